@@ -3,6 +3,7 @@ package api
 import (
 	"application/service"
 	"application/utils"
+	"encoding/json"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
@@ -18,7 +19,7 @@ func NewBankHandler() *BankHandler {
 	}
 }
 
-// CompleteTransaction 完成交易（仅银行组织可以调用）
+// CompleteTransaction 完成交易（仅银行组织可以调用，交易必须已付清）
 func (h *BankHandler) CompleteTransaction(c *gin.Context) {
 	txID := c.Param("txId")
 	err := h.bankService.CompleteTransaction(txID)
@@ -30,6 +31,93 @@ func (h *BankHandler) CompleteTransaction(c *gin.Context) {
 	utils.SuccessWithMessage(c, "交易完成", nil)
 }
 
+// RecordPayment 登记买家向托管账户的付款（仅银行组织可以调用，付清后自动完成交易）
+func (h *BankHandler) RecordPayment(c *gin.Context) {
+	txID := c.Param("txId")
+	var req struct {
+		Amount    json.Number `json:"amount"`    // 付款金额（十进制数字或字符串，币种与成交价格一致）
+		Reference string      `json:"reference"` // 银行流水号
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(c, "付款信息格式错误")
+		return
+	}
+
+	paid, err := h.bankService.RecordPayment(txID, req.Amount.String(), req.Reference)
+	if err != nil {
+		utils.ServerError(c, "登记付款失败："+err.Error())
+		return
+	}
+	if !paid {
+		utils.SuccessWithMessage(c, "付款登记成功", nil)
+		return
+	}
+
+	// 付清后接着完成交易，失败时交易保持已付清状态，可以稍后重试
+	err = h.bankService.CompleteTransaction(txID)
+	if err != nil {
+		utils.SuccessWithMessage(c, "付款登记成功，交易已付清，但完成交易失败："+err.Error(), nil)
+		return
+	}
+
+	utils.SuccessWithMessage(c, "付款登记成功，交易已完成", nil)
+}
+
+// QueryTransactionPayments 查询交易的托管付款记录和未付余额
+func (h *BankHandler) QueryTransactionPayments(c *gin.Context) {
+	summary, err := h.bankService.QueryTransactionPayments(c.Param("txId"))
+	if err != nil {
+		utils.ServerError(c, err.Error())
+		return
+	}
+
+	utils.Success(c, summary)
+}
+
+// QueryTransactionFees 查询交易完成时计算的税费明细
+func (h *BankHandler) QueryTransactionFees(c *gin.Context) {
+	fees, err := h.bankService.QueryTransactionFees(c.Param("txId"))
+	if err != nil {
+		utils.ServerError(c, err.Error())
+		return
+	}
+
+	utils.Success(c, fees)
+}
+
+// QueryTransactionSigningPayload 查询交易待签署的条款及买卖双方的签署状态
+func (h *BankHandler) QueryTransactionSigningPayload(c *gin.Context) {
+	payload, err := h.bankService.QueryTransactionSigningPayload(c.Param("txId"))
+	if err != nil {
+		utils.ServerError(c, err.Error())
+		return
+	}
+
+	utils.Success(c, payload)
+}
+
+// CancelTransaction 取消交易（交易平台组织和银行组织可以调用）
+func (h *BankHandler) CancelTransaction(c *gin.Context) {
+	txID := c.Param("txId")
+	var req struct {
+		Reason string `json:"reason"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(c, "取消信息格式错误")
+		return
+	}
+
+	err := h.bankService.CancelTransaction(txID, req.Reason)
+	if err != nil {
+		utils.ServerError(c, "取消交易失败："+err.Error())
+		return
+	}
+
+	utils.SuccessWithMessage(c, "交易已取消", nil)
+}
+
 // QueryTransaction 查询交易信息
 func (h *BankHandler) QueryTransaction(c *gin.Context) {
 	txID := c.Param("txId")
@@ -42,6 +130,18 @@ func (h *BankHandler) QueryTransaction(c *gin.Context) {
 	utils.Success(c, transaction)
 }
 
+// QueryTransactionPrivateDetails 查询交易私有数据（买家、价格和盐值）
+func (h *BankHandler) QueryTransactionPrivateDetails(c *gin.Context) {
+	txID := c.Param("txId")
+	details, err := h.bankService.QueryTransactionPrivateDetails(txID)
+	if err != nil {
+		utils.ServerError(c, err.Error())
+		return
+	}
+
+	utils.Success(c, details)
+}
+
 // QueryTransactionList 分页查询交易列表
 func (h *BankHandler) QueryTransactionList(c *gin.Context) {
 	pageSize, _ := strconv.Atoi(c.DefaultQuery("pageSize", "10"))
@@ -57,6 +157,141 @@ func (h *BankHandler) QueryTransactionList(c *gin.Context) {
 	utils.Success(c, result)
 }
 
+// RegisterMortgage 登记抵押（仅银行组织可以调用）
+func (h *BankHandler) RegisterMortgage(c *gin.Context) {
+	var req struct {
+		ID           string      `json:"id"`
+		RealEstateID string      `json:"realEstateId"`
+		Amount       json.Number `json:"amount"`   // 担保金额（十进制数字或字符串）
+		Currency     string      `json:"currency"` // 币种（ISO 4217 代码），不填时为人民币
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(c, "抵押信息格式错误")
+		return
+	}
+
+	if req.Currency == "" {
+		req.Currency = service.DEFAULT_CURRENCY
+	}
+
+	err := h.bankService.RegisterMortgage(req.ID, req.RealEstateID, req.Amount.String(), req.Currency)
+	if err != nil {
+		utils.ServerError(c, "登记抵押失败："+err.Error())
+		return
+	}
+
+	utils.SuccessWithMessage(c, "抵押登记成功", nil)
+}
+
+// ConsentMortgageTransfer 同意转让抵押房产（仅抵押权人可以调用）
+func (h *BankHandler) ConsentMortgageTransfer(c *gin.Context) {
+	var req struct {
+		RealEstateID string `json:"realEstateId"`
+		MortgageID   string `json:"mortgageId"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(c, "抵押信息格式错误")
+		return
+	}
+
+	err := h.bankService.ConsentMortgageTransfer(req.RealEstateID, req.MortgageID)
+	if err != nil {
+		utils.ServerError(c, "同意转让失败："+err.Error())
+		return
+	}
+
+	utils.SuccessWithMessage(c, "已同意转让", nil)
+}
+
+// ReleaseMortgage 注销抵押（仅抵押权人可以调用）
+func (h *BankHandler) ReleaseMortgage(c *gin.Context) {
+	var req struct {
+		RealEstateID string `json:"realEstateId"`
+		MortgageID   string `json:"mortgageId"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(c, "抵押信息格式错误")
+		return
+	}
+
+	err := h.bankService.ReleaseMortgage(req.RealEstateID, req.MortgageID)
+	if err != nil {
+		utils.ServerError(c, "注销抵押失败："+err.Error())
+		return
+	}
+
+	utils.SuccessWithMessage(c, "抵押注销成功", nil)
+}
+
+// QueryMortgageList 查询房产的抵押列表
+func (h *BankHandler) QueryMortgageList(c *gin.Context) {
+	realEstateID := c.Query("realEstateId")
+	mortgages, err := h.bankService.QueryMortgageList(realEstateID)
+	if err != nil {
+		utils.ServerError(c, err.Error())
+		return
+	}
+
+	utils.Success(c, mortgages)
+}
+
+// QueryRealEstateHistory 查询房产历史记录
+func (h *BankHandler) QueryRealEstateHistory(c *gin.Context) {
+	id := c.Param("id")
+	history, err := h.bankService.QueryRealEstateHistory(id)
+	if err != nil {
+		utils.ServerError(c, "查询房产历史记录失败："+err.Error())
+		return
+	}
+
+	utils.Success(c, history)
+}
+
+// QueryTransactionHistory 查询交易历史记录
+func (h *BankHandler) QueryTransactionHistory(c *gin.Context) {
+	txID := c.Param("txId")
+	history, err := h.bankService.QueryTransactionHistory(txID)
+	if err != nil {
+		utils.ServerError(c, "查询交易历史记录失败："+err.Error())
+		return
+	}
+
+	utils.Success(c, history)
+}
+
+// QueryRealEstateByOwner 分页查询所有者持有的房产列表
+func (h *BankHandler) QueryRealEstateByOwner(c *gin.Context) {
+	owner := c.Param("owner")
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("pageSize", "10"))
+	bookmark := c.DefaultQuery("bookmark", "")
+
+	result, err := h.bankService.QueryRealEstateByOwner(owner, int32(pageSize), bookmark)
+	if err != nil {
+		utils.ServerError(c, err.Error())
+		return
+	}
+
+	utils.Success(c, result)
+}
+
+// QueryTransactionsByParty 分页查询交易方参与的交易列表
+func (h *BankHandler) QueryTransactionsByParty(c *gin.Context) {
+	party := c.Param("party")
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("pageSize", "10"))
+	bookmark := c.DefaultQuery("bookmark", "")
+
+	result, err := h.bankService.QueryTransactionsByParty(party, int32(pageSize), bookmark)
+	if err != nil {
+		utils.ServerError(c, err.Error())
+		return
+	}
+
+	utils.Success(c, result)
+}
+
 // QueryBlockList 分页查询区块列表
 func (h *BankHandler) QueryBlockList(c *gin.Context) {
 	pageSize, _ := strconv.Atoi(c.DefaultQuery("pageSize", "10"))
@@ -70,3 +305,69 @@ func (h *BankHandler) QueryBlockList(c *gin.Context) {
 
 	utils.Success(c, result)
 }
+
+// QueryEventList 分页查询链码事件列表
+func (h *BankHandler) QueryEventList(c *gin.Context) {
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("pageSize", "10"))
+	pageNum, _ := strconv.Atoi(c.DefaultQuery("pageNum", "1"))
+
+	filter, err := parseEventFilter(c)
+	if err != nil {
+		utils.BadRequest(c, err.Error())
+		return
+	}
+
+	result, err := h.bankService.QueryEventList(filter, pageSize, pageNum)
+	if err != nil {
+		utils.ServerError(c, err.Error())
+		return
+	}
+
+	utils.Success(c, result)
+}
+
+// UploadDocument 上传文档，保存到本地文档存储并将文件哈希存证到账本上
+func (h *BankHandler) UploadDocument(c *gin.Context) {
+	req, err := parseDocumentUpload(c)
+	if err != nil {
+		utils.BadRequest(c, err.Error())
+		return
+	}
+
+	file, err := req.File.Open()
+	if err != nil {
+		utils.BadRequest(c, "读取上传文件失败")
+		return
+	}
+	defer file.Close()
+
+	hash, err := h.bankService.UploadDocument(req.EntityType, req.EntityID, req.DocType, file)
+	if err != nil {
+		utils.ServerError(c, err.Error())
+		return
+	}
+
+	utils.SuccessWithMessage(c, "文档存证成功", gin.H{"sha256": hash})
+}
+
+// DownloadDocument 下载文档（文件哈希必须已存证到对象上，且本地文件内容与哈希一致）
+func (h *BankHandler) DownloadDocument(c *gin.Context) {
+	file, size, err := h.bankService.OpenDocument(c.Param("entityType"), c.Param("entityId"), c.Param("sha256"))
+	if err != nil {
+		utils.ServerError(c, err.Error())
+		return
+	}
+
+	serveDocument(c, file, size, c.Param("sha256"))
+}
+
+// QueryDocuments 查询对象的文档存证记录
+func (h *BankHandler) QueryDocuments(c *gin.Context) {
+	documents, err := h.bankService.QueryDocuments(c.Param("entityType"), c.Param("entityId"))
+	if err != nil {
+		utils.ServerError(c, err.Error())
+		return
+	}
+
+	utils.Success(c, documents)
+}
@@ -0,0 +1,43 @@
+package api
+
+import (
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"os"
+
+	"github.com/gin-gonic/gin"
+)
+
+// documentUpload 上传文档的表单（multipart/form-data）
+type documentUpload struct {
+	EntityType string                `form:"entityType"` // 关联对象类型：REAL_ESTATE-房产、TRANSACTION-交易、PARTY-交易方
+	EntityID   string                `form:"entityId"`   // 关联对象ID
+	DocType    string                `form:"docType"`    // 文档类型：TITLE_DEED-产权证书、CONTRACT-合同、ID_SCAN-证件扫描件、OTHER-其他
+	File       *multipart.FileHeader `form:"file"`       // 文件
+}
+
+// parseDocumentUpload 解析上传文档的表单
+func parseDocumentUpload(c *gin.Context) (*documentUpload, error) {
+	var req documentUpload
+	if err := c.ShouldBind(&req); err != nil {
+		return nil, fmt.Errorf("文档信息格式错误")
+	}
+	if req.EntityType == "" || req.EntityID == "" || req.DocType == "" {
+		return nil, fmt.Errorf("关联对象类型、关联对象ID和文档类型不能为空")
+	}
+	if req.File == nil {
+		return nil, fmt.Errorf("请选择要上传的文件")
+	}
+	return &req, nil
+}
+
+// serveDocument 以附件形式返回文件（文件名为文件哈希）
+func serveDocument(c *gin.Context, file *os.File, size int64, hash string) {
+	defer file.Close()
+
+	c.DataFromReader(http.StatusOK, size, "application/octet-stream", file, map[string]string{
+		"Content-Disposition": fmt.Sprintf(`attachment; filename="%s"`, hash),
+		"ETag":                fmt.Sprintf(`"%s"`, hash),
+	})
+}
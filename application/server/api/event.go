@@ -0,0 +1,35 @@
+package api
+
+import (
+	"application/pkg/fabric"
+	"fmt"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// parseEventFilter 解析链码事件查询条件
+func parseEventFilter(c *gin.Context) (fabric.EventFilter, error) {
+	filter := fabric.EventFilter{
+		EventName: c.DefaultQuery("eventName", ""),
+		EntityID:  c.DefaultQuery("entityId", ""),
+	}
+
+	if startTime := c.DefaultQuery("startTime", ""); startTime != "" {
+		t, err := time.Parse(time.RFC3339, startTime)
+		if err != nil {
+			return filter, fmt.Errorf("开始时间格式错误，应为RFC3339格式")
+		}
+		filter.StartTime = t
+	}
+
+	if endTime := c.DefaultQuery("endTime", ""); endTime != "" {
+		t, err := time.Parse(time.RFC3339, endTime)
+		if err != nil {
+			return filter, fmt.Errorf("结束时间格式错误，应为RFC3339格式")
+		}
+		filter.EndTime = t
+	}
+
+	return filter, nil
+}
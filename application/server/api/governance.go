@@ -0,0 +1,117 @@
+package api
+
+import (
+	"application/service"
+	"application/utils"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type GovernanceHandler struct {
+	governanceService *service.GovernanceService
+}
+
+func NewGovernanceHandler() *GovernanceHandler {
+	return &GovernanceHandler{
+		governanceService: &service.GovernanceService{},
+	}
+}
+
+// QueryOrganizations 查询配置文件中的所有组织
+func (h *GovernanceHandler) QueryOrganizations(c *gin.Context) {
+	utils.Success(c, h.governanceService.QueryOrganizations())
+}
+
+// ProposeOrgCapabilities 提议变更组织权限（仅参与治理的组织可以调用）
+func (h *GovernanceHandler) ProposeOrgCapabilities(c *gin.Context) {
+	var req struct {
+		ProposalID   string   `json:"proposalId"`
+		MSPID        string   `json:"mspId"`        // 目标组织 MSP ID（可以是新组织）
+		Capabilities []string `json:"capabilities"` // 变更后的权限：REGISTRY、TRADING、SETTLEMENT、JUDICIAL、TAXATION，为空表示移除该组织
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(c, "提案信息格式错误")
+		return
+	}
+	if req.Capabilities == nil {
+		req.Capabilities = []string{}
+	}
+
+	err := h.governanceService.ProposeOrgCapabilities(c.Param("org"), req.ProposalID, req.MSPID, req.Capabilities)
+	if err != nil {
+		utils.ServerError(c, err.Error())
+		return
+	}
+
+	utils.SuccessWithMessage(c, "治理提案已提交", nil)
+}
+
+// ProposeRoleEnforcement 提议启用或关闭角色检查（仅参与治理的组织可以调用）
+func (h *GovernanceHandler) ProposeRoleEnforcement(c *gin.Context) {
+	var req struct {
+		ProposalID string `json:"proposalId"`
+		Enabled    *bool  `json:"enabled"` // 是否启用角色检查
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil || req.Enabled == nil {
+		utils.BadRequest(c, "提案信息格式错误")
+		return
+	}
+
+	err := h.governanceService.ProposeRoleEnforcement(c.Param("org"), req.ProposalID, *req.Enabled)
+	if err != nil {
+		utils.ServerError(c, err.Error())
+		return
+	}
+
+	utils.SuccessWithMessage(c, "治理提案已提交", nil)
+}
+
+// ApproveGovernanceProposal 批准治理提案（仅参与治理的组织可以调用）
+func (h *GovernanceHandler) ApproveGovernanceProposal(c *gin.Context) {
+	err := h.governanceService.ApproveGovernanceProposal(c.Param("org"), c.Param("id"))
+	if err != nil {
+		utils.ServerError(c, err.Error())
+		return
+	}
+
+	utils.SuccessWithMessage(c, "治理提案已批准", nil)
+}
+
+// QueryGovernanceProposal 查询治理提案
+func (h *GovernanceHandler) QueryGovernanceProposal(c *gin.Context) {
+	proposal, err := h.governanceService.QueryGovernanceProposal(c.Param("org"), c.Param("id"))
+	if err != nil {
+		utils.ServerError(c, err.Error())
+		return
+	}
+
+	utils.Success(c, proposal)
+}
+
+// QueryGovernanceProposalList 分页查询治理提案列表
+func (h *GovernanceHandler) QueryGovernanceProposalList(c *gin.Context) {
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("pageSize", "10"))
+	bookmark := c.DefaultQuery("bookmark", "")
+
+	result, err := h.governanceService.QueryGovernanceProposalList(c.Param("org"), int32(pageSize), bookmark)
+	if err != nil {
+		utils.ServerError(c, err.Error())
+		return
+	}
+
+	utils.Success(c, result)
+}
+
+// QueryOrgCapabilitiesList 查询所有参与治理的组织及其权限
+func (h *GovernanceHandler) QueryOrgCapabilitiesList(c *gin.Context) {
+	records, err := h.governanceService.QueryOrgCapabilitiesList(c.Param("org"))
+	if err != nil {
+		utils.ServerError(c, err.Error())
+		return
+	}
+
+	utils.Success(c, records)
+}
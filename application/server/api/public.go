@@ -0,0 +1,43 @@
+package api
+
+import (
+	"application/service"
+	"application/utils"
+	"encoding/hex"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PublicHandler struct {
+	publicService *service.PublicService
+}
+
+func NewPublicHandler() *PublicHandler {
+	return &PublicHandler{
+		publicService: &service.PublicService{},
+	}
+}
+
+// Verify 核验房产所有权证书或存证文档的哈希（公开接口，不需要 Fabric 身份）
+func (h *PublicHandler) Verify(c *gin.Context) {
+	realEstateID := c.Query("realEstateId")
+	hash := strings.ToLower(c.Query("hash"))
+
+	if realEstateID == "" {
+		utils.BadRequest(c, "房产ID不能为空")
+		return
+	}
+	if decoded, err := hex.DecodeString(hash); err != nil || len(decoded) != 32 {
+		utils.BadRequest(c, "哈希必须为64位十六进制的 SHA-256 值")
+		return
+	}
+
+	result, err := h.publicService.Verify(realEstateID, hash)
+	if err != nil {
+		utils.ServerError(c, err.Error())
+		return
+	}
+
+	utils.Success(c, result)
+}
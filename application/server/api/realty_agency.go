@@ -3,6 +3,9 @@ package api
 import (
 	"application/service"
 	"application/utils"
+	"encoding/json"
+	"fmt"
+	"net/http"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
@@ -21,10 +24,12 @@ func NewRealtyAgencyHandler() *RealtyAgencyHandler {
 // CreateRealEstate 创建房产信息（仅不动产登记机构组织可以调用）
 func (h *RealtyAgencyHandler) CreateRealEstate(c *gin.Context) {
 	var req struct {
-		ID      string  `json:"id"`
-		Address string  `json:"address"`
-		Area    float64 `json:"area"`
-		Owner   string  `json:"owner"`
+		ID           string          `json:"id"`
+		Address      string          `json:"address"`
+		Area         json.Number     `json:"area"`         // 面积（十进制数字或字符串，最多两位小数）
+		PropertyType string          `json:"propertyType"` // 房产类型（RESIDENTIAL、COMMERCIAL、OFFICE、INDUSTRIAL，默认为住宅）
+		Owner        string          `json:"owner"`        // 单一所有者（兼容旧接口，持有全部份额）
+		Owners       []service.Owner `json:"owners"`       // 共有人及其份额
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -32,7 +37,17 @@ func (h *RealtyAgencyHandler) CreateRealEstate(c *gin.Context) {
 		return
 	}
 
-	err := h.realtyService.CreateRealEstate(req.ID, req.Address, req.Area, req.Owner)
+	owners := req.Owners
+	if len(owners) == 0 && req.Owner != "" {
+		owners = []service.Owner{{ID: req.Owner, Share: service.FULL_SHARE}}
+	}
+
+	propertyType := req.PropertyType
+	if propertyType == "" {
+		propertyType = service.DEFAULT_PROPERTY_TYPE
+	}
+
+	err := h.realtyService.CreateRealEstate(req.ID, req.Address, req.Area.String(), propertyType, owners)
 	if err != nil {
 		utils.ServerError(c, "创建房产信息失败："+err.Error())
 		return
@@ -53,6 +68,64 @@ func (h *RealtyAgencyHandler) QueryRealEstate(c *gin.Context) {
 	utils.Success(c, realEstate)
 }
 
+// UpdateRealEstate 更正房产信息（仅不动产登记机构组织可以调用，交易中的房产不能更正）
+func (h *RealtyAgencyHandler) UpdateRealEstate(c *gin.Context) {
+	id := c.Param("id")
+	var req struct {
+		Address      string      `json:"address"`      // 更正后的地址（为空表示不变）
+		Area         json.Number `json:"area"`         // 更正后的面积（为空表示不变）
+		PropertyType string      `json:"propertyType"` // 更正后的房产类型（为空表示不变）
+		Reason       string      `json:"reason"`       // 更正原因
+		DocumentRef  string      `json:"documentRef"`  // 证明文件的 SHA-256 哈希（需先通过文档上传接口存证到该房产上）
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(c, "更正信息格式错误")
+		return
+	}
+
+	err := h.realtyService.UpdateRealEstate(id, req.Address, req.Area.String(), req.PropertyType, req.Reason, req.DocumentRef)
+	if err != nil {
+		utils.ServerError(c, err.Error())
+		return
+	}
+
+	utils.SuccessWithMessage(c, "房产信息更正成功", nil)
+}
+
+// QueryRealEstateAmendments 查询房产信息的更正记录
+func (h *RealtyAgencyHandler) QueryRealEstateAmendments(c *gin.Context) {
+	amendments, err := h.realtyService.QueryRealEstateAmendments(c.Param("id"))
+	if err != nil {
+		utils.ServerError(c, err.Error())
+		return
+	}
+
+	utils.Success(c, amendments)
+}
+
+// RetireRealEstate 注销已拆除或被征收的房产（仅不动产登记机构组织可以调用）
+func (h *RealtyAgencyHandler) RetireRealEstate(c *gin.Context) {
+	id := c.Param("id")
+	var req struct {
+		Reason        string `json:"reason"`        // 注销原因（如拆除、征收）
+		EffectiveDate string `json:"effectiveDate"` // 注销生效日期（YYYY-MM-DD格式）
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(c, "注销信息格式错误")
+		return
+	}
+
+	err := h.realtyService.RetireRealEstate(id, req.Reason, req.EffectiveDate)
+	if err != nil {
+		utils.ServerError(c, err.Error())
+		return
+	}
+
+	utils.SuccessWithMessage(c, "房产已注销", nil)
+}
+
 // QueryRealEstateList 分页查询房产列表
 func (h *RealtyAgencyHandler) QueryRealEstateList(c *gin.Context) {
 	pageSize, _ := strconv.Atoi(c.DefaultQuery("pageSize", "10"))
@@ -68,6 +141,269 @@ func (h *RealtyAgencyHandler) QueryRealEstateList(c *gin.Context) {
 	utils.Success(c, result)
 }
 
+// QueryRealEstateHistory 查询房产历史记录
+func (h *RealtyAgencyHandler) QueryRealEstateHistory(c *gin.Context) {
+	id := c.Param("id")
+	history, err := h.realtyService.QueryRealEstateHistory(id)
+	if err != nil {
+		utils.ServerError(c, "查询房产历史记录失败："+err.Error())
+		return
+	}
+
+	utils.Success(c, history)
+}
+
+// QueryRealEstateByOwner 分页查询所有者持有的房产列表
+func (h *RealtyAgencyHandler) QueryRealEstateByOwner(c *gin.Context) {
+	owner := c.Param("owner")
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("pageSize", "10"))
+	bookmark := c.DefaultQuery("bookmark", "")
+
+	result, err := h.realtyService.QueryRealEstateByOwner(owner, int32(pageSize), bookmark)
+	if err != nil {
+		utils.ServerError(c, err.Error())
+		return
+	}
+
+	utils.Success(c, result)
+}
+
+// GenerateCertificate 下载房产所有权证书 PDF
+func (h *RealtyAgencyHandler) GenerateCertificate(c *gin.Context) {
+	id := c.Param("id")
+	pdf, err := h.realtyService.GenerateCertificate(id)
+	if err != nil {
+		utils.ServerError(c, err.Error())
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="certificate-%s.pdf"`, id))
+	c.Data(http.StatusOK, "application/pdf", pdf)
+}
+
+// VerifyTransactionPrivateData 校验披露的交易私有数据是否与链上哈希一致
+func (h *RealtyAgencyHandler) VerifyTransactionPrivateData(c *gin.Context) {
+	txID := c.Param("txId")
+	var req struct {
+		Buyer    string      `json:"buyer"`
+		Price    json.Number `json:"price"`
+		Currency string      `json:"currency"`
+		Salt     string      `json:"salt"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(c, "披露数据格式错误")
+		return
+	}
+
+	if req.Currency == "" {
+		req.Currency = service.DEFAULT_CURRENCY
+	}
+
+	matched, err := h.realtyService.VerifyTransactionPrivateData(txID, req.Buyer, req.Price.String(), req.Currency, req.Salt)
+	if err != nil {
+		utils.ServerError(c, err.Error())
+		return
+	}
+
+	utils.Success(c, gin.H{"matched": matched})
+}
+
+// RegisterParty 登记交易方（仅不动产登记机构组织可以调用）
+func (h *RealtyAgencyHandler) RegisterParty(c *gin.Context) {
+	var req struct {
+		ID               string `json:"id"`
+		Type             string `json:"type"`             // 交易方类型：PERSON-自然人、LEGAL_ENTITY-法人
+		IDDocumentNumber string `json:"idDocumentNumber"` // 证件号码（仅用于计算哈希，不上链）
+		DisplayName      string `json:"displayName"`
+		ContactRef       string `json:"contactRef"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(c, "交易方信息格式错误")
+		return
+	}
+
+	err := h.realtyService.RegisterParty(req.ID, req.Type, req.IDDocumentNumber, req.DisplayName, req.ContactRef)
+	if err != nil {
+		utils.ServerError(c, err.Error())
+		return
+	}
+
+	utils.SuccessWithMessage(c, "交易方登记成功", nil)
+}
+
+// UpdateParty 更新交易方信息（仅不动产登记机构组织可以调用）
+func (h *RealtyAgencyHandler) UpdateParty(c *gin.Context) {
+	id := c.Param("id")
+	var req struct {
+		DisplayName string `json:"displayName"`
+		ContactRef  string `json:"contactRef"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(c, "交易方信息格式错误")
+		return
+	}
+
+	err := h.realtyService.UpdateParty(id, req.DisplayName, req.ContactRef)
+	if err != nil {
+		utils.ServerError(c, err.Error())
+		return
+	}
+
+	utils.SuccessWithMessage(c, "交易方更新成功", nil)
+}
+
+// RegisterPartyPublicKey 登记或更换交易方签署交易使用的公钥（仅不动产登记机构组织可以调用）
+func (h *RealtyAgencyHandler) RegisterPartyPublicKey(c *gin.Context) {
+	id := c.Param("id")
+	var req struct {
+		PublicKey string `json:"publicKey"` // PEM 格式的 ECDSA 公钥
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(c, "公钥信息格式错误")
+		return
+	}
+
+	err := h.realtyService.RegisterPartyPublicKey(id, req.PublicKey)
+	if err != nil {
+		utils.ServerError(c, err.Error())
+		return
+	}
+
+	utils.SuccessWithMessage(c, "交易方公钥登记成功", nil)
+}
+
+// SetFeeSchedule 更新交易税费标准（仅拥有税务或登记权限的组织可以调用，整体替换现有规则）
+func (h *RealtyAgencyHandler) SetFeeSchedule(c *gin.Context) {
+	var req struct {
+		Rules []map[string]interface{} `json:"rules"` // 税费规则（字段见链码 FeeRule）
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(c, "税费标准格式错误")
+		return
+	}
+
+	err := h.realtyService.SetFeeSchedule(req.Rules)
+	if err != nil {
+		utils.ServerError(c, err.Error())
+		return
+	}
+
+	utils.SuccessWithMessage(c, "税费标准更新成功", nil)
+}
+
+// QueryFeeSchedule 查询交易税费标准
+func (h *RealtyAgencyHandler) QueryFeeSchedule(c *gin.Context) {
+	schedule, err := h.realtyService.QueryFeeSchedule()
+	if err != nil {
+		utils.ServerError(c, err.Error())
+		return
+	}
+
+	utils.Success(c, schedule)
+}
+
+// DeleteParty 删除交易方（仅不动产登记机构组织可以调用）
+func (h *RealtyAgencyHandler) DeleteParty(c *gin.Context) {
+	id := c.Param("id")
+	err := h.realtyService.DeleteParty(id)
+	if err != nil {
+		utils.ServerError(c, err.Error())
+		return
+	}
+
+	utils.SuccessWithMessage(c, "交易方删除成功", nil)
+}
+
+// QueryParty 查询交易方信息
+func (h *RealtyAgencyHandler) QueryParty(c *gin.Context) {
+	id := c.Param("id")
+	party, err := h.realtyService.QueryParty(id)
+	if err != nil {
+		utils.ServerError(c, err.Error())
+		return
+	}
+
+	utils.Success(c, party)
+}
+
+// QueryPartyList 分页查询交易方列表
+func (h *RealtyAgencyHandler) QueryPartyList(c *gin.Context) {
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("pageSize", "10"))
+	bookmark := c.DefaultQuery("bookmark", "")
+
+	result, err := h.realtyService.QueryPartyList(int32(pageSize), bookmark)
+	if err != nil {
+		utils.ServerError(c, err.Error())
+		return
+	}
+
+	utils.Success(c, result)
+}
+
+// FreezeRealEstate 司法冻结房产（仅不动产登记机构组织或获得司法权限的组织可以调用）
+func (h *RealtyAgencyHandler) FreezeRealEstate(c *gin.Context) {
+	id := c.Param("id")
+	var req struct {
+		CaseNumber string `json:"caseNumber"` // 案号
+		Authority  string `json:"authority"`  // 出具裁定的机关
+		ExpireTime string `json:"expireTime"` // 冻结期限（RFC3339格式）
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(c, "冻结信息格式错误")
+		return
+	}
+
+	err := h.realtyService.FreezeRealEstate(id, req.CaseNumber, req.Authority, req.ExpireTime)
+	if err != nil {
+		utils.ServerError(c, err.Error())
+		return
+	}
+
+	utils.SuccessWithMessage(c, "房产已冻结", nil)
+}
+
+// UnfreezeRealEstate 解除房产的司法冻结（仅不动产登记机构组织或获得司法权限的组织可以调用）
+func (h *RealtyAgencyHandler) UnfreezeRealEstate(c *gin.Context) {
+	id := c.Param("id")
+	var req struct {
+		Reason string `json:"reason"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(c, "解除冻结信息格式错误")
+		return
+	}
+
+	err := h.realtyService.UnfreezeRealEstate(id, req.Reason)
+	if err != nil {
+		utils.ServerError(c, err.Error())
+		return
+	}
+
+	utils.SuccessWithMessage(c, "房产已解除冻结", nil)
+}
+
+// QueryFreezeList 分页查询司法冻结记录列表
+func (h *RealtyAgencyHandler) QueryFreezeList(c *gin.Context) {
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("pageSize", "10"))
+	bookmark := c.DefaultQuery("bookmark", "")
+	status := c.DefaultQuery("status", "")
+
+	result, err := h.realtyService.QueryFreezeList(int32(pageSize), bookmark, status)
+	if err != nil {
+		utils.ServerError(c, err.Error())
+		return
+	}
+
+	utils.Success(c, result)
+}
+
 // QueryBlockList 分页查询区块列表
 func (h *RealtyAgencyHandler) QueryBlockList(c *gin.Context) {
 	pageSize, _ := strconv.Atoi(c.DefaultQuery("pageSize", "10"))
@@ -81,3 +417,69 @@ func (h *RealtyAgencyHandler) QueryBlockList(c *gin.Context) {
 
 	utils.Success(c, result)
 }
+
+// QueryEventList 分页查询链码事件列表
+func (h *RealtyAgencyHandler) QueryEventList(c *gin.Context) {
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("pageSize", "10"))
+	pageNum, _ := strconv.Atoi(c.DefaultQuery("pageNum", "1"))
+
+	filter, err := parseEventFilter(c)
+	if err != nil {
+		utils.BadRequest(c, err.Error())
+		return
+	}
+
+	result, err := h.realtyService.QueryEventList(filter, pageSize, pageNum)
+	if err != nil {
+		utils.ServerError(c, err.Error())
+		return
+	}
+
+	utils.Success(c, result)
+}
+
+// UploadDocument 上传文档，保存到本地文档存储并将文件哈希存证到账本上
+func (h *RealtyAgencyHandler) UploadDocument(c *gin.Context) {
+	req, err := parseDocumentUpload(c)
+	if err != nil {
+		utils.BadRequest(c, err.Error())
+		return
+	}
+
+	file, err := req.File.Open()
+	if err != nil {
+		utils.BadRequest(c, "读取上传文件失败")
+		return
+	}
+	defer file.Close()
+
+	hash, err := h.realtyService.UploadDocument(req.EntityType, req.EntityID, req.DocType, file)
+	if err != nil {
+		utils.ServerError(c, err.Error())
+		return
+	}
+
+	utils.SuccessWithMessage(c, "文档存证成功", gin.H{"sha256": hash})
+}
+
+// DownloadDocument 下载文档（文件哈希必须已存证到对象上，且本地文件内容与哈希一致）
+func (h *RealtyAgencyHandler) DownloadDocument(c *gin.Context) {
+	file, size, err := h.realtyService.OpenDocument(c.Param("entityType"), c.Param("entityId"), c.Param("sha256"))
+	if err != nil {
+		utils.ServerError(c, err.Error())
+		return
+	}
+
+	serveDocument(c, file, size, c.Param("sha256"))
+}
+
+// QueryDocuments 查询对象的文档存证记录
+func (h *RealtyAgencyHandler) QueryDocuments(c *gin.Context) {
+	documents, err := h.realtyService.QueryDocuments(c.Param("entityType"), c.Param("entityId"))
+	if err != nil {
+		utils.ServerError(c, err.Error())
+		return
+	}
+
+	utils.Success(c, documents)
+}
@@ -3,6 +3,7 @@ package api
 import (
 	"application/service"
 	"application/utils"
+	"encoding/json"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
@@ -21,11 +22,13 @@ func NewTradingPlatformHandler() *TradingPlatformHandler {
 // CreateTransaction 生成交易（仅交易平台组织可以调用）
 func (h *TradingPlatformHandler) CreateTransaction(c *gin.Context) {
 	var req struct {
-		TxID         string  `json:"txId"`
-		RealEstateID string  `json:"realEstateId"`
-		Seller       string  `json:"seller"`
-		Buyer        string  `json:"buyer"`
-		Price        float64 `json:"price"`
+		TxID         string      `json:"txId"`
+		RealEstateID string      `json:"realEstateId"`
+		Seller       string      `json:"seller"`
+		Buyer        string      `json:"buyer"`
+		Share        int         `json:"share"`    // 出售份额（万分比），不填时出售卖家持有的全部份额
+		Price        json.Number `json:"price"`    // 价格（十进制数字或字符串，按币种的最小货币单位精度）
+		Currency     string      `json:"currency"` // 币种（ISO 4217 代码），不填时为人民币
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -33,7 +36,11 @@ func (h *TradingPlatformHandler) CreateTransaction(c *gin.Context) {
 		return
 	}
 
-	err := h.tradingService.CreateTransaction(req.TxID, req.RealEstateID, req.Seller, req.Buyer, req.Price)
+	if req.Currency == "" {
+		req.Currency = service.DEFAULT_CURRENCY
+	}
+
+	err := h.tradingService.CreateTransaction(req.TxID, req.RealEstateID, req.Seller, req.Buyer, req.Share, req.Price.String(), req.Currency)
 	if err != nil {
 		utils.ServerError(c, "生成交易失败："+err.Error())
 		return
@@ -42,6 +49,230 @@ func (h *TradingPlatformHandler) CreateTransaction(c *gin.Context) {
 	utils.SuccessWithMessage(c, "交易创建成功", nil)
 }
 
+// OpenTransaction 重试确认交易草稿，房产转为交易中（仅交易平台组织可以调用，用于生成交易或接受报价后登记机构确认失败的交易）
+func (h *TradingPlatformHandler) OpenTransaction(c *gin.Context) {
+	err := h.tradingService.OpenTransaction(c.Param("txId"))
+	if err != nil {
+		utils.ServerError(c, err.Error())
+		return
+	}
+
+	utils.SuccessWithMessage(c, "交易已确认", nil)
+}
+
+// ApproveTransaction 提交交易方对交易条款的签署（仅交易平台组织可以调用）
+func (h *TradingPlatformHandler) ApproveTransaction(c *gin.Context) {
+	txID := c.Param("txId")
+	var req struct {
+		PartyID   string `json:"partyId"`   // 签署的交易方（买家或卖家）
+		Signature string `json:"signature"` // 对交易条款 SHA-256 摘要的 ECDSA 签名（Base64 编码的 ASN.1 DER）
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(c, "签署信息格式错误")
+		return
+	}
+
+	err := h.tradingService.ApproveTransaction(txID, req.PartyID, req.Signature)
+	if err != nil {
+		utils.ServerError(c, err.Error())
+		return
+	}
+
+	utils.SuccessWithMessage(c, "签署成功", nil)
+}
+
+// QueryTransactionSigningPayload 查询交易待签署的条款及买卖双方的签署状态
+func (h *TradingPlatformHandler) QueryTransactionSigningPayload(c *gin.Context) {
+	payload, err := h.tradingService.QueryTransactionSigningPayload(c.Param("txId"))
+	if err != nil {
+		utils.ServerError(c, err.Error())
+		return
+	}
+
+	utils.Success(c, payload)
+}
+
+// CancelTransaction 取消交易（交易平台组织和银行组织可以调用）
+func (h *TradingPlatformHandler) CancelTransaction(c *gin.Context) {
+	txID := c.Param("txId")
+	var req struct {
+		Reason string `json:"reason"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(c, "取消信息格式错误")
+		return
+	}
+
+	err := h.tradingService.CancelTransaction(txID, req.Reason)
+	if err != nil {
+		utils.ServerError(c, "取消交易失败："+err.Error())
+		return
+	}
+
+	utils.SuccessWithMessage(c, "交易已取消", nil)
+}
+
+// SubmitOffer 买家对房产出价（仅交易平台组织可以调用）
+func (h *TradingPlatformHandler) SubmitOffer(c *gin.Context) {
+	var req struct {
+		OfferID      string      `json:"offerId"`
+		RealEstateID string      `json:"realEstateId"`
+		Seller       string      `json:"seller"`
+		Buyer        string      `json:"buyer"`
+		Share        int         `json:"share"`    // 出售份额（万分比），不填时购买卖家持有的全部份额
+		Price        json.Number `json:"price"`    // 报价金额（十进制数字或字符串，按币种的最小货币单位精度）
+		Currency     string      `json:"currency"` // 币种（ISO 4217 代码），不填时为人民币
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(c, "报价信息格式错误")
+		return
+	}
+
+	if req.Currency == "" {
+		req.Currency = service.DEFAULT_CURRENCY
+	}
+
+	err := h.tradingService.SubmitOffer(req.OfferID, req.RealEstateID, req.Seller, req.Buyer, req.Share, req.Price.String(), req.Currency)
+	if err != nil {
+		utils.ServerError(c, err.Error())
+		return
+	}
+
+	utils.SuccessWithMessage(c, "出价成功", nil)
+}
+
+// CounterOffer 对报价还价（仅交易平台组织可以调用）
+func (h *TradingPlatformHandler) CounterOffer(c *gin.Context) {
+	offerID := c.Param("offerId")
+	var req struct {
+		CounterOfferID string      `json:"counterOfferId"` // 新报价ID
+		Responder      string      `json:"responder"`      // 还价方（交易方ID），必须是原报价的对方
+		Signature      string      `json:"signature"`      // 还价方对答复内容的签名（Base64 编码的 ASN.1 DER）
+		Price          json.Number `json:"price"`
+		Currency       string      `json:"currency"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(c, "还价信息格式错误")
+		return
+	}
+
+	if req.Currency == "" {
+		req.Currency = service.DEFAULT_CURRENCY
+	}
+
+	err := h.tradingService.CounterOffer(offerID, req.CounterOfferID, req.Responder, req.Signature, req.Price.String(), req.Currency)
+	if err != nil {
+		utils.ServerError(c, err.Error())
+		return
+	}
+
+	utils.SuccessWithMessage(c, "还价成功", nil)
+}
+
+// AcceptOffer 接受报价并生成交易（仅交易平台组织可以调用）
+func (h *TradingPlatformHandler) AcceptOffer(c *gin.Context) {
+	offerID := c.Param("offerId")
+	var req struct {
+		TxID      string `json:"txId"`      // 生成的交易ID
+		Responder string `json:"responder"` // 接受方（交易方ID），必须是报价的对方
+		Signature string `json:"signature"` // 接受方对答复内容的签名（Base64 编码的 ASN.1 DER）
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(c, "接受报价信息格式错误")
+		return
+	}
+
+	err := h.tradingService.AcceptOffer(offerID, req.TxID, req.Responder, req.Signature)
+	if err != nil {
+		utils.ServerError(c, err.Error())
+		return
+	}
+
+	utils.SuccessWithMessage(c, "报价已接受，交易创建成功", nil)
+}
+
+// RejectOffer 拒绝报价（仅交易平台组织可以调用）
+func (h *TradingPlatformHandler) RejectOffer(c *gin.Context) {
+	offerID := c.Param("offerId")
+	var req struct {
+		Responder string `json:"responder"` // 拒绝方（交易方ID），必须是报价的对方
+		Signature string `json:"signature"` // 拒绝方对答复内容的签名（Base64 编码的 ASN.1 DER）
+		Reason    string `json:"reason"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(c, "拒绝信息格式错误")
+		return
+	}
+
+	err := h.tradingService.RejectOffer(offerID, req.Responder, req.Signature, req.Reason)
+	if err != nil {
+		utils.ServerError(c, err.Error())
+		return
+	}
+
+	utils.SuccessWithMessage(c, "报价已拒绝", nil)
+}
+
+// QueryOfferResponsePayload 查询答复报价时答复方需要签署的内容
+func (h *TradingPlatformHandler) QueryOfferResponsePayload(c *gin.Context) {
+	currency := c.DefaultQuery("currency", "")
+	if c.Query("action") == "COUNTER" && currency == "" {
+		currency = service.DEFAULT_CURRENCY
+	}
+
+	payload, err := h.tradingService.QueryOfferResponsePayload(c.Param("offerId"), c.Query("responder"), c.Query("action"),
+		c.Query("targetId"), c.Query("price"), currency, c.Query("reason"))
+	if err != nil {
+		utils.ServerError(c, err.Error())
+		return
+	}
+
+	utils.Success(c, payload)
+}
+
+// QueryOffer 查询报价信息
+func (h *TradingPlatformHandler) QueryOffer(c *gin.Context) {
+	offer, err := h.tradingService.QueryOffer(c.Param("offerId"))
+	if err != nil {
+		utils.ServerError(c, err.Error())
+		return
+	}
+
+	utils.Success(c, offer)
+}
+
+// QueryOfferPrivateDetails 查询报价私有数据
+func (h *TradingPlatformHandler) QueryOfferPrivateDetails(c *gin.Context) {
+	details, err := h.tradingService.QueryOfferPrivateDetails(c.Param("offerId"))
+	if err != nil {
+		utils.ServerError(c, err.Error())
+		return
+	}
+
+	utils.Success(c, details)
+}
+
+// QueryOffersByRealEstate 分页查询房产的报价列表
+func (h *TradingPlatformHandler) QueryOffersByRealEstate(c *gin.Context) {
+	realEstateID := c.Param("realEstateId")
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("pageSize", "10"))
+	bookmark := c.DefaultQuery("bookmark", "")
+
+	result, err := h.tradingService.QueryOffersByRealEstate(realEstateID, int32(pageSize), bookmark)
+	if err != nil {
+		utils.ServerError(c, err.Error())
+		return
+	}
+
+	utils.Success(c, result)
+}
+
 // QueryRealEstate 查询房产信息
 func (h *TradingPlatformHandler) QueryRealEstate(c *gin.Context) {
 	id := c.Param("id")
@@ -66,6 +297,18 @@ func (h *TradingPlatformHandler) QueryTransaction(c *gin.Context) {
 	utils.Success(c, transaction)
 }
 
+// QueryTransactionPrivateDetails 查询交易私有数据（买家、价格和盐值）
+func (h *TradingPlatformHandler) QueryTransactionPrivateDetails(c *gin.Context) {
+	txID := c.Param("txId")
+	details, err := h.tradingService.QueryTransactionPrivateDetails(txID)
+	if err != nil {
+		utils.ServerError(c, err.Error())
+		return
+	}
+
+	utils.Success(c, details)
+}
+
 // QueryTransactionList 分页查询交易列表
 func (h *TradingPlatformHandler) QueryTransactionList(c *gin.Context) {
 	pageSize, _ := strconv.Atoi(c.DefaultQuery("pageSize", "10"))
@@ -81,6 +324,60 @@ func (h *TradingPlatformHandler) QueryTransactionList(c *gin.Context) {
 	utils.Success(c, result)
 }
 
+// QueryRealEstateHistory 查询房产历史记录
+func (h *TradingPlatformHandler) QueryRealEstateHistory(c *gin.Context) {
+	id := c.Param("id")
+	history, err := h.tradingService.QueryRealEstateHistory(id)
+	if err != nil {
+		utils.ServerError(c, "查询房产历史记录失败："+err.Error())
+		return
+	}
+
+	utils.Success(c, history)
+}
+
+// QueryTransactionHistory 查询交易历史记录
+func (h *TradingPlatformHandler) QueryTransactionHistory(c *gin.Context) {
+	txID := c.Param("txId")
+	history, err := h.tradingService.QueryTransactionHistory(txID)
+	if err != nil {
+		utils.ServerError(c, "查询交易历史记录失败："+err.Error())
+		return
+	}
+
+	utils.Success(c, history)
+}
+
+// QueryRealEstateByOwner 分页查询所有者持有的房产列表
+func (h *TradingPlatformHandler) QueryRealEstateByOwner(c *gin.Context) {
+	owner := c.Param("owner")
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("pageSize", "10"))
+	bookmark := c.DefaultQuery("bookmark", "")
+
+	result, err := h.tradingService.QueryRealEstateByOwner(owner, int32(pageSize), bookmark)
+	if err != nil {
+		utils.ServerError(c, err.Error())
+		return
+	}
+
+	utils.Success(c, result)
+}
+
+// QueryTransactionsByParty 分页查询交易方参与的交易列表
+func (h *TradingPlatformHandler) QueryTransactionsByParty(c *gin.Context) {
+	party := c.Param("party")
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("pageSize", "10"))
+	bookmark := c.DefaultQuery("bookmark", "")
+
+	result, err := h.tradingService.QueryTransactionsByParty(party, int32(pageSize), bookmark)
+	if err != nil {
+		utils.ServerError(c, err.Error())
+		return
+	}
+
+	utils.Success(c, result)
+}
+
 // QueryBlockList 分页查询区块列表
 func (h *TradingPlatformHandler) QueryBlockList(c *gin.Context) {
 	pageSize, _ := strconv.Atoi(c.DefaultQuery("pageSize", "10"))
@@ -94,3 +391,69 @@ func (h *TradingPlatformHandler) QueryBlockList(c *gin.Context) {
 
 	utils.Success(c, result)
 }
+
+// QueryEventList 分页查询链码事件列表
+func (h *TradingPlatformHandler) QueryEventList(c *gin.Context) {
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("pageSize", "10"))
+	pageNum, _ := strconv.Atoi(c.DefaultQuery("pageNum", "1"))
+
+	filter, err := parseEventFilter(c)
+	if err != nil {
+		utils.BadRequest(c, err.Error())
+		return
+	}
+
+	result, err := h.tradingService.QueryEventList(filter, pageSize, pageNum)
+	if err != nil {
+		utils.ServerError(c, err.Error())
+		return
+	}
+
+	utils.Success(c, result)
+}
+
+// UploadDocument 上传文档，保存到本地文档存储并将文件哈希存证到账本上
+func (h *TradingPlatformHandler) UploadDocument(c *gin.Context) {
+	req, err := parseDocumentUpload(c)
+	if err != nil {
+		utils.BadRequest(c, err.Error())
+		return
+	}
+
+	file, err := req.File.Open()
+	if err != nil {
+		utils.BadRequest(c, "读取上传文件失败")
+		return
+	}
+	defer file.Close()
+
+	hash, err := h.tradingService.UploadDocument(req.EntityType, req.EntityID, req.DocType, file)
+	if err != nil {
+		utils.ServerError(c, err.Error())
+		return
+	}
+
+	utils.SuccessWithMessage(c, "文档存证成功", gin.H{"sha256": hash})
+}
+
+// DownloadDocument 下载文档（文件哈希必须已存证到对象上，且本地文件内容与哈希一致）
+func (h *TradingPlatformHandler) DownloadDocument(c *gin.Context) {
+	file, size, err := h.tradingService.OpenDocument(c.Param("entityType"), c.Param("entityId"), c.Param("sha256"))
+	if err != nil {
+		utils.ServerError(c, err.Error())
+		return
+	}
+
+	serveDocument(c, file, size, c.Param("sha256"))
+}
+
+// QueryDocuments 查询对象的文档存证记录
+func (h *TradingPlatformHandler) QueryDocuments(c *gin.Context) {
+	documents, err := h.tradingService.QueryDocuments(c.Param("entityType"), c.Param("entityId"))
+	if err != nil {
+		utils.ServerError(c, err.Error())
+		return
+	}
+
+	utils.Success(c, documents)
+}
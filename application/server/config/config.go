@@ -9,8 +9,10 @@ import (
 
 // Config 配置
 type Config struct {
-	Server ServerConfig `yaml:"server"`
-	Fabric FabricConfig `yaml:"fabric"`
+	Server      ServerConfig      `yaml:"server"`
+	Fabric      FabricConfig      `yaml:"fabric"`
+	Certificate CertificateConfig `yaml:"certificate"`
+	Party       PartyConfig       `yaml:"party"`
 }
 
 // ServerConfig 服务器配置
@@ -18,6 +20,17 @@ type ServerConfig struct {
 	Port int `yaml:"port"`
 }
 
+// CertificateConfig 房产所有权证书配置
+type CertificateConfig struct {
+	FontPath  string `yaml:"fontPath"`  // 证书使用的 TrueType 字体（需包含中文字形）
+	VerifyURL string `yaml:"verifyURL"` // 证书二维码中的核验地址
+}
+
+// PartyConfig 交易方配置
+type PartyConfig struct {
+	IDHashKey string `yaml:"idHashKey"` // 计算证件号码 HMAC-SHA256 的密钥（为空时使用首次启动自动生成并保存在本地的密钥）
+}
+
 // FabricConfig Fabric配置
 type FabricConfig struct {
 	ChannelName   string                        `yaml:"channelName"`
@@ -27,12 +40,15 @@ type FabricConfig struct {
 
 // OrganizationConfig 组织配置
 type OrganizationConfig struct {
+	Name         string `yaml:"name"`
 	MSPID        string `yaml:"mspID"`
 	CertPath     string `yaml:"certPath"`
 	KeyPath      string `yaml:"keyPath"`
 	TLSCertPath  string `yaml:"tlsCertPath"`
 	PeerEndpoint string `yaml:"peerEndpoint"`
 	GatewayPeer  string `yaml:"gatewayPeer"`
+	// GovernanceToken 以该组织身份提交治理提案和批准时需要的令牌（请求头 X-Governance-Token），为空时不能通过接口以该组织身份参与治理
+	GovernanceToken string `yaml:"governanceToken"`
 }
 
 var GlobalConfig Config
@@ -3,9 +3,14 @@ package main
 import (
 	"application/api"
 	"application/config"
+	"application/pkg/docstore"
 	"application/pkg/fabric"
+	"application/service"
+	"application/utils"
 	"fmt"
 	"log"
+	"path/filepath"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
@@ -21,6 +26,19 @@ func main() {
 		log.Fatalf("初始化Fabric客户端失败：%v", err)
 	}
 
+	// 初始化本地文档存储
+	if err := docstore.InitDocStore(filepath.Join("data", "documents")); err != nil {
+		log.Fatalf("初始化文档存储失败：%v", err)
+	}
+
+	// 初始化证件号码哈希密钥
+	if err := service.InitPartyIDHashKey(filepath.Join("data", "party-id-hash.key")); err != nil {
+		log.Fatalf("初始化证件号码哈希密钥失败：%v", err)
+	}
+
+	// 启动过期交易清理任务
+	service.StartExpirySweeper()
+
 	// 创建 Gin 路由
 	gin.SetMode(gin.ReleaseMode)
 	r := gin.Default()
@@ -31,6 +49,8 @@ func main() {
 	realtyAgencyHandler := api.NewRealtyAgencyHandler()
 	tradingPlatformHandler := api.NewTradingPlatformHandler()
 	bankHandler := api.NewBankHandler()
+	governanceHandler := api.NewGovernanceHandler()
+	publicHandler := api.NewPublicHandler()
 
 	// 不动产登记机构的接口
 	realty := apiGroup.Group("/realty-agency")
@@ -40,22 +60,79 @@ func main() {
 		// 查询房产接口
 		realty.GET("/realty/:id", realtyAgencyHandler.QueryRealEstate)
 		realty.GET("/realty/list", realtyAgencyHandler.QueryRealEstateList)
+		realty.GET("/realty/:id/history", realtyAgencyHandler.QueryRealEstateHistory)
+		realty.GET("/realty/owner/:owner", realtyAgencyHandler.QueryRealEstateByOwner)
+		// 更正房产信息接口
+		realty.PUT("/realty/:id", realtyAgencyHandler.UpdateRealEstate)
+		realty.GET("/realty/:id/amendments", realtyAgencyHandler.QueryRealEstateAmendments)
+		// 注销房产接口
+		realty.POST("/realty/:id/retire", realtyAgencyHandler.RetireRealEstate)
+		// 生成房产所有权证书
+		realty.GET("/realty/:id/certificate", realtyAgencyHandler.GenerateCertificate)
+		// 司法冻结接口
+		realty.POST("/realty/:id/freeze", realtyAgencyHandler.FreezeRealEstate)
+		realty.POST("/realty/:id/unfreeze", realtyAgencyHandler.UnfreezeRealEstate)
+		realty.GET("/freeze/list", realtyAgencyHandler.QueryFreezeList)
+		// 校验交易私有数据
+		realty.POST("/transaction/:txId/verify", realtyAgencyHandler.VerifyTransactionPrivateData)
+		// 交易方接口
+		realty.POST("/party/create", realtyAgencyHandler.RegisterParty)
+		realty.PUT("/party/:id", realtyAgencyHandler.UpdateParty)
+		realty.POST("/party/:id/public-key", realtyAgencyHandler.RegisterPartyPublicKey)
+		realty.DELETE("/party/:id", realtyAgencyHandler.DeleteParty)
+		realty.GET("/party/:id", realtyAgencyHandler.QueryParty)
+		realty.GET("/party/list", realtyAgencyHandler.QueryPartyList)
+		// 文档存证接口
+		realty.POST("/document/upload", realtyAgencyHandler.UploadDocument)
+		realty.GET("/document/:entityType/:entityId", realtyAgencyHandler.QueryDocuments)
+		realty.GET("/document/:entityType/:entityId/:sha256", realtyAgencyHandler.DownloadDocument)
+		// 交易税费标准接口
+		realty.POST("/fee-schedule", realtyAgencyHandler.SetFeeSchedule)
+		realty.GET("/fee-schedule", realtyAgencyHandler.QueryFeeSchedule)
 		// 查询区块接口
 		realty.GET("/block/list", realtyAgencyHandler.QueryBlockList)
+		// 查询链码事件接口
+		realty.GET("/event/list", realtyAgencyHandler.QueryEventList)
 	}
 
 	// 交易平台的接口
 	trading := apiGroup.Group("/trading-platform")
 	{
-		// 生成交易
+		// 生成交易（登记机构确认交易草稿失败时可以重试确认）
 		trading.POST("/transaction/create", tradingPlatformHandler.CreateTransaction)
+		trading.POST("/transaction/:txId/open", tradingPlatformHandler.OpenTransaction)
+		// 取消交易
+		trading.POST("/transaction/cancel/:txId", tradingPlatformHandler.CancelTransaction)
+		// 买卖双方签署交易
+		trading.GET("/transaction/:txId/signing-payload", tradingPlatformHandler.QueryTransactionSigningPayload)
+		trading.POST("/transaction/:txId/approve", tradingPlatformHandler.ApproveTransaction)
+		// 报价接口
+		trading.POST("/offer/create", tradingPlatformHandler.SubmitOffer)
+		trading.POST("/offer/:offerId/counter", tradingPlatformHandler.CounterOffer)
+		trading.POST("/offer/:offerId/accept", tradingPlatformHandler.AcceptOffer)
+		trading.POST("/offer/:offerId/reject", tradingPlatformHandler.RejectOffer)
+		trading.GET("/offer/:offerId", tradingPlatformHandler.QueryOffer)
+		trading.GET("/offer/:offerId/private", tradingPlatformHandler.QueryOfferPrivateDetails)
+		trading.GET("/offer/:offerId/response-payload", tradingPlatformHandler.QueryOfferResponsePayload)
+		trading.GET("/offer/realty/:realEstateId", tradingPlatformHandler.QueryOffersByRealEstate)
+		// 文档存证接口
+		trading.POST("/document/upload", tradingPlatformHandler.UploadDocument)
+		trading.GET("/document/:entityType/:entityId", tradingPlatformHandler.QueryDocuments)
+		trading.GET("/document/:entityType/:entityId/:sha256", tradingPlatformHandler.DownloadDocument)
 		// 查询房产接口
 		trading.GET("/realty/:id", tradingPlatformHandler.QueryRealEstate)
+		trading.GET("/realty/:id/history", tradingPlatformHandler.QueryRealEstateHistory)
+		trading.GET("/realty/owner/:owner", tradingPlatformHandler.QueryRealEstateByOwner)
 		// 查询交易接口
 		trading.GET("/transaction/:txId", tradingPlatformHandler.QueryTransaction)
 		trading.GET("/transaction/list", tradingPlatformHandler.QueryTransactionList)
+		trading.GET("/transaction/:txId/history", tradingPlatformHandler.QueryTransactionHistory)
+		trading.GET("/transaction/:txId/private", tradingPlatformHandler.QueryTransactionPrivateDetails)
+		trading.GET("/transaction/party/:party", tradingPlatformHandler.QueryTransactionsByParty)
 		// 查询区块接口
 		trading.GET("/block/list", tradingPlatformHandler.QueryBlockList)
+		// 查询链码事件接口
+		trading.GET("/event/list", tradingPlatformHandler.QueryEventList)
 	}
 
 	// 银行的接口
@@ -63,11 +140,64 @@ func main() {
 	{
 		// 完成交易
 		bank.POST("/transaction/complete/:txId", bankHandler.CompleteTransaction)
+		// 托管付款接口
+		bank.POST("/transaction/:txId/payment", bankHandler.RecordPayment)
+		bank.GET("/transaction/:txId/payments", bankHandler.QueryTransactionPayments)
+		// 查询交易税费
+		bank.GET("/transaction/:txId/fees", bankHandler.QueryTransactionFees)
+		// 查询买卖双方的签署状态
+		bank.GET("/transaction/:txId/signing-payload", bankHandler.QueryTransactionSigningPayload)
+		// 取消交易
+		bank.POST("/transaction/cancel/:txId", bankHandler.CancelTransaction)
 		// 查询交易接口
 		bank.GET("/transaction/:txId", bankHandler.QueryTransaction)
 		bank.GET("/transaction/list", bankHandler.QueryTransactionList)
+		bank.GET("/transaction/:txId/history", bankHandler.QueryTransactionHistory)
+		bank.GET("/transaction/:txId/private", bankHandler.QueryTransactionPrivateDetails)
+		bank.GET("/transaction/party/:party", bankHandler.QueryTransactionsByParty)
+		// 文档存证接口
+		bank.POST("/document/upload", bankHandler.UploadDocument)
+		bank.GET("/document/:entityType/:entityId", bankHandler.QueryDocuments)
+		bank.GET("/document/:entityType/:entityId/:sha256", bankHandler.DownloadDocument)
+		// 查询房产接口
+		bank.GET("/realty/:id/history", bankHandler.QueryRealEstateHistory)
+		bank.GET("/realty/owner/:owner", bankHandler.QueryRealEstateByOwner)
+		// 抵押接口
+		bank.POST("/mortgage/register", bankHandler.RegisterMortgage)
+		bank.POST("/mortgage/consent", bankHandler.ConsentMortgageTransfer)
+		bank.POST("/mortgage/release", bankHandler.ReleaseMortgage)
+		bank.GET("/mortgage/list", bankHandler.QueryMortgageList)
 		// 查询区块接口
 		bank.GET("/block/list", bankHandler.QueryBlockList)
+		// 查询链码事件接口
+		bank.GET("/event/list", bankHandler.QueryEventList)
+	}
+
+	// 组织权限治理的接口（:org 为配置文件中的组织名称，新组织加入时只需增加配置）
+	governance := apiGroup.Group("/governance")
+	{
+		// 以组织身份提交治理提案或批准需要该组织的治理令牌，防止一个调用方冒充所有组织
+		orgToken := utils.RequireOrgToken("X-Governance-Token", func(orgName string) string {
+			return config.GlobalConfig.Fabric.Organizations[orgName].GovernanceToken
+		})
+
+		// 查询配置文件中的组织
+		governance.GET("/organizations", governanceHandler.QueryOrganizations)
+		// 查询组织权限
+		governance.GET("/:org/capabilities", governanceHandler.QueryOrgCapabilitiesList)
+		// 治理提案接口
+		governance.POST("/:org/proposal/create", orgToken, governanceHandler.ProposeOrgCapabilities)
+		governance.POST("/:org/proposal/role-enforcement", orgToken, governanceHandler.ProposeRoleEnforcement)
+		governance.POST("/:org/proposal/:id/approve", orgToken, governanceHandler.ApproveGovernanceProposal)
+		governance.GET("/:org/proposal/:id", governanceHandler.QueryGovernanceProposal)
+		governance.GET("/:org/proposal/list", governanceHandler.QueryGovernanceProposalList)
+	}
+
+	// 公开接口（不需要 Fabric 身份，供公证处、法院等第三方核验，按客户端 IP 限流）
+	public := apiGroup.Group("/public", utils.RateLimit(30, time.Minute))
+	{
+		// 核验房产所有权证书或存证文档的哈希
+		public.GET("/verify", publicHandler.Verify)
 	}
 
 	// 启动服务器
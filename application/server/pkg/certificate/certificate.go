@@ -0,0 +1,128 @@
+package certificate
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/go-pdf/fpdf"
+	"github.com/skip2/go-qrcode"
+)
+
+// _FontFamily 证书使用的字体名称
+const _FontFamily = "certificate"
+
+// Owner 房产所有者及其份额
+type Owner struct {
+	ID    string `json:"id"`    // 所有者（交易方ID）
+	Share int    `json:"share"` // 份额（万分比）
+}
+
+// Content 房产所有权证书内容（全部取自账本和本地区块存储，相同的账本状态总是得到相同的内容和哈希）
+type Content struct {
+	RealEstateID string    `json:"realEstateId"` // 房产ID
+	Address      string    `json:"address"`      // 房产地址
+	Area         string    `json:"area"`         // 面积（平方米）
+	PropertyType string    `json:"propertyType"` // 房产类型
+	Owners       []Owner   `json:"owners"`       // 所有者列表
+	Status       string    `json:"status"`       // 房产状态
+	UpdateTime   time.Time `json:"updateTime"`   // 最后更新时间
+	TxID         string    `json:"txId"`         // 最后一次修改房产的 Fabric 交易ID
+	BlockNum     uint64    `json:"blockNum"`     // 该交易所在的区块号
+	BlockHash    string    `json:"blockHash"`    // 该交易所在的区块哈希
+}
+
+// Hash 证书内容的 SHA-256 哈希（十六进制小写，对固定字段顺序的 JSON 计算）
+func (c *Content) Hash() (string, error) {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return "", fmt.Errorf("序列化证书内容失败：%v", err)
+	}
+	hash := sha256.Sum256(data)
+	return hex.EncodeToString(hash[:]), nil
+}
+
+// VerifyLink 证书二维码中的核验链接（核验地址附带房产ID和证书哈希）
+func VerifyLink(verifyURL, realEstateID, hash string) string {
+	query := url.Values{}
+	query.Set("realEstateId", realEstateID)
+	query.Set("hash", hash)
+	return verifyURL + "?" + query.Encode()
+}
+
+// Render 生成证书 PDF（fontPath 为包含中文字形的 TrueType 字体，verifyURL 为核验地址）
+func Render(content *Content, fontPath, verifyURL string) ([]byte, error) {
+	hash, err := content.Hash()
+	if err != nil {
+		return nil, err
+	}
+
+	link := VerifyLink(verifyURL, content.RealEstateID, hash)
+	qr, err := qrcode.Encode(link, qrcode.Medium, 512)
+	if err != nil {
+		return nil, fmt.Errorf("生成二维码失败：%v", err)
+	}
+
+	font, err := os.ReadFile(fontPath)
+	if err != nil {
+		return nil, fmt.Errorf("读取证书字体失败：%v", err)
+	}
+
+	pdf := fpdf.New("P", "mm", "A4", "")
+	pdf.SetTitle("房产所有权证书 "+content.RealEstateID, true)
+	pdf.AddUTF8FontFromBytes(_FontFamily, "", font)
+	if err := pdf.Error(); err != nil {
+		return nil, fmt.Errorf("加载证书字体失败：%v", err)
+	}
+	pdf.AddPage()
+
+	// 标题
+	pdf.SetFont(_FontFamily, "", 22)
+	pdf.CellFormat(0, 20, "房产所有权证书", "", 1, "C", false, 0, "")
+	pdf.Ln(4)
+
+	// 证书内容
+	pdf.SetFont(_FontFamily, "", 11)
+	row := func(label, value string) {
+		pdf.CellFormat(40, 8, label, "", 0, "L", false, 0, "")
+		pdf.MultiCell(0, 8, value, "", "L", false)
+	}
+	row("房产ID", content.RealEstateID)
+	row("房产地址", content.Address)
+	row("面积", content.Area+" 平方米")
+	row("房产类型", content.PropertyType)
+	for i, owner := range content.Owners {
+		label := ""
+		if i == 0 {
+			label = "所有者"
+		}
+		row(label, fmt.Sprintf("%s（份额 %.2f%%）", owner.ID, float64(owner.Share)/100))
+	}
+	row("房产状态", content.Status)
+	row("最后更新时间", content.UpdateTime.Format(time.RFC3339))
+
+	// 账本凭据
+	pdf.Ln(4)
+	pdf.SetFont(_FontFamily, "", 9)
+	row("交易ID", content.TxID)
+	row("区块号", fmt.Sprintf("%d", content.BlockNum))
+	row("区块哈希", content.BlockHash)
+	row("证书哈希", hash)
+
+	// 核验二维码
+	pdf.Ln(6)
+	pdf.RegisterImageOptionsReader("qrcode", fpdf.ImageOptions{ImageType: "PNG"}, bytes.NewReader(qr))
+	pdf.ImageOptions("qrcode", pdf.GetX(), pdf.GetY(), 45, 45, true, fpdf.ImageOptions{ImageType: "PNG"}, 0, "")
+	pdf.MultiCell(0, 6, "扫描二维码核验证书内容是否与账本一致："+link, "", "L", false)
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, fmt.Errorf("生成证书失败：%v", err)
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,178 @@
+package docstore
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// _URIPrefix 本地文档存储的文件位置前缀（存证到账本上的 uri 为 前缀+文件哈希）
+const _URIPrefix = "docstore://sha256/"
+
+// 文档存储根目录（按内容寻址：根目录/哈希前两位/哈希）
+var baseDir string
+
+// 正在上传的文件（按哈希记录）：相同内容只保存一份，并发上传共用同一个文件，全部结束后才决定是否清理
+var (
+	uploadsMu sync.Mutex
+	uploads   = make(map[string]*upload)
+)
+
+// upload 相同内容的并发上传
+type upload struct {
+	pending int  // 尚未结束的上传数量
+	keep    bool // 文件在这批上传之前已保存过，或其中有上传已存证成功，不能清理
+}
+
+// InitDocStore 初始化本地文档存储
+func InitDocStore(dir string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("创建文档存储目录失败：%w", err)
+	}
+	baseDir = dir
+	return nil
+}
+
+// URI 文件在本地文档存储中的位置
+func URI(hash string) string {
+	return _URIPrefix + hash
+}
+
+// Save 保存文件内容（超过 maxSize 字节时拒绝保存），返回内容的 SHA-256 哈希
+//
+// 相同内容只保存一份；保存成功后必须调用 Finish 结束本次上传
+func Save(r io.Reader, maxSize int64) (string, error) {
+	if baseDir == "" {
+		return "", fmt.Errorf("文档存储未初始化")
+	}
+
+	// 先写入临时文件，边写边计算哈希，完成后再移动到哈希对应的位置
+	tmp, err := os.CreateTemp(baseDir, ".upload-*")
+	if err != nil {
+		return "", fmt.Errorf("创建临时文件失败：%v", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	hasher := sha256.New()
+	size, err := io.Copy(io.MultiWriter(tmp, hasher), io.LimitReader(r, maxSize+1))
+	if err != nil {
+		tmp.Close()
+		return "", fmt.Errorf("写入文件失败：%v", err)
+	}
+	if size > maxSize {
+		tmp.Close()
+		return "", fmt.Errorf("文件大小超过限制（%d 字节）", maxSize)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return "", fmt.Errorf("写入文件失败：%v", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return "", fmt.Errorf("写入文件失败：%v", err)
+	}
+
+	hash := hex.EncodeToString(hasher.Sum(nil))
+	path := filePath(hash)
+
+	// 检查文件是否存在、移动文件和登记上传在同一把锁内完成，避免与 Finish 的清理交错
+	uploadsMu.Lock()
+	defer uploadsMu.Unlock()
+
+	u := uploads[hash]
+	if u == nil {
+		u = &upload{}
+	}
+	if _, err := os.Stat(path); err == nil {
+		// 没有正在进行的上传时，文件是此前存证过的内容
+		if u.pending == 0 {
+			u.keep = true
+		}
+	} else {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return "", fmt.Errorf("创建文档存储目录失败：%v", err)
+		}
+		if err := os.Rename(tmp.Name(), path); err != nil {
+			return "", fmt.Errorf("保存文件失败：%v", err)
+		}
+	}
+	u.pending++
+	uploads[hash] = u
+	return hash, nil
+}
+
+// Finish 结束一次上传，anchored 表示文件哈希已存证到账本上
+//
+// 相同内容的上传全部结束后，如果文件是这批上传新保存的且都没有存证成功，则删除文件
+func Finish(hash string, anchored bool) error {
+	uploadsMu.Lock()
+	defer uploadsMu.Unlock()
+
+	u := uploads[hash]
+	if u == nil {
+		return fmt.Errorf("文件 %s 没有正在进行的上传", hash)
+	}
+	if anchored {
+		u.keep = true
+	}
+	u.pending--
+	if u.pending > 0 {
+		return nil
+	}
+
+	delete(uploads, hash)
+	if u.keep {
+		return nil
+	}
+	if err := os.Remove(filePath(hash)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("删除文件失败：%v", err)
+	}
+	return nil
+}
+
+// Open 打开文件并重新计算内容哈希，确认本地文件未被篡改，返回文件及其大小
+func Open(hash string) (*os.File, int64, error) {
+	if !validHash(hash) {
+		return nil, 0, fmt.Errorf("文件哈希必须为64位小写十六进制的 SHA-256 值")
+	}
+
+	file, err := os.Open(filePath(hash))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, 0, fmt.Errorf("文件 %s 不存在", hash)
+		}
+		return nil, 0, fmt.Errorf("打开文件失败：%v", err)
+	}
+
+	hasher := sha256.New()
+	size, err := io.Copy(hasher, file)
+	if err != nil {
+		file.Close()
+		return nil, 0, fmt.Errorf("读取文件失败：%v", err)
+	}
+	if hex.EncodeToString(hasher.Sum(nil)) != hash {
+		file.Close()
+		return nil, 0, fmt.Errorf("文件 %s 的内容与哈希不一致，可能已被篡改", hash)
+	}
+
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		file.Close()
+		return nil, 0, fmt.Errorf("读取文件失败：%v", err)
+	}
+	return file, size, nil
+}
+
+// filePath 文件哈希对应的存储路径
+func filePath(hash string) string {
+	return filepath.Join(baseDir, hash[:2], hash)
+}
+
+// validHash 检查是否为64位小写十六进制的 SHA-256 值（同时防止路径穿越）
+func validHash(hash string) bool {
+	decoded, err := hex.DecodeString(hash)
+	return err == nil && len(decoded) == sha256.Size && strings.ToLower(hash) == hash
+}
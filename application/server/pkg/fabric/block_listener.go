@@ -14,12 +14,15 @@ import (
 
 	"github.com/hyperledger/fabric-gateway/pkg/client"
 	"github.com/hyperledger/fabric-protos-go-apiv2/common"
+	"github.com/hyperledger/fabric-protos-go-apiv2/peer"
 	bolt "go.etcd.io/bbolt"
+	"google.golang.org/protobuf/proto"
 )
 
 const (
 	_BlocksBucket = "blocks"        // 存储区块数据
 	_LatestBucket = "latest_blocks" // 存储最新区块信息
+	_TxBucket     = "transactions"  // 存储交易所在的区块（交易ID -> 区块号）
 
 	_RetryInterval = 30 * time.Second // 重试间隔时间
 )
@@ -34,6 +37,14 @@ type BlockData struct {
 	SaveTime  time.Time `json:"save_time"`
 }
 
+// TransactionLocation 交易所在的区块
+type TransactionLocation struct {
+	TxID           string `json:"tx_id"`
+	BlockNum       uint64 `json:"block_num"`
+	TxIndex        int    `json:"tx_index"`        // 交易在区块内的序号
+	ValidationCode string `json:"validation_code"` // 验证结果（VALID 表示交易有效）
+}
+
 // LatestBlock 最新区块信息
 type LatestBlock struct {
 	BlockNum uint64    `json:"block_num"`
@@ -82,6 +93,15 @@ func initBlockListener(dataDir string) error {
 			if _, err := tx.CreateBucketIfNotExists([]byte(_BlocksBucket)); err != nil {
 				return fmt.Errorf("创建blocks bucket失败: %w", err)
 			}
+			// 升级前保存的区块没有交易索引，清空最新区块信息，从第0个区块重新同步
+			if tx.Bucket([]byte(_TxBucket)) == nil {
+				if err := tx.DeleteBucket([]byte(_LatestBucket)); err != nil && err != bolt.ErrBucketNotFound {
+					return fmt.Errorf("重置latest_blocks bucket失败: %w", err)
+				}
+				if _, err := tx.CreateBucket([]byte(_TxBucket)); err != nil {
+					return fmt.Errorf("创建transactions bucket失败: %w", err)
+				}
+			}
 			if _, err := tx.CreateBucketIfNotExists([]byte(_LatestBucket)); err != nil {
 				return fmt.Errorf("创建latest_blocks bucket失败: %w", err)
 			}
@@ -237,6 +257,8 @@ func (l *blockEventListener) saveBlock(orgName string, block *common.Block) {
 		SaveTime:  time.Now(),
 	}
 
+	locations := extractTransactionLocations(block)
+
 	// 使用事务保存数据
 	err = l.db.Update(func(tx *bolt.Tx) error {
 		// 保存区块数据
@@ -250,6 +272,18 @@ func (l *blockEventListener) saveBlock(orgName string, block *common.Block) {
 			return fmt.Errorf("保存区块数据失败：%v", err)
 		}
 
+		// 保存交易所在的区块（各组织的账本一致，交易ID不区分组织）
+		_TxBucket := tx.Bucket([]byte(_TxBucket))
+		for _, location := range locations {
+			locationJSON, err := json.Marshal(location)
+			if err != nil {
+				return fmt.Errorf("序列化交易位置失败：%v", err)
+			}
+			if err := _TxBucket.Put([]byte(location.TxID), locationJSON); err != nil {
+				return fmt.Errorf("保存交易位置失败：%v", err)
+			}
+		}
+
 		// 更新最新区块信息
 		_LatestBucket := tx.Bucket([]byte(_LatestBucket))
 		latestBlock := LatestBlock{
@@ -275,6 +309,45 @@ func (l *blockEventListener) saveBlock(orgName string, block *common.Block) {
 	fmt.Printf("已保存组织[%s]的区块[%d]\n", orgName, blockNum)
 }
 
+// extractTransactionLocations 解析区块中各交易的交易ID和验证结果（无法解析的交易和配置交易跳过）
+func extractTransactionLocations(block *common.Block) []*TransactionLocation {
+	var filter []byte
+	if metadata := block.GetMetadata().GetMetadata(); len(metadata) > int(common.BlockMetadataIndex_TRANSACTIONS_FILTER) {
+		filter = metadata[common.BlockMetadataIndex_TRANSACTIONS_FILTER]
+	}
+
+	locations := make([]*TransactionLocation, 0, len(block.GetData().GetData()))
+	for i, data := range block.GetData().GetData() {
+		envelope := &common.Envelope{}
+		if err := proto.Unmarshal(data, envelope); err != nil {
+			continue
+		}
+		payload := &common.Payload{}
+		if err := proto.Unmarshal(envelope.GetPayload(), payload); err != nil {
+			continue
+		}
+		channelHeader := &common.ChannelHeader{}
+		if err := proto.Unmarshal(payload.GetHeader().GetChannelHeader(), channelHeader); err != nil {
+			continue
+		}
+		if channelHeader.GetTxId() == "" {
+			continue
+		}
+
+		validationCode := peer.TxValidationCode_VALID
+		if i < len(filter) {
+			validationCode = peer.TxValidationCode(filter[i])
+		}
+		locations = append(locations, &TransactionLocation{
+			TxID:           channelHeader.GetTxId(),
+			BlockNum:       block.GetHeader().GetNumber(),
+			TxIndex:        i,
+			ValidationCode: validationCode.String(),
+		})
+	}
+	return locations
+}
+
 // Close 关闭监听器
 func (l *blockEventListener) Close() error {
 	if l.cancel != nil {
@@ -312,6 +385,63 @@ func (l *blockEventListener) GetBlockByNumber(orgName string, blockNum uint64) (
 	return &blockData, nil
 }
 
+// GetBlockChain 从指定区块开始按区块号升序查询连续的区块（最多 limit 个，遇到尚未同步的区块时停止）
+func (l *blockEventListener) GetBlockChain(orgName string, fromBlock uint64, limit int) ([]*BlockData, error) {
+	blocks := make([]*BlockData, 0, limit)
+
+	err := l.db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(_BlocksBucket))
+		if b == nil {
+			return fmt.Errorf("blocks bucket不存在")
+		}
+
+		for i := 0; i < limit; i++ {
+			blockKey := fmt.Sprintf("%s_%d", orgName, fromBlock+uint64(i))
+			data := b.Get([]byte(blockKey))
+			if data == nil {
+				break
+			}
+			var block BlockData
+			if err := json.Unmarshal(data, &block); err != nil {
+				return err
+			}
+			blocks = append(blocks, &block)
+		}
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return blocks, nil
+}
+
+// GetTransactionLocation 根据交易ID查询交易所在的区块（区块尚未同步时返回错误）
+func (l *blockEventListener) GetTransactionLocation(txID string) (*TransactionLocation, error) {
+	var location TransactionLocation
+
+	err := l.db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(_TxBucket))
+		if b == nil {
+			return fmt.Errorf("transactions bucket不存在")
+		}
+
+		data := b.Get([]byte(txID))
+		if data == nil {
+			return fmt.Errorf("交易所在的区块尚未同步")
+		}
+
+		return json.Unmarshal(data, &location)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &location, nil
+}
+
 // BlockQueryResult 区块查询结果
 type BlockQueryResult struct {
 	Blocks   []*BlockData `json:"blocks"`    // 区块数据列表
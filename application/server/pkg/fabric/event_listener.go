@@ -0,0 +1,305 @@
+package fabric
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/hyperledger/fabric-gateway/pkg/client"
+	bolt "go.etcd.io/bbolt"
+)
+
+const (
+	_EventsBucket     = "chaincode_events"  // 存储链码事件
+	_CheckpointBucket = "event_checkpoints" // 存储链码事件检查点
+)
+
+// ChaincodeEventData 链码事件数据结构
+type ChaincodeEventData struct {
+	BlockNum  uint64          `json:"block_num"`
+	TxID      string          `json:"tx_id"`
+	EventName string          `json:"event_name"`
+	EntityID  string          `json:"entity_id"`
+	Timestamp time.Time       `json:"timestamp"`
+	Payload   json.RawMessage `json:"payload"`
+	SaveTime  time.Time       `json:"save_time"`
+}
+
+// eventCheckpoint 链码事件检查点，实现 client.Checkpoint 接口
+type eventCheckpoint struct {
+	Block uint64 `json:"block_num"` // 下一个事件所在的区块号
+	TxID  string `json:"tx_id"`     // 该区块内最后处理的交易ID
+}
+
+// BlockNumber 下一个事件所在的区块号
+func (c *eventCheckpoint) BlockNumber() uint64 {
+	return c.Block
+}
+
+// TransactionID 该区块内最后处理的交易ID
+func (c *eventCheckpoint) TransactionID() string {
+	return c.TxID
+}
+
+// chaincodeEventListener 链码事件监听器
+type chaincodeEventListener struct {
+	network       *client.Network
+	chaincodeName string
+	ctx           context.Context
+	db            *bolt.DB
+}
+
+var (
+	eventListener     *chaincodeEventListener
+	eventListenerOnce sync.Once
+)
+
+// GetEventListener 获取链码事件监听器实例
+func GetEventListener() *chaincodeEventListener {
+	return eventListener
+}
+
+// startEventListener 启动链码事件监听（链码事件在通道内一致，只需监听一个组织的网络）
+func startEventListener(network *client.Network, chaincodeName string) error {
+	if listener == nil {
+		return fmt.Errorf("区块监听器未初始化")
+	}
+
+	var initErr error
+	eventListenerOnce.Do(func() {
+		// 与区块数据共用BBolt数据库
+		if err := listener.db.Update(func(tx *bolt.Tx) error {
+			if _, err := tx.CreateBucketIfNotExists([]byte(_EventsBucket)); err != nil {
+				return fmt.Errorf("创建chaincode_events bucket失败: %w", err)
+			}
+			if _, err := tx.CreateBucketIfNotExists([]byte(_CheckpointBucket)); err != nil {
+				return fmt.Errorf("创建event_checkpoints bucket失败: %w", err)
+			}
+			return nil
+		}); err != nil {
+			initErr = fmt.Errorf("初始化数据库失败：%w", err)
+			return
+		}
+
+		eventListener = &chaincodeEventListener{
+			network:       network,
+			chaincodeName: chaincodeName,
+			ctx:           listener.ctx,
+			db:            listener.db,
+		}
+		go eventListener.listen()
+	})
+
+	return initErr
+}
+
+// getCheckpoint 获取保存的检查点
+func (l *chaincodeEventListener) getCheckpoint() (*eventCheckpoint, bool) {
+	var checkpoint eventCheckpoint
+	var exists bool
+
+	err := l.db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(_CheckpointBucket))
+		data := b.Get([]byte(l.chaincodeName))
+		if data == nil {
+			return nil
+		}
+		exists = true
+		return json.Unmarshal(data, &checkpoint)
+	})
+
+	if err != nil {
+		fmt.Printf("获取链码事件检查点失败：%v\n", err)
+		return nil, false
+	}
+
+	return &checkpoint, exists
+}
+
+// listen 监听链码事件，中断后从检查点重新开始
+func (l *chaincodeEventListener) listen() {
+	retryCount := 0
+	for {
+		var option client.ChaincodeEventsOption
+		if checkpoint, exists := l.getCheckpoint(); exists {
+			option = client.WithCheckpoint(checkpoint)
+		} else {
+			// 首次启动，从0开始
+			option = client.WithStartBlock(0)
+		}
+
+		events, err := l.network.ChaincodeEvents(l.ctx, l.chaincodeName, option)
+		if err != nil {
+			retryCount++
+			fmt.Printf("创建链码事件请求失败（已重试%d次）：%v\n", retryCount, err)
+			select {
+			case <-l.ctx.Done():
+				return
+			case <-time.After(_RetryInterval):
+				continue
+			}
+		}
+
+		for event := range events {
+			l.saveEvent(event)
+		}
+
+		retryCount++
+		fmt.Printf("链码事件监听中断（已重试%d次），准备重试...\n", retryCount)
+		select {
+		case <-l.ctx.Done():
+			return
+		case <-time.After(_RetryInterval):
+		}
+	}
+}
+
+// eventKey 事件存储键（按区块号和交易ID排序）
+func eventKey(blockNum uint64, txID string) []byte {
+	return []byte(fmt.Sprintf("%020d_%s", blockNum, txID))
+}
+
+// saveEvent 保存链码事件并更新检查点
+func (l *chaincodeEventListener) saveEvent(event *client.ChaincodeEvent) {
+	if event == nil {
+		return
+	}
+
+	// 解析链码事件负载中的实体ID和账本交易时间
+	var payload struct {
+		EntityID  string    `json:"entityId"`
+		Timestamp time.Time `json:"timestamp"`
+	}
+	if err := json.Unmarshal(event.Payload, &payload); err != nil {
+		fmt.Printf("解析链码事件负载失败：%v\n", err)
+	}
+
+	eventData := ChaincodeEventData{
+		BlockNum:  event.BlockNumber,
+		TxID:      event.TransactionID,
+		EventName: event.EventName,
+		EntityID:  payload.EntityID,
+		Timestamp: payload.Timestamp,
+		Payload:   event.Payload,
+		SaveTime:  time.Now(),
+	}
+	if !json.Valid(eventData.Payload) {
+		eventData.Payload = nil
+	}
+
+	// 使用事务同时保存事件和检查点
+	err := l.db.Update(func(tx *bolt.Tx) error {
+		eventJSON, err := json.Marshal(eventData)
+		if err != nil {
+			return fmt.Errorf("序列化链码事件失败：%v", err)
+		}
+		if err := tx.Bucket([]byte(_EventsBucket)).Put(eventKey(event.BlockNumber, event.TransactionID), eventJSON); err != nil {
+			return fmt.Errorf("保存链码事件失败：%v", err)
+		}
+
+		checkpointJSON, err := json.Marshal(eventCheckpoint{
+			Block: event.BlockNumber,
+			TxID:  event.TransactionID,
+		})
+		if err != nil {
+			return fmt.Errorf("序列化检查点失败：%v", err)
+		}
+		if err := tx.Bucket([]byte(_CheckpointBucket)).Put([]byte(l.chaincodeName), checkpointJSON); err != nil {
+			return fmt.Errorf("保存检查点失败：%v", err)
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		fmt.Printf("保存链码事件失败：%v\n", err)
+		return
+	}
+
+	fmt.Printf("已保存链码事件[%s]，区块[%d]，交易[%s]\n", event.EventName, event.BlockNumber, event.TransactionID)
+}
+
+// EventFilter 链码事件查询条件（为空的条件不参与过滤）
+type EventFilter struct {
+	EventName string    // 事件名称
+	EntityID  string    // 实体ID
+	StartTime time.Time // 开始时间（包含）
+	EndTime   time.Time // 结束时间（包含）
+}
+
+// match 判断事件是否满足查询条件
+func (f EventFilter) match(event *ChaincodeEventData) bool {
+	if f.EventName != "" && event.EventName != f.EventName {
+		return false
+	}
+	if f.EntityID != "" && event.EntityID != f.EntityID {
+		return false
+	}
+	if !f.StartTime.IsZero() && event.Timestamp.Before(f.StartTime) {
+		return false
+	}
+	if !f.EndTime.IsZero() && event.Timestamp.After(f.EndTime) {
+		return false
+	}
+	return true
+}
+
+// EventQueryResult 链码事件查询结果
+type EventQueryResult struct {
+	Events   []*ChaincodeEventData `json:"events"`    // 事件列表
+	Total    int                   `json:"total"`     // 满足条件的总记录数
+	PageSize int                   `json:"page_size"` // 每页大小
+	PageNum  int                   `json:"page_num"`  // 当前页码
+	HasMore  bool                  `json:"has_more"`  // 是否还有更多数据
+}
+
+// GetEvents 分页查询链码事件（按区块号降序）
+func (l *chaincodeEventListener) GetEvents(filter EventFilter, pageSize, pageNum int) (*EventQueryResult, error) {
+	if pageSize <= 0 {
+		pageSize = 10
+	}
+	if pageNum <= 0 {
+		pageNum = 1
+	}
+
+	result := EventQueryResult{
+		Events:   make([]*ChaincodeEventData, 0, pageSize),
+		PageSize: pageSize,
+		PageNum:  pageNum,
+	}
+	skip := (pageNum - 1) * pageSize
+
+	err := l.db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(_EventsBucket))
+		if b == nil {
+			return fmt.Errorf("chaincode_events bucket不存在")
+		}
+
+		c := b.Cursor()
+		for k, v := c.Last(); k != nil; k, v = c.Prev() {
+			var event ChaincodeEventData
+			if err := json.Unmarshal(v, &event); err != nil {
+				return err
+			}
+			if !filter.match(&event) {
+				continue
+			}
+
+			if result.Total >= skip && len(result.Events) < pageSize {
+				result.Events = append(result.Events, &event)
+			}
+			result.Total++
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	result.HasMore = result.Total > skip+len(result.Events)
+	return &result, nil
+}
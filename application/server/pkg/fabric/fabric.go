@@ -7,6 +7,7 @@ import (
 	"os"
 	"path"
 	"path/filepath"
+	"sort"
 	"time"
 
 	"github.com/hyperledger/fabric-gateway/pkg/client"
@@ -71,6 +72,11 @@ func InitFabric() error {
 		if err := addNetwork(orgName, network); err != nil {
 			return fmt.Errorf("添加网络到区块监听器失败：%v", err)
 		}
+
+		// 启动链码事件监听器（仅使用第一个连接成功的组织网络）
+		if err := startEventListener(network, config.GlobalConfig.Fabric.ChaincodeName); err != nil {
+			return fmt.Errorf("启动链码事件监听器失败：%v", err)
+		}
 	}
 
 	return nil
@@ -81,6 +87,34 @@ func GetContract(orgName string) *client.Contract {
 	return contracts[orgName]
 }
 
+// GetMSPID 获取指定组织的 MSP ID
+func GetMSPID(orgName string) string {
+	return config.GlobalConfig.Fabric.Organizations[orgName].MSPID
+}
+
+// Organization 配置文件中的组织
+type Organization struct {
+	OrgName string `json:"org"`   // 组织在配置文件中的名称
+	Name    string `json:"name"`  // 组织显示名称
+	MSPID   string `json:"mspId"` // 组织 MSP ID
+}
+
+// GetOrganizations 获取配置文件中的所有组织（按组织名称排序）
+func GetOrganizations() []Organization {
+	organizations := make([]Organization, 0, len(config.GlobalConfig.Fabric.Organizations))
+	for orgName, orgConfig := range config.GlobalConfig.Fabric.Organizations {
+		organizations = append(organizations, Organization{
+			OrgName: orgName,
+			Name:    orgConfig.Name,
+			MSPID:   orgConfig.MSPID,
+		})
+	}
+	sort.Slice(organizations, func(i, j int) bool {
+		return organizations[i].OrgName < organizations[j].OrgName
+	})
+	return organizations
+}
+
 // ExtractErrorMessage 从错误中提取详细信息
 func ExtractErrorMessage(err error) string {
 	if err == nil {
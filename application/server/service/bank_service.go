@@ -4,24 +4,128 @@ import (
 	"application/pkg/fabric"
 	"encoding/json"
 	"fmt"
-	"time"
+	"io"
+	"os"
+
+	"github.com/hyperledger/fabric-gateway/pkg/client"
 )
 
 type BankService struct{}
 
 const BANK_ORG = "org2" // 银行组织
 
-// CompleteTransaction 完成交易
+// CompleteTransaction 完成交易（分两笔交易提交：先由集合成员背书结算，复核签署、计算税费并公开买家，再经登记机构背书过户）
 func (s *BankService) CompleteTransaction(txID string) error {
 	contract := fabric.GetContract(BANK_ORG)
-	now := time.Now().Format(time.RFC3339)
-	_, err := contract.SubmitTransaction("CompleteTransaction", txID, now)
+
+	// 已结算、过户失败的交易直接重试过户（升级前没有私有数据的旧交易无法结算，由链码拒绝过户）
+	transaction, err := s.QueryTransaction(txID)
+	if err != nil {
+		return err
+	}
+	if transaction["status"] == "PAID" {
+		_, err = contract.Submit("SettleTransaction", client.WithArguments(txID), withPrivateDataEndorsers())
+		if err != nil {
+			return fmt.Errorf("结算交易失败：%s", fabric.ExtractErrorMessage(err))
+		}
+	}
+
+	endorsement, err := withRealEstateEndorsement(BANK_ORG, txID)
+	if err != nil {
+		return err
+	}
+	_, err = contract.Submit("CompleteTransaction", client.WithArguments(txID), endorsement)
 	if err != nil {
 		return fmt.Errorf("完成交易失败：%s", fabric.ExtractErrorMessage(err))
 	}
 	return nil
 }
 
+// RecordPayment 登记买家向托管账户的付款，返回交易是否已付清（付清后还需调用 CompleteTransaction 结算并经登记机构背书完成过户）
+func (s *BankService) RecordPayment(txID, amount, reference string) (bool, error) {
+	contract := fabric.GetContract(BANK_ORG)
+	_, err := contract.Submit("RecordPayment", client.WithArguments(txID, amount, reference), withPrivateDataEndorsers())
+	if err != nil {
+		return false, fmt.Errorf("登记付款失败：%s", fabric.ExtractErrorMessage(err))
+	}
+
+	transaction, err := s.QueryTransaction(txID)
+	if err != nil {
+		return false, err
+	}
+	return transaction["status"] == "PAID", nil
+}
+
+// QueryTransactionPayments 查询交易的托管付款记录和未付余额
+func (s *BankService) QueryTransactionPayments(txID string) (map[string]interface{}, error) {
+	contract := fabric.GetContract(BANK_ORG)
+	result, err := contract.EvaluateTransaction("QueryTransactionPayments", txID)
+	if err != nil {
+		return nil, fmt.Errorf("查询付款记录失败：%s", fabric.ExtractErrorMessage(err))
+	}
+
+	var summary map[string]interface{}
+	if err := json.Unmarshal(result, &summary); err != nil {
+		return nil, fmt.Errorf("解析付款记录失败：%v", err)
+	}
+
+	return summary, nil
+}
+
+// QueryTransactionFees 查询交易完成时计算的税费明细
+func (s *BankService) QueryTransactionFees(txID string) ([]map[string]interface{}, error) {
+	contract := fabric.GetContract(BANK_ORG)
+	result, err := contract.EvaluateTransaction("QueryTransactionFees", txID)
+	if err != nil {
+		return nil, fmt.Errorf("查询交易税费失败：%s", fabric.ExtractErrorMessage(err))
+	}
+
+	var fees []map[string]interface{}
+	if err := json.Unmarshal(result, &fees); err != nil {
+		return nil, fmt.Errorf("解析交易税费失败：%v", err)
+	}
+
+	return fees, nil
+}
+
+// QueryTransactionPrivateDetails 查询交易私有数据（买家、价格和盐值）
+func (s *BankService) QueryTransactionPrivateDetails(txID string) (map[string]interface{}, error) {
+	return queryTransactionPrivateDetails(BANK_ORG, txID)
+}
+
+// QueryTransactionSigningPayload 查询交易待签署的条款及买卖双方的签署状态
+func (s *BankService) QueryTransactionSigningPayload(txID string) (map[string]interface{}, error) {
+	return queryTransactionSigningPayload(BANK_ORG, txID)
+}
+
+// CancelTransaction 取消交易
+func (s *BankService) CancelTransaction(txID, reason string) error {
+	contract := fabric.GetContract(BANK_ORG)
+	endorsement, err := withRealEstateEndorsement(BANK_ORG, txID)
+	if err != nil {
+		return err
+	}
+	_, err = contract.Submit("CancelTransaction", client.WithArguments(txID, reason), endorsement)
+	if err != nil {
+		return fmt.Errorf("取消交易失败：%s", fabric.ExtractErrorMessage(err))
+	}
+	return nil
+}
+
+// ExpireTransaction 使超过有效期的交易过期
+func (s *BankService) ExpireTransaction(txID string) error {
+	contract := fabric.GetContract(BANK_ORG)
+	endorsement, err := withRealEstateEndorsement(BANK_ORG, txID)
+	if err != nil {
+		return err
+	}
+	_, err = contract.Submit("ExpireTransaction", client.WithArguments(txID), endorsement)
+	if err != nil {
+		return fmt.Errorf("交易过期处理失败：%s", fabric.ExtractErrorMessage(err))
+	}
+	return nil
+}
+
 // QueryTransaction 查询交易信息
 func (s *BankService) QueryTransaction(txID string) (map[string]interface{}, error) {
 	contract := fabric.GetContract(BANK_ORG)
@@ -54,6 +158,116 @@ func (s *BankService) QueryTransactionList(pageSize int32, bookmark string, stat
 	return queryResult, nil
 }
 
+// RegisterMortgage 登记抵押
+func (s *BankService) RegisterMortgage(id, realEstateID, amount, currency string) error {
+	contract := fabric.GetContract(BANK_ORG)
+	_, err := contract.SubmitTransaction("RegisterMortgage", id, realEstateID, amount, currency)
+	if err != nil {
+		return fmt.Errorf("登记抵押失败：%s", fabric.ExtractErrorMessage(err))
+	}
+	return nil
+}
+
+// ConsentMortgageTransfer 同意转让抵押房产
+func (s *BankService) ConsentMortgageTransfer(realEstateID, mortgageID string) error {
+	contract := fabric.GetContract(BANK_ORG)
+	_, err := contract.SubmitTransaction("ConsentMortgageTransfer", realEstateID, mortgageID)
+	if err != nil {
+		return fmt.Errorf("同意转让失败：%s", fabric.ExtractErrorMessage(err))
+	}
+	return nil
+}
+
+// ReleaseMortgage 注销抵押
+func (s *BankService) ReleaseMortgage(realEstateID, mortgageID string) error {
+	contract := fabric.GetContract(BANK_ORG)
+	_, err := contract.SubmitTransaction("ReleaseMortgage", realEstateID, mortgageID)
+	if err != nil {
+		return fmt.Errorf("注销抵押失败：%s", fabric.ExtractErrorMessage(err))
+	}
+	return nil
+}
+
+// QueryMortgageList 查询房产的抵押列表
+func (s *BankService) QueryMortgageList(realEstateID string) ([]map[string]interface{}, error) {
+	contract := fabric.GetContract(BANK_ORG)
+	result, err := contract.EvaluateTransaction("QueryMortgageList", realEstateID)
+	if err != nil {
+		return nil, fmt.Errorf("查询抵押列表失败：%s", fabric.ExtractErrorMessage(err))
+	}
+
+	var mortgages []map[string]interface{}
+	if err := json.Unmarshal(result, &mortgages); err != nil {
+		return nil, fmt.Errorf("解析抵押数据失败：%v", err)
+	}
+
+	return mortgages, nil
+}
+
+// QueryRealEstateHistory 查询房产历史记录
+func (s *BankService) QueryRealEstateHistory(id string) ([]map[string]interface{}, error) {
+	contract := fabric.GetContract(BANK_ORG)
+	result, err := contract.EvaluateTransaction("QueryRealEstateHistory", id)
+	if err != nil {
+		return nil, fmt.Errorf("查询房产历史记录失败：%s", fabric.ExtractErrorMessage(err))
+	}
+
+	var history []map[string]interface{}
+	if err := json.Unmarshal(result, &history); err != nil {
+		return nil, fmt.Errorf("解析历史记录失败：%v", err)
+	}
+
+	return history, nil
+}
+
+// QueryTransactionHistory 查询交易历史记录
+func (s *BankService) QueryTransactionHistory(txID string) ([]map[string]interface{}, error) {
+	contract := fabric.GetContract(BANK_ORG)
+	result, err := contract.EvaluateTransaction("QueryTransactionHistory", txID)
+	if err != nil {
+		return nil, fmt.Errorf("查询交易历史记录失败：%s", fabric.ExtractErrorMessage(err))
+	}
+
+	var history []map[string]interface{}
+	if err := json.Unmarshal(result, &history); err != nil {
+		return nil, fmt.Errorf("解析历史记录失败：%v", err)
+	}
+
+	return history, nil
+}
+
+// QueryRealEstateByOwner 分页查询所有者持有的房产列表
+func (s *BankService) QueryRealEstateByOwner(owner string, pageSize int32, bookmark string) (map[string]interface{}, error) {
+	contract := fabric.GetContract(BANK_ORG)
+	result, err := contract.EvaluateTransaction("QueryRealEstateByOwner", owner, fmt.Sprintf("%d", pageSize), bookmark)
+	if err != nil {
+		return nil, fmt.Errorf("查询所有者持有的房产列表失败：%s", fabric.ExtractErrorMessage(err))
+	}
+
+	var queryResult map[string]interface{}
+	if err := json.Unmarshal(result, &queryResult); err != nil {
+		return nil, fmt.Errorf("解析查询结果失败：%v", err)
+	}
+
+	return queryResult, nil
+}
+
+// QueryTransactionsByParty 分页查询交易方参与的交易列表
+func (s *BankService) QueryTransactionsByParty(party string, pageSize int32, bookmark string) (map[string]interface{}, error) {
+	contract := fabric.GetContract(BANK_ORG)
+	result, err := contract.EvaluateTransaction("QueryTransactionsByParty", party, fmt.Sprintf("%d", pageSize), bookmark)
+	if err != nil {
+		return nil, fmt.Errorf("查询交易方参与的交易列表失败：%s", fabric.ExtractErrorMessage(err))
+	}
+
+	var queryResult map[string]interface{}
+	if err := json.Unmarshal(result, &queryResult); err != nil {
+		return nil, fmt.Errorf("解析查询结果失败：%v", err)
+	}
+
+	return queryResult, nil
+}
+
 // QueryBlockList 分页查询区块列表
 func (s *BankService) QueryBlockList(pageSize int, pageNum int) (*fabric.BlockQueryResult, error) {
 	result, err := fabric.GetBlockListener().GetBlocksByOrg(BANK_ORG, pageSize, pageNum)
@@ -62,3 +276,27 @@ func (s *BankService) QueryBlockList(pageSize int, pageNum int) (*fabric.BlockQu
 	}
 	return result, nil
 }
+
+// QueryEventList 分页查询链码事件列表
+func (s *BankService) QueryEventList(filter fabric.EventFilter, pageSize int, pageNum int) (*fabric.EventQueryResult, error) {
+	result, err := fabric.GetEventListener().GetEvents(filter, pageSize, pageNum)
+	if err != nil {
+		return nil, fmt.Errorf("查询链码事件列表失败：%v", err)
+	}
+	return result, nil
+}
+
+// UploadDocument 保存文件到本地文档存储并将文件哈希存证到房产、交易或交易方上，返回文件哈希
+func (s *BankService) UploadDocument(entityType, entityID, docType string, file io.Reader) (string, error) {
+	return uploadDocument(BANK_ORG, entityType, entityID, docType, file)
+}
+
+// OpenDocument 确认文件哈希已存证到对象上后打开文件，返回文件及其大小
+func (s *BankService) OpenDocument(entityType, entityID, hash string) (*os.File, int64, error) {
+	return openDocument(BANK_ORG, entityType, entityID, hash)
+}
+
+// QueryDocuments 查询对象的文档存证记录
+func (s *BankService) QueryDocuments(entityType, entityID string) ([]map[string]interface{}, error) {
+	return queryDocuments(BANK_ORG, entityType, entityID)
+}
@@ -0,0 +1,80 @@
+package service
+
+import (
+	"application/pkg/certificate"
+	"application/pkg/fabric"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// buildCertificateContent 通过指定组织查询房产信息和最后一次修改房产的交易，结合本地区块存储生成证书内容
+func buildCertificateContent(orgName, realEstateID string) (*certificate.Content, error) {
+	contract := fabric.GetContract(orgName)
+	result, err := contract.EvaluateTransaction("QueryRealEstate", realEstateID)
+	if err != nil {
+		return nil, fmt.Errorf("查询房产信息失败：%s", fabric.ExtractErrorMessage(err))
+	}
+
+	var realEstate struct {
+		ID              string              `json:"id"`
+		PropertyAddress string              `json:"propertyAddress"`
+		Area            string              `json:"area"`
+		PropertyType    string              `json:"propertyType"`
+		Owners          []certificate.Owner `json:"owners"`
+		Status          string              `json:"status"`
+		UpdateTime      time.Time           `json:"updateTime"`
+	}
+	if err := json.Unmarshal(result, &realEstate); err != nil {
+		return nil, fmt.Errorf("解析房产数据失败：%v", err)
+	}
+	if realEstate.PropertyType == "" {
+		realEstate.PropertyType = DEFAULT_PROPERTY_TYPE
+	}
+
+	// 历史记录按时间排序，最后一条写入记录即为当前状态对应的交易
+	result, err = contract.EvaluateTransaction("QueryRealEstateHistory", realEstateID)
+	if err != nil {
+		return nil, fmt.Errorf("查询房产历史记录失败：%s", fabric.ExtractErrorMessage(err))
+	}
+
+	var history []struct {
+		TxID     string `json:"txId"`
+		IsDelete bool   `json:"isDelete"`
+	}
+	if err := json.Unmarshal(result, &history); err != nil {
+		return nil, fmt.Errorf("解析历史记录失败：%v", err)
+	}
+
+	txID := ""
+	for _, record := range history {
+		if !record.IsDelete {
+			txID = record.TxID
+		}
+	}
+	if txID == "" {
+		return nil, fmt.Errorf("房产 %s 没有历史记录", realEstateID)
+	}
+
+	location, err := fabric.GetBlockListener().GetTransactionLocation(txID)
+	if err != nil {
+		return nil, fmt.Errorf("查询交易 %s 所在区块失败：%v", txID, err)
+	}
+	block, err := fabric.GetBlockListener().GetBlockByNumber(orgName, location.BlockNum)
+	if err != nil {
+		return nil, fmt.Errorf("查询区块 %d 失败：%v", location.BlockNum, err)
+	}
+
+	return &certificate.Content{
+		RealEstateID: realEstate.ID,
+		Address:      realEstate.PropertyAddress,
+		Area:         realEstate.Area,
+		PropertyType: realEstate.PropertyType,
+		Owners:       realEstate.Owners,
+		Status:       realEstate.Status,
+		UpdateTime:   realEstate.UpdateTime,
+		TxID:         txID,
+		BlockNum:     block.BlockNum,
+		BlockHash:    block.BlockHash,
+	}, nil
+}
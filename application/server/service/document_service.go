@@ -0,0 +1,69 @@
+package service
+
+import (
+	"application/pkg/docstore"
+	"application/pkg/fabric"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+)
+
+// MAX_DOCUMENT_SIZE 上传文档的大小上限（字节）
+const MAX_DOCUMENT_SIZE = 20 << 20
+
+// uploadDocument 通过指定组织保存文件到本地文档存储，并将文件哈希存证到账本上，返回文件哈希
+func uploadDocument(orgName, entityType, entityID, docType string, file io.Reader) (string, error) {
+	hash, err := docstore.Save(file, MAX_DOCUMENT_SIZE)
+	if err != nil {
+		return "", fmt.Errorf("保存文件失败：%v", err)
+	}
+
+	contract := fabric.GetContract(orgName)
+	_, err = contract.SubmitTransaction("AttachDocument", entityType, entityID, docType, hash, docstore.URI(hash))
+
+	// 结束本次上传：相同内容的并发上传全部存证失败时才清理新保存的文件（相同内容可能已存证到其他对象上，不能删除）
+	if finishErr := docstore.Finish(hash, err == nil); finishErr != nil {
+		log.Printf("清理未存证的文件 %s 失败：%v", hash, finishErr)
+	}
+	if err != nil {
+		return "", fmt.Errorf("存证文档失败：%s", fabric.ExtractErrorMessage(err))
+	}
+	return hash, nil
+}
+
+// openDocument 通过指定组织确认文件哈希已存证到对象上后，打开本地文档存储中的文件，返回文件及其大小
+func openDocument(orgName, entityType, entityID, hash string) (*os.File, int64, error) {
+	contract := fabric.GetContract(orgName)
+	result, err := contract.EvaluateTransaction("VerifyDocument", entityType, entityID, hash)
+	if err != nil {
+		return nil, 0, fmt.Errorf("校验文档失败：%s", fabric.ExtractErrorMessage(err))
+	}
+
+	var anchored bool
+	if err := json.Unmarshal(result, &anchored); err != nil {
+		return nil, 0, fmt.Errorf("解析校验结果失败：%v", err)
+	}
+	if !anchored {
+		return nil, 0, fmt.Errorf("文件 %s 未存证到 %s %s", hash, entityType, entityID)
+	}
+
+	return docstore.Open(hash)
+}
+
+// queryDocuments 通过指定组织查询对象的文档存证记录
+func queryDocuments(orgName, entityType, entityID string) ([]map[string]interface{}, error) {
+	contract := fabric.GetContract(orgName)
+	result, err := contract.EvaluateTransaction("QueryDocuments", entityType, entityID)
+	if err != nil {
+		return nil, fmt.Errorf("查询文档存证记录失败：%s", fabric.ExtractErrorMessage(err))
+	}
+
+	var documents []map[string]interface{}
+	if err := json.Unmarshal(result, &documents); err != nil {
+		return nil, fmt.Errorf("解析文档存证记录失败：%v", err)
+	}
+
+	return documents, nil
+}
@@ -0,0 +1,141 @@
+package service
+
+import (
+	"application/pkg/fabric"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+)
+
+const (
+	_ExpirySweepInterval = 5 * time.Minute // 过期交易和到期冻结扫描间隔
+	_ExpirySweepPageSize = 100             // 每次分页查询的记录数
+)
+
+// pendingTransactionPage 交易草稿和待付款交易分页结果（仅解析清理所需字段）
+type pendingTransactionPage struct {
+	Records []struct {
+		ID         string    `json:"id"`
+		ExpireTime time.Time `json:"expireTime"`
+	} `json:"records"`
+	Bookmark            string `json:"bookmark"`
+	FetchedRecordsCount int32  `json:"fetchedRecordsCount"`
+}
+
+// activeFreezePage 冻结中记录分页结果（仅解析清理所需字段）
+type activeFreezePage struct {
+	Records []struct {
+		RealEstateID string    `json:"realEstateId"`
+		ExpireTime   time.Time `json:"expireTime"`
+	} `json:"records"`
+	Bookmark            string `json:"bookmark"`
+	FetchedRecordsCount int32  `json:"fetchedRecordsCount"`
+}
+
+// StartExpirySweeper 启动后台任务，定期将超过有效期的待付款交易提交过期处理，并结束已到期的司法冻结
+func StartExpirySweeper() {
+	go func() {
+		ticker := time.NewTicker(_ExpirySweepInterval)
+		defer ticker.Stop()
+
+		bankService := &BankService{}
+		realtyService := &RealtyAgencyService{}
+		for range ticker.C {
+			if err := bankService.sweepExpiredTransactions(); err != nil {
+				log.Printf("扫描过期交易失败：%v", err)
+			}
+			if err := realtyService.sweepExpiredFreezes(); err != nil {
+				log.Printf("扫描到期冻结失败：%v", err)
+			}
+		}
+	}()
+}
+
+// sweepExpiredTransactions 遍历所有交易草稿和待付款交易，对已过期的交易提交过期处理
+func (s *BankService) sweepExpiredTransactions() error {
+	contract := fabric.GetContract(BANK_ORG)
+	now := time.Now()
+
+	expired := make([]string, 0)
+	for _, status := range []string{"DRAFT", "PENDING"} {
+		bookmark := ""
+		for {
+			result, err := contract.EvaluateTransaction("QueryTransactionList", fmt.Sprintf("%d", _ExpirySweepPageSize), bookmark, status)
+			if err != nil {
+				return fmt.Errorf("查询交易列表失败：%s", fabric.ExtractErrorMessage(err))
+			}
+
+			var page pendingTransactionPage
+			if err := json.Unmarshal(result, &page); err != nil {
+				return fmt.Errorf("解析查询结果失败：%v", err)
+			}
+
+			for _, record := range page.Records {
+				if !record.ExpireTime.IsZero() && now.After(record.ExpireTime) {
+					expired = append(expired, record.ID)
+				}
+			}
+
+			// 链码会跳过无法解析的记录，返回的记录数可能少于分页大小，以书签判断是否已遍历完
+			if page.Bookmark == "" || page.FetchedRecordsCount == 0 {
+				break
+			}
+			bookmark = page.Bookmark
+		}
+	}
+
+	// 分页遍历结束后再提交，避免修改状态影响分页书签
+	for _, txID := range expired {
+		if err := s.ExpireTransaction(txID); err != nil {
+			log.Printf("交易[%s]过期处理失败：%v", txID, err)
+			continue
+		}
+		log.Printf("交易[%s]已过期", txID)
+	}
+
+	return nil
+}
+
+// sweepExpiredFreezes 遍历所有冻结中的记录，对已到期的冻结提交结束处理
+func (s *RealtyAgencyService) sweepExpiredFreezes() error {
+	contract := fabric.GetContract(REALTY_ORG)
+	now := time.Now()
+
+	expired := make([]string, 0)
+	bookmark := ""
+	for {
+		result, err := contract.EvaluateTransaction("QueryFreezeList", fmt.Sprintf("%d", _ExpirySweepPageSize), bookmark, "ACTIVE")
+		if err != nil {
+			return fmt.Errorf("查询冻结记录列表失败：%s", fabric.ExtractErrorMessage(err))
+		}
+
+		var page activeFreezePage
+		if err := json.Unmarshal(result, &page); err != nil {
+			return fmt.Errorf("解析查询结果失败：%v", err)
+		}
+
+		for _, record := range page.Records {
+			if now.After(record.ExpireTime) {
+				expired = append(expired, record.RealEstateID)
+			}
+		}
+
+		// 链码会跳过无法解析的记录，返回的记录数可能少于分页大小，以书签判断是否已遍历完
+		if page.Bookmark == "" || page.FetchedRecordsCount == 0 {
+			break
+		}
+		bookmark = page.Bookmark
+	}
+
+	// 分页遍历结束后再提交，避免修改状态影响分页书签
+	for _, realEstateID := range expired {
+		if err := s.ExpireFreeze(realEstateID); err != nil {
+			log.Printf("房产[%s]冻结到期处理失败：%v", realEstateID, err)
+			continue
+		}
+		log.Printf("房产[%s]的冻结已到期，房产已恢复冻结前的状态", realEstateID)
+	}
+
+	return nil
+}
@@ -0,0 +1,133 @@
+package service
+
+import (
+	"application/pkg/fabric"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/hyperledger/fabric-gateway/pkg/client"
+)
+
+// GovernanceService 组织权限治理（组织由配置文件指定，新组织加入时无需修改代码）
+type GovernanceService struct{}
+
+// QueryOrganizations 查询配置文件中的所有组织
+func (s *GovernanceService) QueryOrganizations() []fabric.Organization {
+	return fabric.GetOrganizations()
+}
+
+// ProposeOrgCapabilities 以指定组织的身份提议变更组织权限
+func (s *GovernanceService) ProposeOrgCapabilities(orgName, proposalID, mspID string, capabilities []string) error {
+	contract, err := governanceContract(orgName)
+	if err != nil {
+		return err
+	}
+
+	capabilitiesJSON, err := json.Marshal(capabilities)
+	if err != nil {
+		return fmt.Errorf("序列化权限列表失败：%v", err)
+	}
+	_, err = contract.SubmitTransaction("ProposeOrgCapabilities", proposalID, mspID, string(capabilitiesJSON))
+	if err != nil {
+		return fmt.Errorf("提交治理提案失败：%s", fabric.ExtractErrorMessage(err))
+	}
+	return nil
+}
+
+// ProposeRoleEnforcement 以指定组织的身份提议启用或关闭角色检查
+func (s *GovernanceService) ProposeRoleEnforcement(orgName, proposalID string, enabled bool) error {
+	contract, err := governanceContract(orgName)
+	if err != nil {
+		return err
+	}
+
+	_, err = contract.SubmitTransaction("ProposeRoleEnforcement", proposalID, strconv.FormatBool(enabled))
+	if err != nil {
+		return fmt.Errorf("提交治理提案失败：%s", fabric.ExtractErrorMessage(err))
+	}
+	return nil
+}
+
+// ApproveGovernanceProposal 以指定组织的身份批准治理提案
+func (s *GovernanceService) ApproveGovernanceProposal(orgName, proposalID string) error {
+	contract, err := governanceContract(orgName)
+	if err != nil {
+		return err
+	}
+
+	_, err = contract.SubmitTransaction("ApproveGovernanceProposal", proposalID)
+	if err != nil {
+		return fmt.Errorf("批准治理提案失败：%s", fabric.ExtractErrorMessage(err))
+	}
+	return nil
+}
+
+// QueryGovernanceProposal 查询治理提案
+func (s *GovernanceService) QueryGovernanceProposal(orgName, proposalID string) (map[string]interface{}, error) {
+	contract, err := governanceContract(orgName)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := contract.EvaluateTransaction("QueryGovernanceProposal", proposalID)
+	if err != nil {
+		return nil, fmt.Errorf("查询治理提案失败：%s", fabric.ExtractErrorMessage(err))
+	}
+
+	var proposal map[string]interface{}
+	if err := json.Unmarshal(result, &proposal); err != nil {
+		return nil, fmt.Errorf("解析治理提案失败：%v", err)
+	}
+
+	return proposal, nil
+}
+
+// QueryGovernanceProposalList 分页查询治理提案列表
+func (s *GovernanceService) QueryGovernanceProposalList(orgName string, pageSize int32, bookmark string) (map[string]interface{}, error) {
+	contract, err := governanceContract(orgName)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := contract.EvaluateTransaction("QueryGovernanceProposalList", fmt.Sprintf("%d", pageSize), bookmark)
+	if err != nil {
+		return nil, fmt.Errorf("查询治理提案列表失败：%s", fabric.ExtractErrorMessage(err))
+	}
+
+	var queryResult map[string]interface{}
+	if err := json.Unmarshal(result, &queryResult); err != nil {
+		return nil, fmt.Errorf("解析查询结果失败：%v", err)
+	}
+
+	return queryResult, nil
+}
+
+// QueryOrgCapabilitiesList 查询所有参与治理的组织及其权限
+func (s *GovernanceService) QueryOrgCapabilitiesList(orgName string) ([]map[string]interface{}, error) {
+	contract, err := governanceContract(orgName)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := contract.EvaluateTransaction("QueryOrgCapabilitiesList")
+	if err != nil {
+		return nil, fmt.Errorf("查询组织权限失败：%s", fabric.ExtractErrorMessage(err))
+	}
+
+	var records []map[string]interface{}
+	if err := json.Unmarshal(result, &records); err != nil {
+		return nil, fmt.Errorf("解析组织权限失败：%v", err)
+	}
+
+	return records, nil
+}
+
+// 通用方法：获取配置文件中指定组织的合约客户端
+func governanceContract(orgName string) (*client.Contract, error) {
+	contract := fabric.GetContract(orgName)
+	if contract == nil {
+		return nil, fmt.Errorf("组织[%s]不在配置文件中", orgName)
+	}
+	return contract, nil
+}
@@ -0,0 +1,132 @@
+package service
+
+import (
+	"application/pkg/fabric"
+	"fmt"
+	"time"
+)
+
+type PublicService struct{}
+
+// _ProofChainLength 核验结果中凭据所在区块之后最多再返回的区块数
+const _ProofChainLength = 10
+
+// 核验哈希的匹配类型
+const (
+	MATCH_CERTIFICATE = "CERTIFICATE" // 与房产所有权证书哈希一致
+	MATCH_DOCUMENT    = "DOCUMENT"    // 与房产的存证文档哈希一致
+)
+
+// VerificationResult 公开核验结果（只包含房产状态和账本凭据，不包含所有者、地址等信息）
+type VerificationResult struct {
+	RealEstateID string    `json:"realEstateId"`        // 房产ID
+	Status       string    `json:"status"`              // 房产当前状态
+	UpdateTime   time.Time `json:"updateTime"`          // 房产最后更新时间
+	Hash         string    `json:"hash"`                // 待核验的哈希
+	Matched      bool      `json:"matched"`             // 是否与当前账本状态生成的证书或房产的存证文档一致
+	MatchType    string    `json:"matchType,omitempty"` // 匹配类型：CERTIFICATE-证书、DOCUMENT-存证文档
+	DocType      string    `json:"docType,omitempty"`   // 匹配的存证文档类型
+	Proof        *Proof    `json:"proof"`               // 账本凭据（匹配存证文档时为存证交易，否则为房产当前状态对应的交易）
+}
+
+// Proof 账本凭据
+type Proof struct {
+	TxID           string       `json:"txId"`           // Fabric 交易ID
+	ValidationCode string       `json:"validationCode"` // 交易验证结果
+	BlockNum       uint64       `json:"blockNum"`       // 交易所在的区块号
+	BlockHash      string       `json:"blockHash"`      // 交易所在的区块哈希
+	Chain          []*ChainLink `json:"chain"`          // 从交易所在区块开始的区块哈希链
+	ChainVerified  bool         `json:"chainVerified"`  // 哈希链中每个区块记录的前一区块哈希是否都与前一个区块的哈希一致
+}
+
+// ChainLink 区块哈希链中的一个区块
+type ChainLink struct {
+	BlockNum  uint64 `json:"blockNum"`
+	BlockHash string `json:"blockHash"`
+	PrevHash  string `json:"prevHash"`
+}
+
+// Verify 核验房产所有权证书哈希或房产的存证文档哈希（hash 为64位小写十六进制的 SHA-256 值）
+func (s *PublicService) Verify(realEstateID, hash string) (*VerificationResult, error) {
+	content, err := buildCertificateContent(REALTY_ORG, realEstateID)
+	if err != nil {
+		return nil, err
+	}
+
+	certificateHash, err := content.Hash()
+	if err != nil {
+		return nil, err
+	}
+
+	result := &VerificationResult{
+		RealEstateID: content.RealEstateID,
+		Status:       content.Status,
+		UpdateTime:   content.UpdateTime,
+		Hash:         hash,
+	}
+	proofTxID := content.TxID
+
+	if certificateHash == hash {
+		result.Matched = true
+		result.MatchType = MATCH_CERTIFICATE
+	} else {
+		documents, err := queryDocuments(REALTY_ORG, "REAL_ESTATE", realEstateID)
+		if err != nil {
+			return nil, err
+		}
+		for _, document := range documents {
+			if document["sha256"] != hash {
+				continue
+			}
+			result.Matched = true
+			result.MatchType = MATCH_DOCUMENT
+			result.DocType, _ = document["docType"].(string)
+			if txID, _ := document["txId"].(string); txID != "" {
+				proofTxID = txID
+			}
+			break
+		}
+	}
+
+	result.Proof, err = buildProof(REALTY_ORG, proofTxID)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// buildProof 根据本地区块存储生成交易的账本凭据
+func buildProof(orgName, txID string) (*Proof, error) {
+	location, err := fabric.GetBlockListener().GetTransactionLocation(txID)
+	if err != nil {
+		return nil, fmt.Errorf("查询交易 %s 所在区块失败：%v", txID, err)
+	}
+
+	blocks, err := fabric.GetBlockListener().GetBlockChain(orgName, location.BlockNum, _ProofChainLength+1)
+	if err != nil {
+		return nil, fmt.Errorf("查询区块哈希链失败：%v", err)
+	}
+	if len(blocks) == 0 {
+		return nil, fmt.Errorf("区块 %d 尚未同步", location.BlockNum)
+	}
+
+	proof := &Proof{
+		TxID:           txID,
+		ValidationCode: location.ValidationCode,
+		BlockNum:       blocks[0].BlockNum,
+		BlockHash:      blocks[0].BlockHash,
+		Chain:          make([]*ChainLink, 0, len(blocks)),
+		ChainVerified:  true,
+	}
+	for i, block := range blocks {
+		if i > 0 && block.PrevHash != blocks[i-1].BlockHash {
+			proof.ChainVerified = false
+		}
+		proof.Chain = append(proof.Chain, &ChainLink{
+			BlockNum:  block.BlockNum,
+			BlockHash: block.BlockHash,
+			PrevHash:  block.PrevHash,
+		})
+	}
+	return proof, nil
+}
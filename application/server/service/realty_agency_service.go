@@ -1,21 +1,47 @@
 package service
 
 import (
+	"application/config"
+	"application/pkg/certificate"
 	"application/pkg/fabric"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
-	"time"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/hyperledger/fabric-gateway/pkg/client"
 )
 
 type RealtyAgencyService struct{}
 
+// FULL_SHARE 房产的全部份额（万分比）
+const FULL_SHARE = 10000
+
+// Owner 房产所有者及其份额
+type Owner struct {
+	ID    string `json:"id"`    // 所有者
+	Share int    `json:"share"` // 份额（万分比）
+}
+
 const REALTY_ORG = "org1" // 不动产登记机构组织
 
+// DEFAULT_PROPERTY_TYPE 未指定房产类型时使用的类型（住宅）
+const DEFAULT_PROPERTY_TYPE = "RESIDENTIAL"
+
 // CreateRealEstate 创建房产信息
-func (s *RealtyAgencyService) CreateRealEstate(id, address string, area float64, owner string) error {
+func (s *RealtyAgencyService) CreateRealEstate(id, address, area, propertyType string, owners []Owner) error {
 	contract := fabric.GetContract(REALTY_ORG)
-	now := time.Now().Format(time.RFC3339)
-	_, err := contract.SubmitTransaction("CreateRealEstate", id, address, fmt.Sprintf("%f", area), owner, now)
+	ownersJSON, err := json.Marshal(owners)
+	if err != nil {
+		return fmt.Errorf("序列化所有者列表失败：%v", err)
+	}
+	_, err = contract.SubmitTransaction("CreateRealEstate", id, address, area, propertyType, string(ownersJSON))
 	if err != nil {
 		return fmt.Errorf("创建房产信息失败：%s", fabric.ExtractErrorMessage(err))
 	}
@@ -54,6 +80,269 @@ func (s *RealtyAgencyService) QueryRealEstateList(pageSize int32, bookmark strin
 	return queryResult, nil
 }
 
+// QueryRealEstateHistory 查询房产历史记录
+func (s *RealtyAgencyService) QueryRealEstateHistory(id string) ([]map[string]interface{}, error) {
+	contract := fabric.GetContract(REALTY_ORG)
+	result, err := contract.EvaluateTransaction("QueryRealEstateHistory", id)
+	if err != nil {
+		return nil, fmt.Errorf("查询房产历史记录失败：%s", fabric.ExtractErrorMessage(err))
+	}
+
+	var history []map[string]interface{}
+	if err := json.Unmarshal(result, &history); err != nil {
+		return nil, fmt.Errorf("解析历史记录失败：%v", err)
+	}
+
+	return history, nil
+}
+
+// UpdateRealEstate 更正房产地址、面积或类型（为空的字段保持不变，documentRef 为已存证到该房产上的证明文件哈希）
+func (s *RealtyAgencyService) UpdateRealEstate(id, address, area, propertyType, reason, documentRef string) error {
+	contract := fabric.GetContract(REALTY_ORG)
+	_, err := contract.SubmitTransaction("UpdateRealEstate", id, address, area, propertyType, reason, documentRef)
+	if err != nil {
+		return fmt.Errorf("更正房产信息失败：%s", fabric.ExtractErrorMessage(err))
+	}
+	return nil
+}
+
+// QueryRealEstateAmendments 查询房产信息的更正记录
+func (s *RealtyAgencyService) QueryRealEstateAmendments(id string) ([]map[string]interface{}, error) {
+	contract := fabric.GetContract(REALTY_ORG)
+	result, err := contract.EvaluateTransaction("QueryRealEstateAmendments", id)
+	if err != nil {
+		return nil, fmt.Errorf("查询更正记录失败：%s", fabric.ExtractErrorMessage(err))
+	}
+
+	var amendments []map[string]interface{}
+	if err := json.Unmarshal(result, &amendments); err != nil {
+		return nil, fmt.Errorf("解析更正记录失败：%v", err)
+	}
+
+	return amendments, nil
+}
+
+// RetireRealEstate 注销已拆除或被征收的房产（effectiveDate 为 YYYY-MM-DD 格式的注销生效日期）
+func (s *RealtyAgencyService) RetireRealEstate(id, reason, effectiveDate string) error {
+	contract := fabric.GetContract(REALTY_ORG)
+	_, err := contract.SubmitTransaction("RetireRealEstate", id, reason, effectiveDate)
+	if err != nil {
+		return fmt.Errorf("注销房产失败：%s", fabric.ExtractErrorMessage(err))
+	}
+	return nil
+}
+
+// QueryRealEstateByOwner 分页查询所有者持有的房产列表
+func (s *RealtyAgencyService) QueryRealEstateByOwner(owner string, pageSize int32, bookmark string) (map[string]interface{}, error) {
+	contract := fabric.GetContract(REALTY_ORG)
+	result, err := contract.EvaluateTransaction("QueryRealEstateByOwner", owner, fmt.Sprintf("%d", pageSize), bookmark)
+	if err != nil {
+		return nil, fmt.Errorf("查询所有者持有的房产列表失败：%s", fabric.ExtractErrorMessage(err))
+	}
+
+	var queryResult map[string]interface{}
+	if err := json.Unmarshal(result, &queryResult); err != nil {
+		return nil, fmt.Errorf("解析查询结果失败：%v", err)
+	}
+
+	return queryResult, nil
+}
+
+// partyIDHashKey 计算证件号码 HMAC-SHA256 的密钥
+var partyIDHashKey []byte
+
+// InitPartyIDHashKey 初始化证件号码哈希密钥（优先使用配置项 party.idHashKey，未配置时读取本地密钥文件，文件不存在时生成随机密钥）
+func InitPartyIDHashKey(keyFile string) error {
+	if key := config.GlobalConfig.Party.IDHashKey; key != "" {
+		partyIDHashKey = []byte(key)
+		return nil
+	}
+
+	key, err := os.ReadFile(keyFile)
+	if err == nil {
+		if len(key) == 0 {
+			return fmt.Errorf("证件号码哈希密钥文件 %s 为空", keyFile)
+		}
+		partyIDHashKey = key
+		return nil
+	}
+	if !os.IsNotExist(err) {
+		return fmt.Errorf("读取证件号码哈希密钥失败：%v", err)
+	}
+
+	random := make([]byte, 32)
+	if _, err := rand.Read(random); err != nil {
+		return fmt.Errorf("生成证件号码哈希密钥失败：%v", err)
+	}
+	key = []byte(hex.EncodeToString(random))
+	if err := os.MkdirAll(filepath.Dir(keyFile), 0755); err != nil {
+		return fmt.Errorf("创建密钥目录失败：%v", err)
+	}
+	if err := os.WriteFile(keyFile, key, 0600); err != nil {
+		return fmt.Errorf("保存证件号码哈希密钥失败：%v", err)
+	}
+	partyIDHashKey = key
+	return nil
+}
+
+// RegisterParty 登记交易方（证件号码在服务端用服务器持有的密钥计算 HMAC-SHA256 后上链，账本上不保存原文）
+func (s *RealtyAgencyService) RegisterParty(id, partyType, idDocumentNumber, displayName, contactRef string) error {
+	normalized := strings.ToUpper(strings.TrimSpace(idDocumentNumber))
+	if normalized == "" {
+		return fmt.Errorf("证件号码不能为空")
+	}
+	if len(partyIDHashKey) == 0 {
+		return fmt.Errorf("证件号码哈希密钥未初始化")
+	}
+	mac := hmac.New(sha256.New, partyIDHashKey)
+	mac.Write([]byte(normalized))
+
+	contract := fabric.GetContract(REALTY_ORG)
+	_, err := contract.SubmitTransaction("RegisterParty", id, partyType, hex.EncodeToString(mac.Sum(nil)), displayName, contactRef)
+	if err != nil {
+		return fmt.Errorf("登记交易方失败：%s", fabric.ExtractErrorMessage(err))
+	}
+	return nil
+}
+
+// UpdateParty 更新交易方的显示名称和联系方式引用
+func (s *RealtyAgencyService) UpdateParty(id, displayName, contactRef string) error {
+	contract := fabric.GetContract(REALTY_ORG)
+	_, err := contract.SubmitTransaction("UpdateParty", id, displayName, contactRef)
+	if err != nil {
+		return fmt.Errorf("更新交易方失败：%s", fabric.ExtractErrorMessage(err))
+	}
+	return nil
+}
+
+// RegisterPartyPublicKey 登记或更换交易方签署交易使用的公钥
+func (s *RealtyAgencyService) RegisterPartyPublicKey(id, publicKey string) error {
+	contract := fabric.GetContract(REALTY_ORG)
+	_, err := contract.SubmitTransaction("RegisterPartyPublicKey", id, publicKey)
+	if err != nil {
+		return fmt.Errorf("登记交易方公钥失败：%s", fabric.ExtractErrorMessage(err))
+	}
+	return nil
+}
+
+// DeleteParty 删除交易方（由集合成员背书，检查私有数据集合中该交易方作为买家的交易和报价）
+func (s *RealtyAgencyService) DeleteParty(id string) error {
+	contract := fabric.GetContract(REALTY_ORG)
+	_, err := contract.Submit("DeleteParty", client.WithArguments(id), withPrivateDataEndorsers())
+	if err != nil {
+		return fmt.Errorf("删除交易方失败：%s", fabric.ExtractErrorMessage(err))
+	}
+	return nil
+}
+
+// QueryParty 查询交易方信息
+func (s *RealtyAgencyService) QueryParty(id string) (map[string]interface{}, error) {
+	contract := fabric.GetContract(REALTY_ORG)
+	result, err := contract.EvaluateTransaction("QueryParty", id)
+	if err != nil {
+		return nil, fmt.Errorf("查询交易方信息失败：%s", fabric.ExtractErrorMessage(err))
+	}
+
+	var party map[string]interface{}
+	if err := json.Unmarshal(result, &party); err != nil {
+		return nil, fmt.Errorf("解析交易方数据失败：%v", err)
+	}
+
+	return party, nil
+}
+
+// QueryPartyList 分页查询交易方列表
+func (s *RealtyAgencyService) QueryPartyList(pageSize int32, bookmark string) (map[string]interface{}, error) {
+	contract := fabric.GetContract(REALTY_ORG)
+	result, err := contract.EvaluateTransaction("QueryPartyList", fmt.Sprintf("%d", pageSize), bookmark)
+	if err != nil {
+		return nil, fmt.Errorf("查询交易方列表失败：%s", fabric.ExtractErrorMessage(err))
+	}
+
+	var queryResult map[string]interface{}
+	if err := json.Unmarshal(result, &queryResult); err != nil {
+		return nil, fmt.Errorf("解析查询结果失败：%v", err)
+	}
+
+	return queryResult, nil
+}
+
+// GenerateCertificate 生成房产所有权证书 PDF（内容取自账本和本地区块存储，二维码包含核验地址和证书哈希）
+func (s *RealtyAgencyService) GenerateCertificate(realEstateID string) ([]byte, error) {
+	content, err := buildCertificateContent(REALTY_ORG, realEstateID)
+	if err != nil {
+		return nil, err
+	}
+
+	pdf, err := certificate.Render(content, config.GlobalConfig.Certificate.FontPath, config.GlobalConfig.Certificate.VerifyURL)
+	if err != nil {
+		return nil, fmt.Errorf("生成房产所有权证书失败：%v", err)
+	}
+	return pdf, nil
+}
+
+// VerifyTransactionPrivateData 校验披露的买家、价格和盐值是否与链上私有数据哈希一致
+func (s *RealtyAgencyService) VerifyTransactionPrivateData(txID, buyer, price, currency, salt string) (bool, error) {
+	contract := fabric.GetContract(REALTY_ORG)
+	result, err := contract.EvaluateTransaction("VerifyTransactionPrivateData", txID, buyer, price, currency, salt)
+	if err != nil {
+		return false, fmt.Errorf("校验交易私有数据失败：%s", fabric.ExtractErrorMessage(err))
+	}
+
+	var matched bool
+	if err := json.Unmarshal(result, &matched); err != nil {
+		return false, fmt.Errorf("解析校验结果失败：%v", err)
+	}
+
+	return matched, nil
+}
+
+// FreezeRealEstate 司法冻结房产（expireTime 为 RFC3339 格式的冻结期限）
+func (s *RealtyAgencyService) FreezeRealEstate(realEstateID, caseNumber, authority, expireTime string) error {
+	contract := fabric.GetContract(REALTY_ORG)
+	_, err := contract.SubmitTransaction("FreezeRealEstate", realEstateID, caseNumber, authority, expireTime)
+	if err != nil {
+		return fmt.Errorf("冻结房产失败：%s", fabric.ExtractErrorMessage(err))
+	}
+	return nil
+}
+
+// UnfreezeRealEstate 解除房产的司法冻结
+func (s *RealtyAgencyService) UnfreezeRealEstate(realEstateID, reason string) error {
+	contract := fabric.GetContract(REALTY_ORG)
+	_, err := contract.SubmitTransaction("UnfreezeRealEstate", realEstateID, reason)
+	if err != nil {
+		return fmt.Errorf("解除冻结失败：%s", fabric.ExtractErrorMessage(err))
+	}
+	return nil
+}
+
+// ExpireFreeze 结束已到期的司法冻结
+func (s *RealtyAgencyService) ExpireFreeze(realEstateID string) error {
+	contract := fabric.GetContract(REALTY_ORG)
+	_, err := contract.SubmitTransaction("ExpireFreeze", realEstateID)
+	if err != nil {
+		return fmt.Errorf("冻结到期处理失败：%s", fabric.ExtractErrorMessage(err))
+	}
+	return nil
+}
+
+// QueryFreezeList 分页查询司法冻结记录列表
+func (s *RealtyAgencyService) QueryFreezeList(pageSize int32, bookmark string, status string) (map[string]interface{}, error) {
+	contract := fabric.GetContract(REALTY_ORG)
+	result, err := contract.EvaluateTransaction("QueryFreezeList", fmt.Sprintf("%d", pageSize), bookmark, status)
+	if err != nil {
+		return nil, fmt.Errorf("查询冻结记录列表失败：%s", fabric.ExtractErrorMessage(err))
+	}
+
+	var queryResult map[string]interface{}
+	if err := json.Unmarshal(result, &queryResult); err != nil {
+		return nil, fmt.Errorf("解析查询结果失败：%v", err)
+	}
+
+	return queryResult, nil
+}
+
 // QueryBlockList 分页查询区块列表
 func (s *RealtyAgencyService) QueryBlockList(pageSize int, pageNum int) (*fabric.BlockQueryResult, error) {
 	result, err := fabric.GetBlockListener().GetBlocksByOrg(REALTY_ORG, pageSize, pageNum)
@@ -62,3 +351,58 @@ func (s *RealtyAgencyService) QueryBlockList(pageSize int, pageNum int) (*fabric
 	}
 	return result, nil
 }
+
+// QueryEventList 分页查询链码事件列表
+func (s *RealtyAgencyService) QueryEventList(filter fabric.EventFilter, pageSize int, pageNum int) (*fabric.EventQueryResult, error) {
+	result, err := fabric.GetEventListener().GetEvents(filter, pageSize, pageNum)
+	if err != nil {
+		return nil, fmt.Errorf("查询链码事件列表失败：%v", err)
+	}
+	return result, nil
+}
+
+// SetFeeSchedule 更新交易税费标准（整体替换现有规则）
+func (s *RealtyAgencyService) SetFeeSchedule(rules []map[string]interface{}) error {
+	rulesJSON, err := json.Marshal(rules)
+	if err != nil {
+		return fmt.Errorf("序列化税费规则失败：%v", err)
+	}
+
+	contract := fabric.GetContract(REALTY_ORG)
+	_, err = contract.SubmitTransaction("SetFeeSchedule", string(rulesJSON))
+	if err != nil {
+		return fmt.Errorf("更新税费标准失败：%s", fabric.ExtractErrorMessage(err))
+	}
+	return nil
+}
+
+// QueryFeeSchedule 查询交易税费标准
+func (s *RealtyAgencyService) QueryFeeSchedule() (map[string]interface{}, error) {
+	contract := fabric.GetContract(REALTY_ORG)
+	result, err := contract.EvaluateTransaction("QueryFeeSchedule")
+	if err != nil {
+		return nil, fmt.Errorf("查询税费标准失败：%s", fabric.ExtractErrorMessage(err))
+	}
+
+	var schedule map[string]interface{}
+	if err := json.Unmarshal(result, &schedule); err != nil {
+		return nil, fmt.Errorf("解析税费标准失败：%v", err)
+	}
+
+	return schedule, nil
+}
+
+// UploadDocument 保存文件到本地文档存储并将文件哈希存证到房产、交易或交易方上，返回文件哈希
+func (s *RealtyAgencyService) UploadDocument(entityType, entityID, docType string, file io.Reader) (string, error) {
+	return uploadDocument(REALTY_ORG, entityType, entityID, docType, file)
+}
+
+// OpenDocument 确认文件哈希已存证到对象上后打开文件，返回文件及其大小
+func (s *RealtyAgencyService) OpenDocument(entityType, entityID, hash string) (*os.File, int64, error) {
+	return openDocument(REALTY_ORG, entityType, entityID, hash)
+}
+
+// QueryDocuments 查询对象的文档存证记录
+func (s *RealtyAgencyService) QueryDocuments(entityType, entityID string) ([]map[string]interface{}, error) {
+	return queryDocuments(REALTY_ORG, entityType, entityID)
+}
@@ -2,26 +2,356 @@ package service
 
 import (
 	"application/pkg/fabric"
+	"crypto/rand"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
-	"time"
+	"io"
+	"os"
+	"slices"
+
+	"github.com/hyperledger/fabric-gateway/pkg/client"
 )
 
 type TradingPlatformService struct{}
 
 const TRADE_ORG = "org3" // 交易平台组织
 
-// CreateTransaction 生成交易
-func (s *TradingPlatformService) CreateTransaction(txID, realEstateID, seller, buyer string, price float64) error {
+// DEFAULT_CURRENCY 未指定币种时使用的币种（ISO 4217 代码）
+const DEFAULT_CURRENCY = "CNY"
+
+// _TransactionPrivateTransientKey 交易私有数据在 transient map 中的键
+const _TransactionPrivateTransientKey = "transaction"
+
+// _OfferResponderTransientKey 答复报价的交易方在 transient map 中的键（卖家还价后答复方是买家，不出现在交易参数中）
+const _OfferResponderTransientKey = "responder"
+
+// _OfferResponseSignatureTransientKey 答复方对答复内容的签名在 transient map 中的键（链码用答复方登记的公钥验证）
+const _OfferResponseSignatureTransientKey = "responseSignature"
+
+// withPrivateDataEndorsers 读写交易私有数据的交易只由私有数据集合成员（银行组织和交易平台组织）背书
+func withPrivateDataEndorsers() client.ProposalOption {
+	return client.WithEndorsingOrganizations(fabric.GetMSPID(BANK_ORG), fabric.GetMSPID(TRADE_ORG))
+}
+
+// withRealEstateEndorsement 修改房产信息的交易按房产的键级背书策略选择背书组织：策略中的组织必须全部背书，
+// 再加上调用组织；不足通道多数背书策略要求的组织数时，优先补充不动产登记机构，再按配置顺序补充其余组织
+//
+// 房产的键级背书策略由创建房产的组织决定，可以通过 SetRealEstateEndorsementPolicy 修改，因此每次提交前从链上读取；
+// 登记机构的节点不是私有数据集合成员，使用该选项的交易不能携带 transient 数据，私有数据由集合成员背书的前一笔交易写入
+func withRealEstateEndorsement(orgName, txID string) (client.ProposalOption, error) {
+	contract := fabric.GetContract(orgName)
+	result, err := contract.EvaluateTransaction("QueryTransaction", txID)
+	if err != nil {
+		return nil, fmt.Errorf("查询交易信息失败：%s", fabric.ExtractErrorMessage(err))
+	}
+	var transaction struct {
+		RealEstateID string `json:"realEstateId"`
+	}
+	if err := json.Unmarshal(result, &transaction); err != nil {
+		return nil, fmt.Errorf("解析交易数据失败：%v", err)
+	}
+
+	result, err = contract.EvaluateTransaction("QueryRealEstateEndorsementPolicy", transaction.RealEstateID)
+	if err != nil {
+		return nil, fmt.Errorf("查询房产背书策略失败：%s", fabric.ExtractErrorMessage(err))
+	}
+	var policy struct {
+		Orgs []string `json:"orgs"`
+	}
+	if err := json.Unmarshal(result, &policy); err != nil {
+		return nil, fmt.Errorf("解析房产背书策略失败：%v", err)
+	}
+
+	mspIDs := make([]string, 0, len(policy.Orgs)+2)
+	addMSPID := func(mspID string) {
+		if !slices.Contains(mspIDs, mspID) {
+			mspIDs = append(mspIDs, mspID)
+		}
+	}
+	for _, mspID := range policy.Orgs {
+		addMSPID(mspID)
+	}
+	addMSPID(fabric.GetMSPID(orgName))
+
+	organizations := fabric.GetOrganizations()
+	candidates := []string{fabric.GetMSPID(REALTY_ORG)}
+	for _, organization := range organizations {
+		candidates = append(candidates, organization.MSPID)
+	}
+	for _, mspID := range candidates {
+		if len(mspIDs) > len(organizations)/2 {
+			break
+		}
+		addMSPID(mspID)
+	}
+
+	return client.WithEndorsingOrganizations(mspIDs...), nil
+}
+
+// CreateTransaction 生成交易（分两笔交易提交：买家和价格通过 transient map 由集合成员背书写入交易草稿，再经登记机构背书将房产转为交易中）
+func (s *TradingPlatformService) CreateTransaction(txID, realEstateID, seller, buyer string, share int, price, currency string) error {
+	privateData, err := newTransactionPrivateData(buyer, price, currency)
+	if err != nil {
+		return err
+	}
+
 	contract := fabric.GetContract(TRADE_ORG)
-	now := time.Now().Format(time.RFC3339)
-	_, err := contract.SubmitTransaction("CreateTransaction", txID, realEstateID, seller, buyer, fmt.Sprintf("%f", price), now)
+	_, err = contract.Submit("CreateTransaction",
+		client.WithArguments(txID, realEstateID, seller, fmt.Sprintf("%d", share)),
+		client.WithTransient(map[string][]byte{_TransactionPrivateTransientKey: privateData}),
+		withPrivateDataEndorsers(),
+	)
 	if err != nil {
 		return fmt.Errorf("生成交易失败：%s", fabric.ExtractErrorMessage(err))
 	}
+	return s.OpenTransaction(txID)
+}
+
+// OpenTransaction 确认交易草稿，房产转为交易中（需要房产键级背书策略中的组织背书，不携带 transient 数据；生成交易后确认失败时可以重试）
+func (s *TradingPlatformService) OpenTransaction(txID string) error {
+	contract := fabric.GetContract(TRADE_ORG)
+	endorsement, err := withRealEstateEndorsement(TRADE_ORG, txID)
+	if err != nil {
+		return fmt.Errorf("交易草稿 %s 已生成，登记机构确认失败：%v", txID, err)
+	}
+	_, err = contract.Submit("OpenTransaction", client.WithArguments(txID), endorsement)
+	if err != nil {
+		return fmt.Errorf("交易草稿 %s 已生成，登记机构确认失败：%s", txID, fabric.ExtractErrorMessage(err))
+	}
+	return nil
+}
+
+// newTransactionPrivateData 生成通过 transient map 传入的私有数据（附带随机盐值）
+func newTransactionPrivateData(buyer, price, currency string) ([]byte, error) {
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, fmt.Errorf("生成盐值失败：%v", err)
+	}
+
+	privateData, err := json.Marshal(map[string]string{
+		"buyer":    buyer,
+		"price":    price,
+		"currency": currency,
+		"salt":     hex.EncodeToString(salt),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("序列化交易私有数据失败：%v", err)
+	}
+	return privateData, nil
+}
+
+// QueryTransactionPrivateDetails 查询交易私有数据（买家、价格和盐值）
+func (s *TradingPlatformService) QueryTransactionPrivateDetails(txID string) (map[string]interface{}, error) {
+	return queryTransactionPrivateDetails(TRADE_ORG, txID)
+}
+
+// queryTransactionPrivateDetails 通过指定组织（私有数据集合成员）查询交易私有数据
+func queryTransactionPrivateDetails(orgName, txID string) (map[string]interface{}, error) {
+	contract := fabric.GetContract(orgName)
+	result, err := contract.EvaluateTransaction("QueryTransactionPrivateDetails", txID)
+	if err != nil {
+		return nil, fmt.Errorf("查询交易私有数据失败：%s", fabric.ExtractErrorMessage(err))
+	}
+
+	var details map[string]interface{}
+	if err := json.Unmarshal(result, &details); err != nil {
+		return nil, fmt.Errorf("解析交易私有数据失败：%v", err)
+	}
+
+	return details, nil
+}
+
+// ApproveTransaction 提交交易方对交易条款的签署（签名由交易方用自己的私钥生成）
+func (s *TradingPlatformService) ApproveTransaction(txID, partyID, signature string) error {
+	contract := fabric.GetContract(TRADE_ORG)
+	_, err := contract.Submit("ApproveTransaction",
+		client.WithArguments(txID, partyID, signature),
+		withPrivateDataEndorsers(),
+	)
+	if err != nil {
+		return fmt.Errorf("签署交易失败：%s", fabric.ExtractErrorMessage(err))
+	}
+	return nil
+}
+
+// QueryTransactionSigningPayload 查询交易待签署的条款及买卖双方的签署状态
+func (s *TradingPlatformService) QueryTransactionSigningPayload(txID string) (map[string]interface{}, error) {
+	return queryTransactionSigningPayload(TRADE_ORG, txID)
+}
+
+// queryTransactionSigningPayload 通过指定组织（私有数据集合成员）查询交易待签署的条款
+func queryTransactionSigningPayload(orgName, txID string) (map[string]interface{}, error) {
+	contract := fabric.GetContract(orgName)
+	result, err := contract.EvaluateTransaction("QueryTransactionSigningPayload", txID)
+	if err != nil {
+		return nil, fmt.Errorf("查询交易签署内容失败：%s", fabric.ExtractErrorMessage(err))
+	}
+
+	var payload map[string]interface{}
+	if err := json.Unmarshal(result, &payload); err != nil {
+		return nil, fmt.Errorf("解析交易签署内容失败：%v", err)
+	}
+
+	return payload, nil
+}
+
+// CancelTransaction 取消交易
+func (s *TradingPlatformService) CancelTransaction(txID, reason string) error {
+	contract := fabric.GetContract(TRADE_ORG)
+	endorsement, err := withRealEstateEndorsement(TRADE_ORG, txID)
+	if err != nil {
+		return err
+	}
+	_, err = contract.Submit("CancelTransaction", client.WithArguments(txID, reason), endorsement)
+	if err != nil {
+		return fmt.Errorf("取消交易失败：%s", fabric.ExtractErrorMessage(err))
+	}
+	return nil
+}
+
+// SubmitOffer 买家对房产出价（买家和价格通过 transient map 传入私有数据集合，不出现在交易参数中）
+func (s *TradingPlatformService) SubmitOffer(offerID, realEstateID, seller, buyer string, share int, price, currency string) error {
+	privateData, err := newTransactionPrivateData(buyer, price, currency)
+	if err != nil {
+		return err
+	}
+
+	contract := fabric.GetContract(TRADE_ORG)
+	_, err = contract.Submit("SubmitOffer",
+		client.WithArguments(offerID, realEstateID, seller, fmt.Sprintf("%d", share)),
+		client.WithTransient(map[string][]byte{_TransactionPrivateTransientKey: privateData}),
+		withPrivateDataEndorsers(),
+	)
+	if err != nil {
+		return fmt.Errorf("出价失败：%s", fabric.ExtractErrorMessage(err))
+	}
+	return nil
+}
+
+// CounterOffer 对报价还价（买家沿用原报价的买家，新的价格、还价方及其签名通过 transient map 传入，还价方必须是原报价的对方）
+func (s *TradingPlatformService) CounterOffer(offerID, counterOfferID, responder, signature, price, currency string) error {
+	privateData, err := newTransactionPrivateData("", price, currency)
+	if err != nil {
+		return err
+	}
+
+	contract := fabric.GetContract(TRADE_ORG)
+	_, err = contract.Submit("CounterOffer",
+		client.WithArguments(offerID, counterOfferID),
+		client.WithTransient(map[string][]byte{
+			_TransactionPrivateTransientKey:     privateData,
+			_OfferResponderTransientKey:         []byte(responder),
+			_OfferResponseSignatureTransientKey: []byte(signature),
+		}),
+		withPrivateDataEndorsers(),
+	)
+	if err != nil {
+		return fmt.Errorf("还价失败：%s", fabric.ExtractErrorMessage(err))
+	}
+	return nil
+}
+
+// AcceptOffer 接受报价并生成交易（接受方必须是报价的对方；与生成交易一样先由集合成员背书写入交易草稿，再经登记机构背书确认）
+func (s *TradingPlatformService) AcceptOffer(offerID, txID, responder, signature string) error {
+	contract := fabric.GetContract(TRADE_ORG)
+	_, err := contract.Submit("AcceptOffer",
+		client.WithArguments(offerID, txID),
+		client.WithTransient(offerResponseTransient(responder, signature)),
+		withPrivateDataEndorsers(),
+	)
+	if err != nil {
+		return fmt.Errorf("接受报价失败：%s", fabric.ExtractErrorMessage(err))
+	}
+	return s.OpenTransaction(txID)
+}
+
+// RejectOffer 拒绝报价（拒绝方及其签名通过 transient map 传入，拒绝方必须是报价的对方；需要读取报价私有数据，由私有数据集合成员背书）
+func (s *TradingPlatformService) RejectOffer(offerID, responder, signature, reason string) error {
+	contract := fabric.GetContract(TRADE_ORG)
+	_, err := contract.Submit("RejectOffer",
+		client.WithArguments(offerID, reason),
+		client.WithTransient(offerResponseTransient(responder, signature)),
+		withPrivateDataEndorsers(),
+	)
+	if err != nil {
+		return fmt.Errorf("拒绝报价失败：%s", fabric.ExtractErrorMessage(err))
+	}
 	return nil
 }
 
+// offerResponseTransient 答复报价时通过 transient map 传入的答复方及其签名
+func offerResponseTransient(responder, signature string) map[string][]byte {
+	return map[string][]byte{
+		_OfferResponderTransientKey:         []byte(responder),
+		_OfferResponseSignatureTransientKey: []byte(signature),
+	}
+}
+
+// QueryOfferResponsePayload 查询答复报价时答复方需要签署的内容（action 为 COUNTER、ACCEPT 或 REJECT）
+func (s *TradingPlatformService) QueryOfferResponsePayload(offerID, responder, action, targetID, price, currency, reason string) (map[string]interface{}, error) {
+	contract := fabric.GetContract(TRADE_ORG)
+	result, err := contract.EvaluateTransaction("QueryOfferResponsePayload", offerID, responder, action, targetID, price, currency, reason)
+	if err != nil {
+		return nil, fmt.Errorf("查询报价答复签署内容失败：%s", fabric.ExtractErrorMessage(err))
+	}
+
+	var payload map[string]interface{}
+	if err := json.Unmarshal(result, &payload); err != nil {
+		return nil, fmt.Errorf("解析报价答复签署内容失败：%v", err)
+	}
+
+	return payload, nil
+}
+
+// QueryOffer 查询报价信息
+func (s *TradingPlatformService) QueryOffer(offerID string) (map[string]interface{}, error) {
+	contract := fabric.GetContract(TRADE_ORG)
+	result, err := contract.EvaluateTransaction("QueryOffer", offerID)
+	if err != nil {
+		return nil, fmt.Errorf("查询报价信息失败：%s", fabric.ExtractErrorMessage(err))
+	}
+
+	var offer map[string]interface{}
+	if err := json.Unmarshal(result, &offer); err != nil {
+		return nil, fmt.Errorf("解析报价数据失败：%v", err)
+	}
+
+	return offer, nil
+}
+
+// QueryOfferPrivateDetails 查询报价私有数据（买家、价格和盐值）
+func (s *TradingPlatformService) QueryOfferPrivateDetails(offerID string) (map[string]interface{}, error) {
+	contract := fabric.GetContract(TRADE_ORG)
+	result, err := contract.EvaluateTransaction("QueryOfferPrivateDetails", offerID)
+	if err != nil {
+		return nil, fmt.Errorf("查询报价私有数据失败：%s", fabric.ExtractErrorMessage(err))
+	}
+
+	var details map[string]interface{}
+	if err := json.Unmarshal(result, &details); err != nil {
+		return nil, fmt.Errorf("解析报价私有数据失败：%v", err)
+	}
+
+	return details, nil
+}
+
+// QueryOffersByRealEstate 分页查询房产的报价列表
+func (s *TradingPlatformService) QueryOffersByRealEstate(realEstateID string, pageSize int32, bookmark string) (map[string]interface{}, error) {
+	contract := fabric.GetContract(TRADE_ORG)
+	result, err := contract.EvaluateTransaction("QueryOffersByRealEstate", realEstateID, fmt.Sprintf("%d", pageSize), bookmark)
+	if err != nil {
+		return nil, fmt.Errorf("查询房产的报价列表失败：%s", fabric.ExtractErrorMessage(err))
+	}
+
+	var queryResult map[string]interface{}
+	if err := json.Unmarshal(result, &queryResult); err != nil {
+		return nil, fmt.Errorf("解析查询结果失败：%v", err)
+	}
+
+	return queryResult, nil
+}
+
 // QueryRealEstate 查询房产信息
 func (s *TradingPlatformService) QueryRealEstate(id string) (map[string]interface{}, error) {
 	contract := fabric.GetContract(TRADE_ORG)
@@ -70,6 +400,70 @@ func (s *TradingPlatformService) QueryTransactionList(pageSize int32, bookmark s
 	return queryResult, nil
 }
 
+// QueryRealEstateHistory 查询房产历史记录
+func (s *TradingPlatformService) QueryRealEstateHistory(id string) ([]map[string]interface{}, error) {
+	contract := fabric.GetContract(TRADE_ORG)
+	result, err := contract.EvaluateTransaction("QueryRealEstateHistory", id)
+	if err != nil {
+		return nil, fmt.Errorf("查询房产历史记录失败：%s", fabric.ExtractErrorMessage(err))
+	}
+
+	var history []map[string]interface{}
+	if err := json.Unmarshal(result, &history); err != nil {
+		return nil, fmt.Errorf("解析历史记录失败：%v", err)
+	}
+
+	return history, nil
+}
+
+// QueryTransactionHistory 查询交易历史记录
+func (s *TradingPlatformService) QueryTransactionHistory(txID string) ([]map[string]interface{}, error) {
+	contract := fabric.GetContract(TRADE_ORG)
+	result, err := contract.EvaluateTransaction("QueryTransactionHistory", txID)
+	if err != nil {
+		return nil, fmt.Errorf("查询交易历史记录失败：%s", fabric.ExtractErrorMessage(err))
+	}
+
+	var history []map[string]interface{}
+	if err := json.Unmarshal(result, &history); err != nil {
+		return nil, fmt.Errorf("解析历史记录失败：%v", err)
+	}
+
+	return history, nil
+}
+
+// QueryRealEstateByOwner 分页查询所有者持有的房产列表
+func (s *TradingPlatformService) QueryRealEstateByOwner(owner string, pageSize int32, bookmark string) (map[string]interface{}, error) {
+	contract := fabric.GetContract(TRADE_ORG)
+	result, err := contract.EvaluateTransaction("QueryRealEstateByOwner", owner, fmt.Sprintf("%d", pageSize), bookmark)
+	if err != nil {
+		return nil, fmt.Errorf("查询所有者持有的房产列表失败：%s", fabric.ExtractErrorMessage(err))
+	}
+
+	var queryResult map[string]interface{}
+	if err := json.Unmarshal(result, &queryResult); err != nil {
+		return nil, fmt.Errorf("解析查询结果失败：%v", err)
+	}
+
+	return queryResult, nil
+}
+
+// QueryTransactionsByParty 分页查询交易方参与的交易列表
+func (s *TradingPlatformService) QueryTransactionsByParty(party string, pageSize int32, bookmark string) (map[string]interface{}, error) {
+	contract := fabric.GetContract(TRADE_ORG)
+	result, err := contract.EvaluateTransaction("QueryTransactionsByParty", party, fmt.Sprintf("%d", pageSize), bookmark)
+	if err != nil {
+		return nil, fmt.Errorf("查询交易方参与的交易列表失败：%s", fabric.ExtractErrorMessage(err))
+	}
+
+	var queryResult map[string]interface{}
+	if err := json.Unmarshal(result, &queryResult); err != nil {
+		return nil, fmt.Errorf("解析查询结果失败：%v", err)
+	}
+
+	return queryResult, nil
+}
+
 // QueryBlockList 分页查询区块列表
 func (s *TradingPlatformService) QueryBlockList(pageSize int, pageNum int) (*fabric.BlockQueryResult, error) {
 	result, err := fabric.GetBlockListener().GetBlocksByOrg(TRADE_ORG, pageSize, pageNum)
@@ -78,3 +472,27 @@ func (s *TradingPlatformService) QueryBlockList(pageSize int, pageNum int) (*fab
 	}
 	return result, nil
 }
+
+// QueryEventList 分页查询链码事件列表
+func (s *TradingPlatformService) QueryEventList(filter fabric.EventFilter, pageSize int, pageNum int) (*fabric.EventQueryResult, error) {
+	result, err := fabric.GetEventListener().GetEvents(filter, pageSize, pageNum)
+	if err != nil {
+		return nil, fmt.Errorf("查询链码事件列表失败：%v", err)
+	}
+	return result, nil
+}
+
+// UploadDocument 保存文件到本地文档存储并将文件哈希存证到房产、交易或交易方上，返回文件哈希
+func (s *TradingPlatformService) UploadDocument(entityType, entityID, docType string, file io.Reader) (string, error) {
+	return uploadDocument(TRADE_ORG, entityType, entityID, docType, file)
+}
+
+// OpenDocument 确认文件哈希已存证到对象上后打开文件，返回文件及其大小
+func (s *TradingPlatformService) OpenDocument(entityType, entityID, hash string) (*os.File, int64, error) {
+	return openDocument(TRADE_ORG, entityType, entityID, hash)
+}
+
+// QueryDocuments 查询对象的文档存证记录
+func (s *TradingPlatformService) QueryDocuments(entityType, entityID string) ([]map[string]interface{}, error) {
+	return queryDocuments(TRADE_ORG, entityType, entityID)
+}
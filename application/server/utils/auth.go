@@ -0,0 +1,32 @@
+package utils
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequireOrgToken 校验请求头 header 中的令牌与路径参数 :org 对应组织的令牌一致的中间件
+//
+// tokenOf 返回组织配置的令牌，组织未配置令牌时拒绝所有请求
+func RequireOrgToken(header string, tokenOf func(orgName string) string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		orgName := c.Param("org")
+		expected := tokenOf(orgName)
+		if expected == "" {
+			Fail(c, http.StatusForbidden, fmt.Sprintf("组织[%s]未配置访问令牌，拒绝请求", orgName))
+			c.Abort()
+			return
+		}
+
+		if subtle.ConstantTimeCompare([]byte(c.GetHeader(header)), []byte(expected)) != 1 {
+			Fail(c, http.StatusUnauthorized, fmt.Sprintf("请求头 %s 中的令牌无效", header))
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,60 @@
+package utils
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RateLimit 按客户端 IP 限制请求频率的中间件（每个 IP 在每个时间窗口内最多 limit 次请求）
+func RateLimit(limit int, window time.Duration) gin.HandlerFunc {
+	type counter struct {
+		count int
+		reset time.Time
+	}
+
+	var (
+		mu        sync.Mutex
+		counters  = make(map[string]*counter)
+		lastSweep = time.Now()
+	)
+
+	return func(c *gin.Context) {
+		now := time.Now()
+		ip := c.ClientIP()
+
+		mu.Lock()
+		// 每个时间窗口清理一次过期的计数，防止内存持续增长
+		if now.Sub(lastSweep) > window {
+			for key, value := range counters {
+				if now.After(value.reset) {
+					delete(counters, key)
+				}
+			}
+			lastSweep = now
+		}
+
+		current, ok := counters[ip]
+		if !ok || now.After(current.reset) {
+			current = &counter{reset: now.Add(window)}
+			counters[ip] = current
+		}
+		current.count++
+		exceeded := current.count > limit
+		retryAfter := current.reset.Sub(now)
+		mu.Unlock()
+
+		if exceeded {
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+			Fail(c, http.StatusTooManyRequests, "请求过于频繁，请稍后重试")
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -1,5 +1,15 @@
 import request from '../utils/request';
-import type { RealEstatePageResult, TransactionPageResult, RealEstate, Transaction, BlockQueryResult } from '../types';
+import type { RealEstatePageResult, TransactionPageResult, RealEstate, Transaction, BlockQueryResult, Party, PageResult, Freeze, Offer, TransactionPaymentSummary, TransactionSigningPayload, OfferAction, OfferResponsePayload, PropertyType, Fee, FeeRule, FeeSchedule, DocumentEntityType, DocumentType, DocumentRecord, VerificationResult, Amendment } from '../types';
+
+// 上传文档的表单（multipart/form-data）
+const toDocumentForm = (data: { entityType: DocumentEntityType; entityId: string; docType: DocumentType; file: File }) => {
+  const form = new FormData();
+  form.append('entityType', data.entityType);
+  form.append('entityId', data.entityId);
+  form.append('docType', data.docType);
+  form.append('file', data.file);
+  return form;
+};
 
 // 不动产登记机构接口
 export const realtyAgencyApi = {
@@ -8,16 +18,94 @@ export const realtyAgencyApi = {
     id: string;
     address: string;
     area: number;
+    propertyType?: PropertyType;
     owner: string;
   }) => request.post<never, void>('/realty-agency/realty/create', data),
 
   // 查询房产信息
   getRealEstate: (id: string) => request.get<never, RealEstate>(`/realty-agency/realty/${id}`),
 
+  // 更正房产信息（未填写的字段保持不变，documentRef 为已存证到该房产上的证明文件哈希）
+  updateRealEstate: (id: string, data: {
+    address?: string;
+    area?: number | string;
+    propertyType?: PropertyType;
+    reason: string;
+    documentRef: string;
+  }) => request.put<never, void>(`/realty-agency/realty/${id}`, data),
+
+  // 查询房产信息的更正记录
+  getAmendments: (id: string) => request.get<never, Amendment[]>(`/realty-agency/realty/${id}/amendments`),
+
+  // 注销已拆除或被征收的房产（effectiveDate 为 YYYY-MM-DD 格式的注销生效日期）
+  retireRealEstate: (id: string, data: { reason: string; effectiveDate: string }) =>
+    request.post<never, void>(`/realty-agency/realty/${id}/retire`, data),
+
+  // 房产所有权证书下载地址（PDF）
+  getCertificateUrl: (id: string) => `/api/realty-agency/realty/${id}/certificate`,
+
   // 分页查询房产列表
   getRealEstateList: (params: { pageSize: number; bookmark: string; status?: string }) =>
     request.get<never, RealEstatePageResult>('/realty-agency/realty/list', { params }),
 
+  // 司法冻结房产
+  freezeRealEstate: (id: string, data: { caseNumber: string; authority: string; expireTime: string }) =>
+    request.post<never, void>(`/realty-agency/realty/${id}/freeze`, data),
+
+  // 解除房产的司法冻结
+  unfreezeRealEstate: (id: string, data: { reason: string }) =>
+    request.post<never, void>(`/realty-agency/realty/${id}/unfreeze`, data),
+
+  // 分页查询司法冻结记录列表
+  getFreezeList: (params: { pageSize: number; bookmark: string; status?: string }) =>
+    request.get<never, PageResult<Freeze>>('/realty-agency/freeze/list', { params }),
+
+  // 登记交易方
+  registerParty: (data: {
+    id: string;
+    type: 'PERSON' | 'LEGAL_ENTITY';
+    idDocumentNumber: string;
+    displayName: string;
+    contactRef: string;
+  }) => request.post<never, void>('/realty-agency/party/create', data),
+
+  // 更新交易方信息
+  updateParty: (id: string, data: { displayName: string; contactRef: string }) =>
+    request.put<never, void>(`/realty-agency/party/${id}`, data),
+
+  // 登记或更换交易方签署交易使用的公钥
+  registerPartyPublicKey: (id: string, publicKey: string) =>
+    request.post<never, void>(`/realty-agency/party/${id}/public-key`, { publicKey }),
+
+  // 删除交易方
+  deleteParty: (id: string) => request.delete<never, void>(`/realty-agency/party/${id}`),
+
+  // 查询交易方信息
+  getParty: (id: string) => request.get<never, Party>(`/realty-agency/party/${id}`),
+
+  // 分页查询交易方列表
+  getPartyList: (params: { pageSize: number; bookmark: string }) =>
+    request.get<never, PageResult<Party>>('/realty-agency/party/list', { params }),
+
+  // 更新交易税费标准（整体替换现有规则）
+  setFeeSchedule: (data: { rules: FeeRule[] }) =>
+    request.post<never, void>('/realty-agency/fee-schedule', data),
+
+  // 查询交易税费标准
+  getFeeSchedule: () => request.get<never, FeeSchedule>('/realty-agency/fee-schedule'),
+
+  // 上传文档并存证文件哈希
+  uploadDocument: (data: { entityType: DocumentEntityType; entityId: string; docType: DocumentType; file: File }) =>
+    request.post<never, { sha256: string }>('/realty-agency/document/upload', toDocumentForm(data)),
+
+  // 查询对象的文档存证记录
+  getDocuments: (entityType: DocumentEntityType, entityId: string) =>
+    request.get<never, DocumentRecord[]>(`/realty-agency/document/${entityType}/${entityId}`),
+
+  // 文档下载地址（服务端校验文件哈希已存证后才返回文件）
+  getDocumentUrl: (entityType: DocumentEntityType, entityId: string, sha256: string) =>
+    `/api/realty-agency/document/${entityType}/${entityId}/${sha256}`,
+
   // 分页查询区块列表
   getBlockList: (params: { pageSize?: number; pageNum?: number }) =>
     request.get<never, BlockQueryResult>('/realty-agency/block/list', { params }),
@@ -34,6 +122,55 @@ export const tradingPlatformApi = {
     price: number;
   }) => request.post<never, void>('/trading-platform/transaction/create', data),
 
+  // 重试确认交易草稿（登记机构确认失败时）
+  openTransaction: (txId: string) =>
+    request.post<never, void>(`/trading-platform/transaction/${txId}/open`),
+
+  // 查询交易待签署的条款及签署状态
+  getSigningPayload: (txId: string) =>
+    request.get<never, TransactionSigningPayload>(`/trading-platform/transaction/${txId}/signing-payload`),
+
+  // 提交交易方签署
+  approveTransaction: (txId: string, data: { partyId: string; signature: string }) =>
+    request.post<never, void>(`/trading-platform/transaction/${txId}/approve`, data),
+
+  // 买家出价
+  submitOffer: (data: {
+    offerId: string;
+    realEstateId: string;
+    seller: string;
+    buyer: string;
+    share?: number;
+    price: number | string;
+    currency?: string;
+  }) => request.post<never, void>('/trading-platform/offer/create', data),
+
+  // 查询答复报价时答复方需要签署的内容（targetId 为还价的新报价ID或接受后的交易ID）
+  getOfferResponsePayload: (offerId: string, params: {
+    responder: string;
+    action: OfferAction;
+    targetId?: string;
+    price?: number | string;
+    currency?: string;
+    reason?: string;
+  }) => request.get<never, OfferResponsePayload>(`/trading-platform/offer/${offerId}/response-payload`, { params }),
+
+  // 还价（responder 为还价方，必须是原报价的对方，signature 为其对答复内容的签名）
+  counterOffer: (offerId: string, data: { counterOfferId: string; responder: string; signature: string; price: number | string; currency?: string }) =>
+    request.post<never, void>(`/trading-platform/offer/${offerId}/counter`, data),
+
+  // 接受报价并生成交易（responder 为接受方，必须是报价的对方，signature 为其对答复内容的签名）
+  acceptOffer: (offerId: string, txId: string, responder: string, signature: string) =>
+    request.post<never, void>(`/trading-platform/offer/${offerId}/accept`, { txId, responder, signature }),
+
+  // 拒绝报价（responder 为拒绝方，必须是报价的对方，signature 为其对答复内容的签名）
+  rejectOffer: (offerId: string, responder: string, signature: string, reason: string) =>
+    request.post<never, void>(`/trading-platform/offer/${offerId}/reject`, { responder, signature, reason }),
+
+  // 分页查询房产的报价列表
+  getOffersByRealEstate: (realEstateId: string, params: { pageSize: number; bookmark: string }) =>
+    request.get<never, PageResult<Offer>>(`/trading-platform/offer/realty/${realEstateId}`, { params }),
+
   // 查询房产信息
   getRealEstate: (id: string) => request.get<never, RealEstate>(`/trading-platform/realty/${id}`),
 
@@ -44,6 +181,18 @@ export const tradingPlatformApi = {
   getTransactionList: (params: { pageSize: number; bookmark: string; status?: string }) =>
     request.get<never, TransactionPageResult>('/trading-platform/transaction/list', { params }),
 
+  // 上传文档并存证文件哈希
+  uploadDocument: (data: { entityType: DocumentEntityType; entityId: string; docType: DocumentType; file: File }) =>
+    request.post<never, { sha256: string }>('/trading-platform/document/upload', toDocumentForm(data)),
+
+  // 查询对象的文档存证记录
+  getDocuments: (entityType: DocumentEntityType, entityId: string) =>
+    request.get<never, DocumentRecord[]>(`/trading-platform/document/${entityType}/${entityId}`),
+
+  // 文档下载地址（服务端校验文件哈希已存证后才返回文件）
+  getDocumentUrl: (entityType: DocumentEntityType, entityId: string, sha256: string) =>
+    `/api/trading-platform/document/${entityType}/${entityId}/${sha256}`,
+
   // 分页查询区块列表
   getBlockList: (params: { pageSize?: number; pageNum?: number }) =>
     request.get<never, BlockQueryResult>('/trading-platform/block/list', { params }),
@@ -55,6 +204,18 @@ export const bankApi = {
   completeTransaction: (txId: string) =>
     request.post<never, void>(`/bank/transaction/complete/${txId}`),
 
+  // 登记付款（付清后交易自动完成）
+  recordPayment: (txId: string, data: { amount: string; reference: string }) =>
+    request.post<never, void>(`/bank/transaction/${txId}/payment`, data),
+
+  // 查询交易的托管付款记录和未付余额
+  getTransactionPayments: (txId: string) =>
+    request.get<never, TransactionPaymentSummary>(`/bank/transaction/${txId}/payments`),
+
+  // 查询交易完成时计算的税费明细
+  getTransactionFees: (txId: string) =>
+    request.get<never, Fee[]>(`/bank/transaction/${txId}/fees`),
+
   // 查询交易信息
   getTransaction: (txId: string) => request.get<never, Transaction>(`/bank/transaction/${txId}`),
 
@@ -62,7 +223,26 @@ export const bankApi = {
   getTransactionList: (params: { pageSize: number; bookmark: string; status?: string }) =>
     request.get<never, TransactionPageResult>('/bank/transaction/list', { params }),
 
+  // 上传文档并存证文件哈希
+  uploadDocument: (data: { entityType: DocumentEntityType; entityId: string; docType: DocumentType; file: File }) =>
+    request.post<never, { sha256: string }>('/bank/document/upload', toDocumentForm(data)),
+
+  // 查询对象的文档存证记录
+  getDocuments: (entityType: DocumentEntityType, entityId: string) =>
+    request.get<never, DocumentRecord[]>(`/bank/document/${entityType}/${entityId}`),
+
+  // 文档下载地址（服务端校验文件哈希已存证后才返回文件）
+  getDocumentUrl: (entityType: DocumentEntityType, entityId: string, sha256: string) =>
+    `/api/bank/document/${entityType}/${entityId}/${sha256}`,
+
   // 分页查询区块列表
   getBlockList: (params: { pageSize?: number; pageNum?: number }) =>
     request.get<never, BlockQueryResult>('/bank/block/list', { params }),
 };
+
+// 公开接口（不需要 Fabric 身份，按客户端 IP 限流）
+export const publicApi = {
+  // 核验房产所有权证书或存证文档的哈希
+  verify: (params: { realEstateId: string; hash: string }) =>
+    request.get<never, VerificationResult>('/public/verify', { params }),
+};
@@ -32,13 +32,67 @@ export interface PageResult<T> {
   recordsCount: number;
 }
 
+// 房产所有者及其份额（万分比）
+export interface Owner {
+  id: string;
+  share: number;
+}
+
+// 交易方信息
+export interface Party {
+  id: string;
+  type: 'PERSON' | 'LEGAL_ENTITY';
+  idDocumentHash: string;
+  displayName: string;
+  contactRef: string;
+  publicKey: string; // 签署交易使用的 ECDSA 公钥（PEM 格式）
+  createTime: string;
+  updateTime: string;
+}
+
+// 金额（以最小货币单位保存，如人民币为分）
+export interface Money {
+  amount: number;
+  currency: string;
+}
+
 // 房产信息
+// 房产类型
+export type PropertyType = 'RESIDENTIAL' | 'COMMERCIAL' | 'OFFICE' | 'INDUSTRIAL';
+
 export interface RealEstate {
   id: string;
   propertyAddress: string;
-  area: number;
-  currentOwner: string;
-  status: 'NORMAL' | 'IN_TRANSACTION';
+  area: string;
+  propertyType?: PropertyType; // 旧版本创建的房产为空，按住宅处理
+  owners: Owner[];
+  status: 'NORMAL' | 'IN_TRANSACTION' | 'FROZEN' | 'RETIRED';
+  createTime: string;
+  updateTime: string;
+  retirement?: Retirement; // 注销信息（仅已注销的房产）
+  freezes?: Freeze[]; // 司法冻结历史（仅查询单个房产时返回）
+}
+
+// 房产注销信息
+export interface Retirement {
+  reason: string; // 注销原因（如拆除、征收）
+  effectiveDate: string; // 注销生效日期（YYYY-MM-DD）
+  operator: string;
+  txId: string;
+  retireTime: string;
+}
+
+// 司法冻结记录
+export interface Freeze {
+  id: string;
+  realEstateId: string;
+  caseNumber: string; // 案号
+  authority: string; // 出具裁定的机关
+  operator: string;
+  previousStatus: 'NORMAL' | 'IN_TRANSACTION';
+  status: 'ACTIVE' | 'LIFTED' | 'EXPIRED';
+  liftReason: string;
+  expireTime: string;
   createTime: string;
   updateTime: string;
 }
@@ -48,11 +102,160 @@ export interface Transaction {
   id: string;
   realEstateId: string;
   seller: string;
-  buyer: string;
-  price: number;
-  status: 'PENDING' | 'COMPLETED' | 'CANCELLED';
+  buyer?: string; // 交易完成后公开
+  share: number;
+  privateDataHash: string; // 买家和价格保存在私有数据集合中，这里只有哈希
+  status: 'DRAFT' | 'PENDING' | 'PARTIALLY_PAID' | 'PAID' | 'SETTLED' | 'COMPLETED' | 'CANCELLED' | 'EXPIRED';
+  createTime: string;
+  updateTime: string;
+  fees?: Fee[]; // 完成时计算的税费明细（仅银行和交易平台查询时返回）
+}
+
+// 交易税费类型
+export type FeeType = 'DEED_TAX' | 'STAMP_DUTY' | 'PLATFORM_FEE';
+
+// 交易完成时计算的税费
+export interface Fee {
+  txId: string;
+  feeType: FeeType;
+  payer: 'SELLER' | 'BUYER';
+  payerId: string;
+  propertyType: PropertyType;
+  rate: number; // 税率（万分比）
+  amount: Money;
+  createTime: string;
+}
+
+// 税费标准中的一条规则（成交价格落在 [minPrice, maxPrice) 区间时按成交价格全额计算）
+export interface FeeRule {
+  feeType: FeeType;
+  propertyType?: PropertyType; // 为空表示适用于所有类型
+  payer: 'SELLER' | 'BUYER';
+  currency: string;
+  minPrice?: string;
+  maxPrice?: string; // 为空表示不设上限
+  rate: number; // 税率（万分比）
+  fixedAmount?: string;
+}
+
+// 交易税费标准
+export interface FeeSchedule {
+  rules: FeeRule[];
+  operator: string;
+  updateTime: string;
+}
+
+// 交易方对交易条款的签署
+export interface TransactionApproval {
+  txId: string;
+  partyId: string;
+  role: 'SELLER' | 'BUYER';
+  termsHash: string;
+  signature: string;
   createTime: string;
+}
+
+// 交易待签署内容及签署状态
+export interface TransactionSigningPayload {
+  txId: string;
+  terms: string; // 规范 JSON，交易方对其 SHA-256 摘要签名
+  termsHash: string;
+  sellerApproved: boolean;
+  buyerApproved: boolean;
+  approvals: TransactionApproval[];
+}
+
+// 答复报价的方式（还价、接受、拒绝）
+export type OfferAction = 'COUNTER' | 'ACCEPT' | 'REJECT';
+
+// 答复报价的待签署内容
+export interface OfferResponsePayload {
+  offerId: string;
+  terms: string; // 规范 JSON，答复方对其 SHA-256 摘要签名
+  termsHash: string;
+}
+
+// 托管付款记录
+export interface TransactionPayment {
+  id: string;
+  txId: string;
+  amount: Money;
+  reference: string; // 银行流水号
+  operator: string;
+  createTime: string;
+}
+
+// 交易托管付款汇总
+export interface TransactionPaymentSummary {
+  txId: string;
+  price: Money;
+  paid: Money;
+  balance: Money;
+  payments: TransactionPayment[];
+}
+
+// 报价信息（买家和报价金额保存在私有数据集合中）
+export interface Offer {
+  id: string;
+  realEstateId: string;
+  seller: string;
+  share: number;
+  proposedBy: 'BUYER' | 'SELLER';
+  parentId: string; // 被还价的报价ID
+  privateDataHash: string;
+  status: 'PENDING' | 'COUNTERED' | 'ACCEPTED' | 'REJECTED' | 'CLOSED';
+  rejectReason: string;
+  transactionId: string; // 接受报价后生成的交易ID
+  createTime: string;
+  updateTime: string;
+}
+
+// 房产信息更正记录
+export interface Amendment {
+  id: string;
+  realEstateId: string;
+  changes: { field: 'propertyAddress' | 'area' | 'propertyType'; oldValue: string; newValue: string }[];
+  reason: string;
+  documentRef: string; // 证明文件的 SHA-256 哈希（已存证到该房产上）
+  operator: string; // 办理更正的组织 MSP ID
+  createTime: string;
+}
+
+// 文档关联的对象类型
+export type DocumentEntityType = 'REAL_ESTATE' | 'TRANSACTION' | 'PARTY';
+
+// 文档类型
+export type DocumentType = 'TITLE_DEED' | 'CONTRACT' | 'ID_SCAN' | 'OTHER';
+
+// 文档存证记录（账本上只保存文件哈希，文件保存在服务端的本地文档存储中）
+export interface DocumentRecord {
+  entityType: DocumentEntityType;
+  entityId: string;
+  docType: DocumentType;
+  sha256: string;
+  uri: string;
+  operator: string; // 存证的组织 MSP ID
+  txId: string; // 存证的 Fabric 交易ID
+  createTime: string;
+}
+
+// 公开核验结果（不包含所有者等个人信息）
+export interface VerificationResult {
+  realEstateId: string;
+  status: RealEstate['status'];
   updateTime: string;
+  hash: string;
+  matched: boolean;
+  matchType?: 'CERTIFICATE' | 'DOCUMENT';
+  docType?: DocumentType;
+  proof: {
+    txId: string;
+    validationCode: string;
+    blockNum: number;
+    blockHash: string;
+    chain: { blockNum: number; blockHash: string; prevHash: string }[]; // 从交易所在区块开始的区块哈希链
+    chainVerified: boolean;
+  };
 }
 
 // 房产列表查询结果
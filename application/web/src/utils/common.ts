@@ -1,3 +1,5 @@
+import type { Money } from '../types';
+
 // 生成UUID
 export const generateUUID = () => {
   return 'xxxxxxxx-xxxx-4xxx-yxxx-xxxxxxxxxxxx'.replace(/[xy]/g, function(c) {
@@ -10,14 +12,32 @@ export const generateUUID = () => {
 // 格式化状态文本
 export const getStatusText = (status: string) => {
   switch (status) {
+    case 'DRAFT':
+      return '待确认';
     case 'PENDING':
       return '待完成';
+    case 'PARTIALLY_PAID':
+      return '部分付款';
+    case 'PAID':
+      return '已付清';
+    case 'SETTLED':
+      return '已结算';
     case 'COMPLETED':
       return '已完成';
     case 'NORMAL':
       return '正常';
     case 'IN_TRANSACTION':
       return '交易中';
+    case 'FROZEN':
+      return '司法冻结';
+    case 'RETIRED':
+      return '已注销';
+    case 'ACTIVE':
+      return '冻结中';
+    case 'LIFTED':
+      return '已解除';
+    case 'EXPIRED':
+      return '已到期';
     default:
       return '未知';
   }
@@ -28,17 +48,37 @@ export const getStatusColor = (status: string) => {
   switch (status) {
     case 'PENDING':
       return 'blue';
+    case 'PARTIALLY_PAID':
+      return 'orange';
+    case 'PAID':
+    case 'SETTLED':
+      return 'cyan';
     case 'COMPLETED':
     case 'NORMAL':
       return 'green';
     case 'IN_TRANSACTION':
       return 'blue';
+    case 'FROZEN':
+    case 'ACTIVE':
+      return 'red';
     default:
       return 'default';
   }
 };
 
+// 币种符号及最小货币单位的小数位数
+const currencies: Record<string, { symbol: string; scale: number }> = {
+  CNY: { symbol: '¥', scale: 2 },
+  HKD: { symbol: 'HK$', scale: 2 },
+  USD: { symbol: '$', scale: 2 },
+  EUR: { symbol: '€', scale: 2 },
+  GBP: { symbol: '£', scale: 2 },
+  JPY: { symbol: 'JP¥', scale: 0 },
+};
+
 // 格式化金额显示
-export const formatPrice = (price: number) => {
-  return `¥ ${price}`.replace(/\B(?=(\d{3})+(?!\d))/g, ',');
+export const formatPrice = (price: Money) => {
+  const { symbol, scale } = currencies[price.currency] ?? { symbol: price.currency, scale: 2 };
+  const value = (price.amount / 10 ** scale).toFixed(scale);
+  return `${symbol} ${value}`.replace(/\B(?=(\d{3})+(?!\d))/g, ',');
 }; 
\ No newline at end of file
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/v2/contractapi"
+)
+
+// AMENDMENT 房产信息更正记录文档类型（复合键：AM_房产ID_更正记录ID）
+const AMENDMENT = "AM"
+
+// AmendmentChange 更正的字段及其更正前后的值
+type AmendmentChange struct {
+	Field    string `json:"field"`    // 字段名（propertyAddress、area、propertyType）
+	OldValue string `json:"oldValue"` // 更正前的值
+	NewValue string `json:"newValue"` // 更正后的值
+}
+
+// Amendment 房产信息更正记录
+type Amendment struct {
+	ID           string            `json:"id"`           // 更正记录ID（办理更正的 Fabric 交易ID）
+	RealEstateID string            `json:"realEstateId"` // 房产ID
+	Changes      []AmendmentChange `json:"changes"`      // 更正的字段
+	Reason       string            `json:"reason"`       // 更正原因
+	DocumentRef  string            `json:"documentRef"`  // 证明文件的 SHA-256 哈希（必须已存证到该房产上）
+	Operator     string            `json:"operator"`     // 办理更正的组织 MSP ID
+	CreateTime   time.Time         `json:"createTime"`   // 更正时间
+}
+
+// UpdateRealEstate 更正房产地址、面积或类型（仅拥有登记权限的组织可以调用，为空的字段保持不变）
+//
+// 必须填写更正原因，并以已通过 AttachDocument 存证到该房产上的证明文件哈希作为依据；交易中或已注销的房产不能更正
+func (s *SmartContract) UpdateRealEstate(ctx contractapi.TransactionContextInterface, realEstateID string, address string, area string, propertyType string, reason string, documentRef string) error {
+	// 验证调用者所在组织是否拥有登记权限
+	err := s.checkCapability(ctx, "更正房产信息", CAP_REGISTRY)
+	if err != nil {
+		return err
+	}
+
+	clientMSPID, err := s.getClientIdentityMSPID(ctx)
+	if err != nil {
+		return fmt.Errorf("获取调用者身份失败：%v", err)
+	}
+
+	// 以账本交易时间作为更新时间
+	updateTime, err := s.getTxTime(ctx)
+	if err != nil {
+		return err
+	}
+
+	// 参数验证
+	if len(strings.TrimSpace(reason)) == 0 {
+		return fmt.Errorf("更正原因不能为空")
+	}
+	err = validateDocumentHash(documentRef)
+	if err != nil {
+		return fmt.Errorf("证明文件无效：%v", err)
+	}
+	if area != "" {
+		area, err = parseArea(area)
+		if err != nil {
+			return err
+		}
+	}
+	if propertyType != "" && !validPropertyTypes[PropertyType(propertyType)] {
+		return fmt.Errorf("房产类型 %s 无效", propertyType)
+	}
+
+	// 查询房产信息
+	realEstate, err := s.getRealEstate(ctx, realEstateID)
+	if err != nil {
+		return err
+	}
+
+	err = s.checkNotRetired(realEstate, "更正")
+	if err != nil {
+		return err
+	}
+
+	// 交易中的房产（包括交易中被冻结的房产）不能更正
+	inTransaction := realEstate.Status == IN_TRANSACTION
+	if realEstate.Status == FROZEN {
+		freeze, err := s.getActiveFreeze(ctx, realEstateID)
+		if err != nil {
+			return err
+		}
+		inTransaction = freeze.PreviousStatus == IN_TRANSACTION
+	}
+	if inTransaction {
+		return fmt.Errorf("房产 %s 正在交易中，不能更正", realEstateID)
+	}
+
+	// 证明文件必须已存证到该房产上
+	anchored, err := s.VerifyDocument(ctx, string(DOC_ENTITY_REAL_ESTATE), realEstateID, documentRef)
+	if err != nil {
+		return err
+	}
+	if !anchored {
+		return fmt.Errorf("证明文件 %s 未存证到房产 %s，请先存证证明文件", documentRef, realEstateID)
+	}
+
+	// 记录实际变化的字段
+	changes := make([]AmendmentChange, 0, 3)
+	if address != "" && address != realEstate.PropertyAddress {
+		changes = append(changes, AmendmentChange{Field: "propertyAddress", OldValue: realEstate.PropertyAddress, NewValue: address})
+		realEstate.PropertyAddress = address
+	}
+	if area != "" && area != realEstate.Area {
+		changes = append(changes, AmendmentChange{Field: "area", OldValue: realEstate.Area, NewValue: area})
+		realEstate.Area = area
+	}
+	if propertyType != "" && PropertyType(propertyType) != realEstate.PropertyType {
+		changes = append(changes, AmendmentChange{Field: "propertyType", OldValue: string(realEstate.PropertyType), NewValue: propertyType})
+		realEstate.PropertyType = PropertyType(propertyType)
+	}
+	if len(changes) == 0 {
+		return fmt.Errorf("没有需要更正的字段")
+	}
+
+	amendment := Amendment{
+		ID:           ctx.GetStub().GetTxID(),
+		RealEstateID: realEstateID,
+		Changes:      changes,
+		Reason:       strings.TrimSpace(reason),
+		DocumentRef:  documentRef,
+		Operator:     clientMSPID,
+		CreateTime:   updateTime,
+	}
+
+	key, err := s.getCompositeKey(ctx, AMENDMENT, []string{realEstateID, amendment.ID})
+	if err != nil {
+		return err
+	}
+	err = s.putState(ctx, key, amendment)
+	if err != nil {
+		return err
+	}
+
+	realEstate.UpdateTime = updateTime
+	return s.putRealEstate(ctx, realEstate, realEstate.Status)
+}
+
+// QueryRealEstateAmendments 查询房产信息的更正记录（按更正时间排序）
+func (s *SmartContract) QueryRealEstateAmendments(ctx contractapi.TransactionContextInterface, realEstateID string) ([]*Amendment, error) {
+	iterator, err := ctx.GetStub().GetStateByPartialCompositeKey(AMENDMENT, []string{realEstateID})
+	if err != nil {
+		return nil, fmt.Errorf("查询更正记录失败：%v", err)
+	}
+	defer iterator.Close()
+
+	amendments := make([]*Amendment, 0)
+	for iterator.HasNext() {
+		queryResponse, err := iterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("获取下一条记录失败：%v", err)
+		}
+
+		var amendment Amendment
+		err = json.Unmarshal(queryResponse.Value, &amendment)
+		if err != nil {
+			return nil, fmt.Errorf("解析更正记录失败：%v", err)
+		}
+		amendments = append(amendments, &amendment)
+	}
+
+	sort.Slice(amendments, func(i, j int) bool {
+		return amendments[i].CreateTime.Before(amendments[j].CreateTime)
+	})
+	return amendments, nil
+}
@@ -0,0 +1,298 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/hyperledger/fabric-chaincode-go/v2/pkg/cid"
+	"github.com/hyperledger/fabric-contract-api-go/v2/contractapi"
+)
+
+// AREA_SCALE 面积保留的小数位数（面积以定点小数字符串保存，如 "89.50"）
+const AREA_SCALE = 2
+
+// LEGACY_CURRENCY 旧版本以浮点数保存的金额所使用的币种
+const LEGACY_CURRENCY = "CNY"
+
+// currencyMinorUnits 支持的币种（ISO 4217 代码 -> 最小货币单位的小数位数）
+var currencyMinorUnits = map[string]int{
+	"CNY": 2,
+	"HKD": 2,
+	"USD": 2,
+	"EUR": 2,
+	"GBP": 2,
+	"JPY": 0,
+}
+
+// Money 金额（以最小货币单位的整数保存，避免浮点误差）
+type Money struct {
+	Amount   int64  `json:"amount"`   // 金额（最小货币单位，如人民币为分）
+	Currency string `json:"currency"` // 币种（ISO 4217 代码）
+}
+
+// String 格式化为十进制金额加币种代码（如 "12.30 CNY"）
+func (m Money) String() string {
+	return formatDecimal(m.Amount, currencyMinorUnits[m.Currency]) + " " + m.Currency
+}
+
+// 通用方法：将十进制字符串解析为按 scale 位小数放大后的整数（如 scale 为2时 "12.3" 解析为 1230）
+//
+// 超出 scale 的小数位只允许为0，不做舍入
+func parseDecimal(value string, scale int) (int64, error) {
+	value = strings.TrimSpace(value)
+	intPart, fracPart, hasPoint := strings.Cut(value, ".")
+	if len(intPart) == 0 || (hasPoint && len(fracPart) == 0) {
+		return 0, fmt.Errorf("%q 不是有效的十进制数", value)
+	}
+	for _, c := range intPart + fracPart {
+		if c < '0' || c > '9' {
+			return 0, fmt.Errorf("%q 不是有效的十进制数", value)
+		}
+	}
+
+	if len(fracPart) > scale {
+		if strings.Trim(fracPart[scale:], "0") != "" {
+			return 0, fmt.Errorf("%q 最多保留%d位小数", value, scale)
+		}
+		fracPart = fracPart[:scale]
+	}
+	fracPart += strings.Repeat("0", scale-len(fracPart))
+
+	result, err := strconv.ParseInt(intPart+fracPart, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%q 超出允许范围", value)
+	}
+	return result, nil
+}
+
+// 通用方法：将按 scale 位小数放大后的整数格式化为十进制字符串
+func formatDecimal(value int64, scale int) string {
+	if scale == 0 {
+		return strconv.FormatInt(value, 10)
+	}
+	digits := fmt.Sprintf("%0*d", scale+1, value)
+	return digits[:len(digits)-scale] + "." + digits[len(digits)-scale:]
+}
+
+// 通用方法：解析并校验金额（amount 为十进制字符串，currency 为 ISO 4217 币种代码）
+func parseMoney(amount string, currency string) (Money, error) {
+	scale, ok := currencyMinorUnits[currency]
+	if !ok {
+		return Money{}, fmt.Errorf("不支持的币种 %q", currency)
+	}
+
+	minorUnits, err := parseDecimal(amount, scale)
+	if err != nil {
+		return Money{}, fmt.Errorf("金额格式错误：%v", err)
+	}
+	if minorUnits <= 0 {
+		return Money{}, fmt.Errorf("金额必须大于0")
+	}
+
+	return Money{Amount: minorUnits, Currency: currency}, nil
+}
+
+// 通用方法：解析并校验面积，返回保留 AREA_SCALE 位小数的规范字符串
+func parseArea(area string) (string, error) {
+	value, err := parseDecimal(area, AREA_SCALE)
+	if err != nil {
+		return "", fmt.Errorf("面积格式错误：%v", err)
+	}
+	if value <= 0 {
+		return "", fmt.Errorf("面积必须大于0")
+	}
+	return formatDecimal(value, AREA_SCALE), nil
+}
+
+// 通用方法：解析面积字段，兼容旧版本以浮点数保存的面积
+func decodeArea(raw json.RawMessage) (string, error) {
+	if len(raw) == 0 || raw[0] == '"' {
+		var area string
+		if len(raw) > 0 {
+			if err := json.Unmarshal(raw, &area); err != nil {
+				return "", err
+			}
+		}
+		return area, nil
+	}
+
+	var legacy float64
+	if err := json.Unmarshal(raw, &legacy); err != nil {
+		return "", err
+	}
+	return strconv.FormatFloat(legacy, 'f', AREA_SCALE, 64), nil
+}
+
+// 通用方法：解析金额字段，兼容旧版本以浮点数（单位为元）保存的金额
+func decodeMoney(raw json.RawMessage) (Money, error) {
+	var money Money
+	if len(raw) == 0 || raw[0] == '{' {
+		if len(raw) > 0 {
+			if err := json.Unmarshal(raw, &money); err != nil {
+				return Money{}, err
+			}
+		}
+		return money, nil
+	}
+
+	var legacy float64
+	if err := json.Unmarshal(raw, &legacy); err != nil {
+		return Money{}, err
+	}
+	scale := currencyMinorUnits[LEGACY_CURRENCY]
+	return Money{
+		Amount:   int64(math.Round(legacy * math.Pow10(scale))),
+		Currency: LEGACY_CURRENCY,
+	}, nil
+}
+
+// UnmarshalJSON 解析房产信息，兼容旧版本以浮点数保存的面积和只有单一所有者（currentOwner）的房产
+func (r *RealEstate) UnmarshalJSON(data []byte) error {
+	type realEstateJSON RealEstate
+	aux := struct {
+		*realEstateJSON
+		Area         json.RawMessage `json:"area"`
+		CurrentOwner string          `json:"currentOwner"`
+	}{realEstateJSON: (*realEstateJSON)(r)}
+
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+
+	area, err := decodeArea(aux.Area)
+	if err != nil {
+		return fmt.Errorf("解析面积失败：%v", err)
+	}
+	r.Area = area
+
+	// 旧版本的当前所有者持有房产的全部份额
+	if len(r.Owners) == 0 && len(aux.CurrentOwner) > 0 {
+		r.Owners = []Owner{{ID: aux.CurrentOwner, Share: FULL_SHARE}}
+	}
+	return nil
+}
+
+// UnmarshalJSON 解析抵押信息，兼容旧版本以浮点数保存的担保金额
+func (m *Mortgage) UnmarshalJSON(data []byte) error {
+	type mortgageJSON Mortgage
+	aux := struct {
+		*mortgageJSON
+		Amount json.RawMessage `json:"amount"`
+	}{mortgageJSON: (*mortgageJSON)(m)}
+
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+
+	amount, err := decodeMoney(aux.Amount)
+	if err != nil {
+		return fmt.Errorf("解析担保金额失败：%v", err)
+	}
+	m.Amount = amount
+	return nil
+}
+
+// MigrateAmounts 将以浮点数保存的面积和担保金额改写为定点小数和最小货币单位（仅组织管理员可以调用）
+//
+// 交易价格已移入私有数据集合，旧版本交易公开状态中的价格不再读取，不做迁移
+//
+// 旧版本房产的单一所有者（currentOwner）同时改写为持有全部份额的所有者，并补充所有者索引
+//
+// 旧记录读取时会自动兼容，迁移只是把存储改写为新格式；每次最多改写 pageSize 条记录，重复调用直到 hasMore 为 false 即可完成迁移
+func (s *SmartContract) MigrateAmounts(ctx contractapi.TransactionContextInterface, pageSize int32) (*MigrationResult, error) {
+	// 检查调用者是否为组织管理员
+	isAdmin, err := cid.HasOUValue(ctx.GetStub(), "admin")
+	if err != nil {
+		return nil, fmt.Errorf("获取调用者身份失败：%v", err)
+	}
+	if !isAdmin {
+		return nil, fmt.Errorf("只有组织管理员才能迁移存储")
+	}
+
+	if pageSize <= 0 {
+		return nil, fmt.Errorf("每页记录数必须大于0")
+	}
+
+	result := &MigrationResult{}
+	targets := []struct {
+		objectType string
+		field      string
+		newRecord  func() interface{}
+	}{
+		{REAL_ESTATE, "area", func() interface{} { return &RealEstate{} }},
+		{MORTGAGE, "amount", func() interface{} { return &Mortgage{} }},
+	}
+
+	for _, target := range targets {
+		remaining := pageSize - result.Migrated
+		if remaining <= 0 {
+			result.HasMore = true
+			return result, nil
+		}
+
+		migrated, err := s.migrateLegacyAmounts(ctx, target.objectType, target.field, target.newRecord, remaining)
+		if err != nil {
+			return nil, err
+		}
+		result.Migrated += migrated
+	}
+
+	result.HasMore = result.Migrated >= pageSize
+	return result, nil
+}
+
+// 通用方法：改写指定类型下以浮点数保存金额或面积的记录，返回改写的记录数
+func (s *SmartContract) migrateLegacyAmounts(ctx contractapi.TransactionContextInterface, objectType string, field string, newRecord func() interface{}, pageSize int32) (int32, error) {
+	// 分页查询只支持只读交易，这里使用普通范围查询并在达到 pageSize 后停止
+	iterator, err := ctx.GetStub().GetStateByPartialCompositeKey(objectType, []string{})
+	if err != nil {
+		return 0, fmt.Errorf("查询记录失败：%v", err)
+	}
+	defer iterator.Close()
+
+	var migrated int32
+	for iterator.HasNext() && migrated < pageSize {
+		queryResponse, err := iterator.Next()
+		if err != nil {
+			return 0, fmt.Errorf("获取下一条记录失败：%v", err)
+		}
+
+		var fields map[string]json.RawMessage
+		err = json.Unmarshal(queryResponse.Value, &fields)
+		if err != nil {
+			return 0, fmt.Errorf("解析记录失败：%v", err)
+		}
+		// 新格式为字符串（面积）或对象（金额），且没有旧版本的所有者字段，不需要迁移
+		raw := fields[field]
+		_, hasLegacyOwner := fields["currentOwner"]
+		if (len(raw) == 0 || raw[0] == '"' || raw[0] == '{') && !hasLegacyOwner {
+			continue
+		}
+
+		record := newRecord()
+		err = json.Unmarshal(queryResponse.Value, record)
+		if err != nil {
+			return 0, fmt.Errorf("解析记录失败：%v", err)
+		}
+
+		// 解析时已将旧版本的当前所有者转换为所有者列表，改写前补充所有者索引（重复写入索引没有影响）
+		if realEstate, ok := record.(*RealEstate); ok && hasLegacyOwner {
+			err = s.updateOwnerIndex(ctx, realEstate.ID, nil, realEstate.Owners)
+			if err != nil {
+				return 0, err
+			}
+		}
+
+		err = s.putState(ctx, queryResponse.Key, record)
+		if err != nil {
+			return 0, err
+		}
+
+		migrated++
+	}
+
+	return migrated, nil
+}
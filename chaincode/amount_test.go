@@ -0,0 +1,195 @@
+package main
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+
+	"github.com/hyperledger/fabric-chaincode-go/v2/shim"
+)
+
+func TestParseDecimal(t *testing.T) {
+	tests := []struct {
+		value   string
+		scale   int
+		want    int64
+		wantErr bool
+	}{
+		{"12.3", 2, 1230, false},
+		{"12", 2, 1200, false},
+		{"0.01", 2, 1, false},
+		{" 12.30 ", 2, 1230, false},
+		{"12.300", 2, 1230, false}, // 超出精度的小数位为0时允许
+		{"12.345", 2, 0, true},     // 不做舍入，超出精度直接拒绝
+		{"12.301", 2, 0, true},
+		{"100", 0, 100, false},
+		{"100.0", 0, 100, false},
+		{"100.5", 0, 0, true},
+		{"92233720368547758.07", 2, math.MaxInt64, false},
+		{"92233720368547758.08", 2, 0, true}, // 超出 int64 范围
+		{"99999999999999999999", 0, 0, true},
+		{"", 2, 0, true},
+		{".", 2, 0, true},
+		{".5", 2, 0, true},
+		{"1.", 2, 0, true},
+		{"-1", 2, 0, true},
+		{"+1", 2, 0, true},
+		{"1e3", 2, 0, true},
+		{"1,000", 2, 0, true},
+	}
+
+	for _, tt := range tests {
+		got, err := parseDecimal(tt.value, tt.scale)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseDecimal(%q, %d) 错误 = %v，期望出错 = %v", tt.value, tt.scale, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("parseDecimal(%q, %d) = %d，期望 %d", tt.value, tt.scale, got, tt.want)
+		}
+	}
+}
+
+func TestFormatDecimal(t *testing.T) {
+	tests := []struct {
+		value int64
+		scale int
+		want  string
+	}{
+		{1230, 2, "12.30"},
+		{1, 2, "0.01"},
+		{0, 2, "0.00"},
+		{100, 0, "100"},
+		{5, 3, "0.005"},
+		{math.MaxInt64, 2, "92233720368547758.07"},
+	}
+
+	for _, tt := range tests {
+		got := formatDecimal(tt.value, tt.scale)
+		if got != tt.want {
+			t.Errorf("formatDecimal(%d, %d) = %q，期望 %q", tt.value, tt.scale, got, tt.want)
+		}
+
+		// 格式化结果可以按相同精度解析回原值
+		parsed, err := parseDecimal(got, tt.scale)
+		if err != nil || parsed != tt.value {
+			t.Errorf("parseDecimal(%q, %d) = %d, %v，期望 %d", got, tt.scale, parsed, err, tt.value)
+		}
+	}
+}
+
+func TestParseMoney(t *testing.T) {
+	tests := []struct {
+		amount   string
+		currency string
+		want     Money
+		wantErr  bool
+	}{
+		{"12.30", "CNY", Money{Amount: 1230, Currency: "CNY"}, false},
+		{"1000", "JPY", Money{Amount: 1000, Currency: "JPY"}, false},
+		{"1000.5", "JPY", Money{}, true}, // 日元没有辅币
+		{"0", "CNY", Money{}, true},
+		{"0.00", "USD", Money{}, true},
+		{"10", "cny", Money{}, true},
+		{"10", "BTC", Money{}, true},
+	}
+
+	for _, tt := range tests {
+		got, err := parseMoney(tt.amount, tt.currency)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseMoney(%q, %q) 错误 = %v，期望出错 = %v", tt.amount, tt.currency, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("parseMoney(%q, %q) = %+v，期望 %+v", tt.amount, tt.currency, got, tt.want)
+		}
+	}
+}
+
+func TestDecodeLegacyAmounts(t *testing.T) {
+	areas := []struct {
+		raw  string
+		want string
+	}{
+		{`"89.50"`, "89.50"},
+		{`89.5`, "89.50"},
+		{`100`, "100.00"},
+		{`120.999`, "121.00"}, // 旧版本浮点数按两位小数舍入
+	}
+	for _, tt := range areas {
+		got, err := decodeArea(json.RawMessage(tt.raw))
+		if err != nil || got != tt.want {
+			t.Errorf("decodeArea(%s) = %q, %v，期望 %q", tt.raw, got, err, tt.want)
+		}
+	}
+
+	amounts := []struct {
+		raw  string
+		want Money
+	}{
+		{`{"amount":1230,"currency":"USD"}`, Money{Amount: 1230, Currency: "USD"}},
+		{`1000000`, Money{Amount: 100000000, Currency: LEGACY_CURRENCY}},
+		{`19.99`, Money{Amount: 1999, Currency: LEGACY_CURRENCY}}, // 19.99*100 的浮点结果按最近整数舍入
+		{`0.1`, Money{Amount: 10, Currency: LEGACY_CURRENCY}},
+	}
+	for _, tt := range amounts {
+		got, err := decodeMoney(json.RawMessage(tt.raw))
+		if err != nil || got != tt.want {
+			t.Errorf("decodeMoney(%s) = %+v, %v，期望 %+v", tt.raw, got, err, tt.want)
+		}
+	}
+}
+
+func TestMigrateAmountsKeepsLegacyOwner(t *testing.T) {
+	s := &SmartContract{}
+	stub := newMockStub()
+	ctx := newTestContext(stub)
+	stub.setCaller(t, REALTY_ORG_MSPID, "admin", nil)
+
+	// 已迁移到新主键、但仍是旧版本格式（浮点数面积、单一所有者）的房产
+	key, err := shim.CreateCompositeKey(REAL_ESTATE, []string{"R1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	stub.state[key] = []byte(`{"id":"R1","propertyAddress":"北京市朝阳区","area":89.5,"currentOwner":"S","status":"NORMAL","createTime":"2024-01-01T00:00:00Z","updateTime":"2024-01-01T00:00:00Z"}`)
+
+	result, err := s.MigrateAmounts(ctx, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Migrated != 1 || result.HasMore {
+		t.Fatalf("迁移结果 = %+v，期望改写1条且没有剩余记录", result)
+	}
+
+	var stored map[string]json.RawMessage
+	if err := json.Unmarshal(stub.state[key], &stored); err != nil {
+		t.Fatal(err)
+	}
+	if string(stored["area"]) != `"89.50"` {
+		t.Fatalf("改写后的面积 = %s，期望 \"89.50\"", stored["area"])
+	}
+	var owners []Owner
+	if err := json.Unmarshal(stored["owners"], &owners); err != nil {
+		t.Fatal(err)
+	}
+	if len(owners) != 1 || owners[0] != (Owner{ID: "S", Share: FULL_SHARE}) {
+		t.Fatalf("改写后的所有者 = %s，期望 S 持有全部份额", stored["owners"])
+	}
+
+	owned, err := s.QueryRealEstateByOwner(ctx, "S", 10, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if owned.RecordsCount != 1 {
+		t.Fatalf("按所有者 S 查询到 %d 条房产，期望1条", owned.RecordsCount)
+	}
+
+	// 再次调用时已没有需要改写的记录
+	result, err = s.MigrateAmounts(ctx, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Migrated != 0 {
+		t.Fatalf("重复迁移改写了 %d 条记录，期望0条", result.Migrated)
+	}
+}
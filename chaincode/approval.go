@@ -0,0 +1,319 @@
+package main
+
+import (
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/v2/contractapi"
+)
+
+// TRANSACTION_APPROVAL 交易签署记录文档类型（保存在交易私有数据集合中，复合键：TXA_交易ID_交易方ID）
+const TRANSACTION_APPROVAL = "TXA"
+
+// PartyRole 交易方在交易中的角色
+type PartyRole string
+
+const (
+	PARTY_SELLER PartyRole = "SELLER" // 卖家
+	PARTY_BUYER  PartyRole = "BUYER"  // 买家
+)
+
+// TransactionTerms 交易条款（签署内容为其 JSON 序列化结果，字段顺序固定）
+type TransactionTerms struct {
+	TxID         string `json:"txId"`         // 交易ID
+	RealEstateID string `json:"realEstateId"` // 房产ID
+	Seller       string `json:"seller"`       // 卖家（交易方ID）
+	Buyer        string `json:"buyer"`        // 买家（交易方ID）
+	Share        int    `json:"share"`        // 出售份额（万分比）
+	Price        Money  `json:"price"`        // 成交价格
+	Salt         string `json:"salt"`         // 交易私有数据的盐值（防止通过穷举价格验证签名）
+}
+
+// TransactionApproval 交易方对交易条款的签署（买家身份可以通过签名和公钥推断，与交易私有数据保存在同一私有数据集合中）
+type TransactionApproval struct {
+	TxID       string    `json:"txId"`       // 交易ID
+	PartyID    string    `json:"partyId"`    // 交易方ID
+	Role       PartyRole `json:"role"`       // 交易方角色
+	TermsHash  string    `json:"termsHash"`  // 签署的交易条款 SHA-256 哈希（十六进制）
+	Signature  string    `json:"signature"`  // ECDSA 签名（Base64 编码的 ASN.1 DER）
+	CreateTime time.Time `json:"createTime"` // 签署时间
+}
+
+// TransactionSigningPayload 交易待签署内容及签署状态
+type TransactionSigningPayload struct {
+	TxID           string                 `json:"txId"`           // 交易ID
+	Terms          string                 `json:"terms"`          // 待签署的交易条款（规范 JSON，使用 SHA-256 摘要签名）
+	TermsHash      string                 `json:"termsHash"`      // 交易条款的 SHA-256 哈希（十六进制）
+	SellerApproved bool                   `json:"sellerApproved"` // 卖家的签署是否有效
+	BuyerApproved  bool                   `json:"buyerApproved"`  // 买家的签署是否有效
+	Approvals      []*TransactionApproval `json:"approvals"`      // 已提交的签署
+}
+
+// ApproveTransaction 提交交易方对交易条款的签署（仅拥有交易权限的组织可以调用）
+//
+// signature 为交易方用已登记公钥对应的私钥对交易条款 SHA-256 摘要的 ECDSA 签名（Base64 编码的 ASN.1 DER），
+// 合约验证通过后保存；同一交易方重复提交时以最新的签署为准
+func (s *SmartContract) ApproveTransaction(ctx contractapi.TransactionContextInterface, txID string, partyID string, signature string) error {
+	// 验证调用者所在组织是否拥有交易权限
+	err := s.checkCapability(ctx, "签署交易", CAP_TRADING)
+	if err != nil {
+		return err
+	}
+
+	// 以账本交易时间作为签署时间
+	createTime, err := s.getTxTime(ctx)
+	if err != nil {
+		return err
+	}
+
+	// 查询交易信息（只有未完成的交易可以签署）
+	transaction, err := s.getOpenTransaction(ctx, txID)
+	if err != nil {
+		return err
+	}
+
+	privateDetails, err := s.getTransactionPrivateDetails(ctx, txID)
+	if err != nil {
+		return err
+	}
+	if privateDetails == nil {
+		return fmt.Errorf("交易 %s 没有私有数据中的交易条款，无法签署", txID)
+	}
+
+	var role PartyRole
+	switch partyID {
+	case transaction.Seller:
+		role = PARTY_SELLER
+	case privateDetails.Buyer:
+		role = PARTY_BUYER
+	default:
+		return fmt.Errorf("交易方 %s 不是交易 %s 的买家或卖家", partyID, txID)
+	}
+
+	_, termsHash, err := transactionTerms(transaction, privateDetails)
+	if err != nil {
+		return err
+	}
+
+	err = s.verifyPartySignature(ctx, partyID, termsHash, signature)
+	if err != nil {
+		return err
+	}
+
+	approval := &TransactionApproval{
+		TxID:       txID,
+		PartyID:    partyID,
+		Role:       role,
+		TermsHash:  hex.EncodeToString(termsHash),
+		Signature:  signature,
+		CreateTime: createTime,
+	}
+
+	key, err := s.getCompositeKey(ctx, TRANSACTION_APPROVAL, []string{txID, partyID})
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(approval)
+	if err != nil {
+		return fmt.Errorf("序列化签署记录失败：%v", err)
+	}
+
+	err = ctx.GetStub().PutPrivateData(TRANSACTION_PRIVATE_COLLECTION, key, data)
+	if err != nil {
+		return fmt.Errorf("保存签署记录失败：%v", err)
+	}
+	return nil
+}
+
+// QueryTransactionSigningPayload 查询交易待签署的条款及买卖双方的签署状态（仅拥有结算或交易权限的组织可以调用）
+func (s *SmartContract) QueryTransactionSigningPayload(ctx contractapi.TransactionContextInterface, txID string) (*TransactionSigningPayload, error) {
+	// 验证调用者所在组织是否拥有结算或交易权限
+	err := s.checkCapability(ctx, "查询交易签署内容", CAP_SETTLEMENT, CAP_TRADING)
+	if err != nil {
+		return nil, err
+	}
+
+	transaction, err := s.getTransaction(ctx, txID)
+	if err != nil {
+		return nil, err
+	}
+
+	privateDetails, err := s.getTransactionPrivateDetails(ctx, txID)
+	if err != nil {
+		return nil, err
+	}
+	if privateDetails == nil {
+		return nil, fmt.Errorf("交易 %s 没有私有数据中的交易条款", txID)
+	}
+
+	terms, termsHash, err := transactionTerms(transaction, privateDetails)
+	if err != nil {
+		return nil, err
+	}
+
+	payload := &TransactionSigningPayload{
+		TxID:      txID,
+		Terms:     string(terms),
+		TermsHash: hex.EncodeToString(termsHash),
+		Approvals: make([]*TransactionApproval, 0),
+	}
+	for _, partyID := range []string{transaction.Seller, privateDetails.Buyer} {
+		approval, err := s.getTransactionApproval(ctx, txID, partyID)
+		if err != nil {
+			return nil, err
+		}
+		if approval == nil {
+			continue
+		}
+		payload.Approvals = append(payload.Approvals, approval)
+
+		valid := s.verifyPartySignature(ctx, partyID, termsHash, approval.Signature) == nil
+		if approval.Role == PARTY_SELLER {
+			payload.SellerApproved = valid
+		} else {
+			payload.BuyerApproved = valid
+		}
+	}
+	return payload, nil
+}
+
+// 通用方法：检查买卖双方均已用当前登记的公钥有效签署交易条款
+func (s *SmartContract) checkTransactionApprovals(ctx contractapi.TransactionContextInterface, transaction *Transaction, privateDetails *TransactionPrivateDetails) error {
+	_, termsHash, err := transactionTerms(transaction, privateDetails)
+	if err != nil {
+		return err
+	}
+
+	parties := []struct {
+		name string
+		id   string
+	}{{"卖家", transaction.Seller}, {"买家", privateDetails.Buyer}}
+	for _, party := range parties {
+		approval, err := s.getTransactionApproval(ctx, transaction.ID, party.id)
+		if err != nil {
+			return err
+		}
+		if approval == nil {
+			return fmt.Errorf("%s %s 尚未签署交易 %s", party.name, party.id, transaction.ID)
+		}
+
+		err = s.verifyPartySignature(ctx, party.id, termsHash, approval.Signature)
+		if err != nil {
+			return fmt.Errorf("%s %s 的签署无效：%v", party.name, party.id, err)
+		}
+	}
+	return nil
+}
+
+// 通用方法：生成交易条款的规范 JSON 及其 SHA-256 摘要
+func transactionTerms(transaction *Transaction, privateDetails *TransactionPrivateDetails) ([]byte, []byte, error) {
+	terms, err := json.Marshal(TransactionTerms{
+		TxID:         transaction.ID,
+		RealEstateID: transaction.RealEstateID,
+		Seller:       transaction.Seller,
+		Buyer:        privateDetails.Buyer,
+		Share:        transaction.Share,
+		Price:        privateDetails.Price,
+		Salt:         privateDetails.Salt,
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("序列化交易条款失败：%v", err)
+	}
+
+	hash := sha256.Sum256(terms)
+	return terms, hash[:], nil
+}
+
+// 通用方法：使用交易方登记的公钥验证对摘要的签名
+func (s *SmartContract) verifyPartySignature(ctx contractapi.TransactionContextInterface, partyID string, digest []byte, signature string) error {
+	party, err := s.getParty(ctx, partyID)
+	if err != nil {
+		return err
+	}
+	if len(party.PublicKey) == 0 {
+		return fmt.Errorf("交易方 %s 尚未登记公钥", partyID)
+	}
+
+	publicKey, err := parseECDSAPublicKey(party.PublicKey)
+	if err != nil {
+		return err
+	}
+
+	sig, err := base64.StdEncoding.DecodeString(signature)
+	if err != nil {
+		return fmt.Errorf("签名不是有效的 Base64 编码：%v", err)
+	}
+
+	if !ecdsa.VerifyASN1(publicKey, digest, sig) {
+		return fmt.Errorf("交易方 %s 的签名验证失败", partyID)
+	}
+	return nil
+}
+
+// 通用方法：计算交易方当前登记公钥的 SHA-256 指纹（按参数顺序返回）
+func (s *SmartContract) getSignerKeyFingerprints(ctx contractapi.TransactionContextInterface, partyIDs ...string) ([]string, error) {
+	fingerprints := make([]string, 0, len(partyIDs))
+	for _, partyID := range partyIDs {
+		party, err := s.getParty(ctx, partyID)
+		if err != nil {
+			return nil, err
+		}
+		if len(party.PublicKey) == 0 {
+			return nil, fmt.Errorf("交易方 %s 尚未登记公钥", partyID)
+		}
+
+		hash := sha256.Sum256([]byte(party.PublicKey))
+		fingerprints = append(fingerprints, hex.EncodeToString(hash[:]))
+	}
+	return fingerprints, nil
+}
+
+// 通用方法：读取交易方的签署记录（不存在时返回 nil）
+func (s *SmartContract) getTransactionApproval(ctx contractapi.TransactionContextInterface, txID string, partyID string) (*TransactionApproval, error) {
+	key, err := s.getCompositeKey(ctx, TRANSACTION_APPROVAL, []string{txID, partyID})
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := ctx.GetStub().GetPrivateData(TRANSACTION_PRIVATE_COLLECTION, key)
+	if err != nil {
+		return nil, fmt.Errorf("读取签署记录失败：%v", err)
+	}
+	if data == nil {
+		return nil, nil
+	}
+
+	var approval TransactionApproval
+	err = json.Unmarshal(data, &approval)
+	if err != nil {
+		return nil, fmt.Errorf("解析签署记录失败：%v", err)
+	}
+	return &approval, nil
+}
+
+// 通用方法：解析 PEM 格式的 ECDSA 公钥
+func parseECDSAPublicKey(publicKey string) (*ecdsa.PublicKey, error) {
+	block, _ := pem.Decode([]byte(publicKey))
+	if block == nil {
+		return nil, fmt.Errorf("公钥不是有效的 PEM 格式")
+	}
+
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("解析公钥失败：%v", err)
+	}
+
+	ecdsaKey, ok := key.(*ecdsa.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("公钥必须为 ECDSA 公钥")
+	}
+	return ecdsaKey, nil
+}
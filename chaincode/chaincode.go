@@ -4,6 +4,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
+	"slices"
+	"sort"
 	"time"
 
 	"github.com/hyperledger/fabric-chaincode-go/v2/pkg/cid"
@@ -17,49 +19,103 @@ type SmartContract struct {
 	contractapi.Contract
 }
 
-// 文档类型常量（用于创建复合键）
+// 文档类型常量（用于创建复合键：类型_ID）
 const (
 	REAL_ESTATE = "RE" // 房产信息
 	TRANSACTION = "TX" // 交易信息
 )
 
+// 索引类型常量（复合键：索引类型_索引值_ID，值为空）
+const (
+	REAL_ESTATE_STATUS_INDEX = "RE-STATUS" // 房产状态索引（按状态查询房产）
+	TRANSACTION_STATUS_INDEX = "TX-STATUS" // 交易状态索引（按状态查询交易）
+	REAL_ESTATE_OWNER_INDEX  = "RE-OWNER"  // 房产所有者索引（按所有者查询房产）
+	TRANSACTION_PARTY_INDEX  = "TX-PARTY"  // 交易方索引（按买家或卖家查询交易）
+)
+
 // RealEstateStatus 房产状态
 type RealEstateStatus string
 
 const (
 	NORMAL         RealEstateStatus = "NORMAL"         // 正常
 	IN_TRANSACTION RealEstateStatus = "IN_TRANSACTION" // 交易中
+	FROZEN         RealEstateStatus = "FROZEN"         // 司法冻结
+	RETIRED        RealEstateStatus = "RETIRED"        // 已注销（拆除或征收）
+)
+
+// PropertyType 房产类型（用于按类型计算交易税费）
+type PropertyType string
+
+const (
+	RESIDENTIAL PropertyType = "RESIDENTIAL" // 住宅
+	COMMERCIAL  PropertyType = "COMMERCIAL"  // 商业
+	OFFICE      PropertyType = "OFFICE"      // 办公
+	INDUSTRIAL  PropertyType = "INDUSTRIAL"  // 工业
 )
 
+// validPropertyTypes 合法的房产类型
+var validPropertyTypes = map[PropertyType]bool{
+	RESIDENTIAL: true,
+	COMMERCIAL:  true,
+	OFFICE:      true,
+	INDUSTRIAL:  true,
+}
+
 // TransactionStatus 交易状态
 type TransactionStatus string
 
 const (
-	PENDING   TransactionStatus = "PENDING"   // 待付款
-	COMPLETED TransactionStatus = "COMPLETED" // 已完成
+	DRAFT          TransactionStatus = "DRAFT"          // 待确认（已保存私有数据，待登记机构背书将房产转为交易中）
+	PENDING        TransactionStatus = "PENDING"        // 待付款
+	PARTIALLY_PAID TransactionStatus = "PARTIALLY_PAID" // 部分付款（托管中）
+	PAID           TransactionStatus = "PAID"           // 已付清（待结算）
+	SETTLED        TransactionStatus = "SETTLED"        // 已结算（已复核签署、计算税费并公开买家，待登记机构背书完成过户）
+	COMPLETED      TransactionStatus = "COMPLETED"      // 已完成
+	CANCELLED      TransactionStatus = "CANCELLED"      // 已取消
+	EXPIRED        TransactionStatus = "EXPIRED"        // 已过期
 )
 
+// TRANSACTION_VALIDITY 交易草稿和待付款交易的有效期（从生成草稿时起算），超过后任何组织都可以使其过期
+const TRANSACTION_VALIDITY = 7 * 24 * time.Hour
+
 // RealEstate 房产信息
 type RealEstate struct {
-	ID              string           `json:"id"`              // 房产ID
-	PropertyAddress string           `json:"propertyAddress"` // 房产地址
-	Area            float64          `json:"area"`            // 面积
-	CurrentOwner    string           `json:"currentOwner"`    // 当前所有者
-	Status          RealEstateStatus `json:"status"`          // 状态
-	CreateTime      time.Time        `json:"createTime"`      // 创建时间
-	UpdateTime      time.Time        `json:"updateTime"`      // 更新时间
+	ID              string           `json:"id"`                   // 房产ID
+	PropertyAddress string           `json:"propertyAddress"`      // 房产地址
+	Area            string           `json:"area"`                 // 面积（平方米，保留两位小数的定点小数）
+	PropertyType    PropertyType     `json:"propertyType"`         // 房产类型（旧版本创建的房产为空，按住宅处理）
+	Owners          []Owner          `json:"owners"`               // 所有者列表
+	Status          RealEstateStatus `json:"status"`               // 状态
+	CreateTime      time.Time        `json:"createTime"`           // 创建时间
+	UpdateTime      time.Time        `json:"updateTime"`           // 更新时间
+	Retirement      *Retirement      `json:"retirement,omitempty"` // 注销信息（仅已注销的房产）
+	Freezes         []*Freeze        `json:"freezes,omitempty"`    // 司法冻结历史（仅查询房产时返回，不随房产保存）
+}
+
+// FULL_SHARE 房产的全部份额（份额以万分比表示，所有者份额之和必须等于该值）
+const FULL_SHARE = 10000
+
+// Owner 房产所有者及其份额
+type Owner struct {
+	ID    string `json:"id"`    // 所有者（交易方ID）
+	Share int    `json:"share"` // 份额（万分比）
 }
 
-// Transaction 交易信息
+// Transaction 交易信息（买家和价格保存在私有数据集合中，公开状态只保存其哈希）
 type Transaction struct {
-	ID           string            `json:"id"`           // 交易ID
-	RealEstateID string            `json:"realEstateId"` // 房产ID
-	Seller       string            `json:"seller"`       // 卖家
-	Buyer        string            `json:"buyer"`        // 买家
-	Price        float64           `json:"price"`        // 成交价格
-	Status       TransactionStatus `json:"status"`       // 状态
-	CreateTime   time.Time         `json:"createTime"`   // 创建时间
-	UpdateTime   time.Time         `json:"updateTime"`   // 更新时间
+	ID              string            `json:"id"`                   // 交易ID
+	RealEstateID    string            `json:"realEstateId"`         // 房产ID
+	Seller          string            `json:"seller"`               // 卖家（交易方ID）
+	Buyer           string            `json:"buyer,omitempty"`      // 买家（交易方ID，结算后、过户前才公开）
+	Share           int               `json:"share"`                // 出售份额（万分比）
+	PrivateDataHash string            `json:"privateDataHash"`      // 私有数据（买家、价格、盐值）的 SHA-256 哈希
+	SignerKeys      []string          `json:"signerKeys,omitempty"` // 结算时复核签署使用的卖家、买家公钥 SHA-256 指纹（过户时确认未更换）
+	Status          TransactionStatus `json:"status"`               // 状态
+	CancelReason    string            `json:"cancelReason"`         // 取消原因
+	ExpireTime      time.Time         `json:"expireTime"`           // 过期时间
+	CreateTime      time.Time         `json:"createTime"`           // 创建时间
+	UpdateTime      time.Time         `json:"updateTime"`           // 更新时间
+	Fees            []*Fee            `json:"fees,omitempty"`       // 完成时计算的税费明细（仅查询交易时向可以读取私有数据的组织返回，不随交易保存）
 }
 
 // QueryResult 分页查询结果
@@ -70,7 +126,31 @@ type QueryResult struct {
 	FetchedRecordsCount int32         `json:"fetchedRecordsCount"` // 总共获取的记录数
 }
 
-// 组织 MSP ID 常量
+// HistoryRecord 历史记录（同一ID在主键及迁移前各状态复合键上的变更合并后的时间线）
+type HistoryRecord struct {
+	TxID      string      `json:"txId"`      // Fabric 交易ID
+	Timestamp time.Time   `json:"timestamp"` // 交易时间
+	IsDelete  bool        `json:"isDelete"`  // 是否为删除操作（状态变更时旧的复合键会被删除）
+	Status    string      `json:"status"`    // 变更后的状态（删除操作时为旧复合键中的状态）
+	Value     interface{} `json:"value"`     // 变更后的数据，删除操作时为空
+}
+
+// 链码事件名称常量
+const (
+	EVENT_REAL_ESTATE_CREATED   = "RealEstateCreated"    // 房产已创建
+	EVENT_TRANSACTION_CREATED   = "TransactionCreated"   // 交易已生成
+	EVENT_TRANSACTION_COMPLETED = "TransactionCompleted" // 交易已完成
+	EVENT_REAL_ESTATE_RETIRED   = "RealEstateRetired"    // 房产已注销
+)
+
+// EventPayload 链码事件负载
+type EventPayload struct {
+	EntityID  string      `json:"entityId"`  // 实体ID（房产ID或交易ID）
+	Timestamp time.Time   `json:"timestamp"` // 账本交易时间
+	Data      interface{} `json:"data"`      // 事件发生后的实体数据
+}
+
+// 初始组织 MSP ID 常量（账本上还没有治理记录时的默认权限见 governance.go）
 const (
 	REALTY_ORG_MSPID = "Org1MSP" // 不动产登记机构组织 MSP ID
 	BANK_ORG_MSPID   = "Org2MSP" // 银行组织 MSP ID
@@ -86,6 +166,33 @@ func (s *SmartContract) getClientIdentityMSPID(ctx contractapi.TransactionContex
 	return clientID.GetMSPID()
 }
 
+// 通用方法：获取账本交易时间（取自交易提案，所有背书节点一致，不再信任客户端传入的时间参数）
+func (s *SmartContract) getTxTime(ctx contractapi.TransactionContextInterface) (time.Time, error) {
+	timestamp, err := ctx.GetStub().GetTxTimestamp()
+	if err != nil {
+		return time.Time{}, fmt.Errorf("获取交易时间失败：%v", err)
+	}
+	return timestamp.AsTime(), nil
+}
+
+// 通用方法：发送链码事件（每个交易只保留最后一次设置的事件）
+func (s *SmartContract) setEvent(ctx contractapi.TransactionContextInterface, eventName string, entityID string, timestamp time.Time, data interface{}) error {
+	payload, err := json.Marshal(EventPayload{
+		EntityID:  entityID,
+		Timestamp: timestamp,
+		Data:      data,
+	})
+	if err != nil {
+		return fmt.Errorf("序列化事件数据失败：%v", err)
+	}
+
+	err = ctx.GetStub().SetEvent(eventName, payload)
+	if err != nil {
+		return fmt.Errorf("发送链码事件失败：%v", err)
+	}
+	return nil
+}
+
 // 通用方法：创建和获取复合键
 func (s *SmartContract) getCompositeKey(ctx contractapi.TransactionContextInterface, objectType string, attributes []string) (string, error) {
 	key, err := ctx.GetStub().CreateCompositeKey(objectType, attributes)
@@ -126,87 +233,406 @@ func (s *SmartContract) putState(ctx contractapi.TransactionContextInterface, ke
 	return nil
 }
 
-// CreateRealEstate 创建房产信息（仅不动产登记机构组织可以调用）
-func (s *SmartContract) CreateRealEstate(ctx contractapi.TransactionContextInterface, id string, address string, area float64, owner string, createTime time.Time) error {
-	// 检查调用者身份
-	clientMSPID, err := s.getClientIdentityMSPID(ctx)
+// 通用方法：获取房产信息
+func (s *SmartContract) getRealEstate(ctx contractapi.TransactionContextInterface, id string) (*RealEstate, error) {
+	key, err := s.getCompositeKey(ctx, REAL_ESTATE, []string{id})
 	if err != nil {
-		return fmt.Errorf("获取调用者身份失败：%v", err)
+		return nil, err
 	}
 
-	// 验证是否是不动产登记机构组织的成员
-	if clientMSPID != REALTY_ORG_MSPID {
-		return fmt.Errorf("只有不动产登记机构组织成员才能创建房产信息")
+	bytes, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return nil, fmt.Errorf("查询房产信息失败：%v", err)
+	}
+	if bytes == nil {
+		return nil, fmt.Errorf("房产ID %s 不存在", id)
 	}
 
-	// 参数验证
-	if len(id) == 0 {
-		return fmt.Errorf("房产ID不能为空")
+	var realEstate RealEstate
+	err = json.Unmarshal(bytes, &realEstate)
+	if err != nil {
+		return nil, fmt.Errorf("解析房产信息失败：%v", err)
 	}
-	if len(address) == 0 {
-		return fmt.Errorf("房产地址不能为空")
+	return &realEstate, nil
+}
+
+// 通用方法：保存房产信息并维护状态索引（oldStatus 为空表示新建）
+func (s *SmartContract) putRealEstate(ctx contractapi.TransactionContextInterface, realEstate *RealEstate, oldStatus RealEstateStatus) error {
+	key, err := s.getCompositeKey(ctx, REAL_ESTATE, []string{realEstate.ID})
+	if err != nil {
+		return err
 	}
-	if area <= 0 {
-		return fmt.Errorf("面积必须大于0")
+
+	// 冻结历史单独保存
+	stored := *realEstate
+	stored.Freezes = nil
+	err = s.putState(ctx, key, stored)
+	if err != nil {
+		return err
 	}
-	if len(owner) == 0 {
-		return fmt.Errorf("所有者不能为空")
+
+	return s.updateStatusIndex(ctx, REAL_ESTATE_STATUS_INDEX, realEstate.ID, string(oldStatus), string(realEstate.Status))
+}
+
+// 通用方法：获取交易信息
+func (s *SmartContract) getTransaction(ctx contractapi.TransactionContextInterface, txID string) (*Transaction, error) {
+	key, err := s.getCompositeKey(ctx, TRANSACTION, []string{txID})
+	if err != nil {
+		return nil, err
 	}
 
-	// 检查房产是否已存在（检查所有可能的状态）
-	for _, status := range []RealEstateStatus{NORMAL, IN_TRANSACTION} {
-		key, err := s.getCompositeKey(ctx, REAL_ESTATE, []string{string(status), id})
+	bytes, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return nil, fmt.Errorf("查询交易信息失败：%v", err)
+	}
+	if bytes == nil {
+		return nil, fmt.Errorf("交易ID %s 不存在", txID)
+	}
+
+	var transaction Transaction
+	err = json.Unmarshal(bytes, &transaction)
+	if err != nil {
+		return nil, fmt.Errorf("解析交易信息失败：%v", err)
+	}
+	return &transaction, nil
+}
+
+// 通用方法：获取未结束（待付款、部分付款或已付清）的交易信息
+func (s *SmartContract) getOpenTransaction(ctx contractapi.TransactionContextInterface, txID string) (*Transaction, error) {
+	transaction, err := s.getTransaction(ctx, txID)
+	if err != nil {
+		return nil, err
+	}
+
+	if transaction.Status != DRAFT && transaction.Status != PENDING && transaction.Status != PARTIALLY_PAID &&
+		transaction.Status != PAID && transaction.Status != SETTLED {
+		return nil, fmt.Errorf("交易 %s 当前状态为 %s，已经结束", txID, transaction.Status)
+	}
+	return transaction, nil
+}
+
+// 通用方法：保存交易信息并维护状态索引（oldStatus 为空表示新建）
+func (s *SmartContract) putTransaction(ctx contractapi.TransactionContextInterface, transaction *Transaction, oldStatus TransactionStatus) error {
+	key, err := s.getCompositeKey(ctx, TRANSACTION, []string{transaction.ID})
+	if err != nil {
+		return err
+	}
+
+	err = s.putState(ctx, key, transaction)
+	if err != nil {
+		return err
+	}
+
+	return s.updateStatusIndex(ctx, TRANSACTION_STATUS_INDEX, transaction.ID, string(oldStatus), string(transaction.Status))
+}
+
+// 通用方法：更新状态索引（删除旧状态的索引键，写入新状态的索引键）
+func (s *SmartContract) updateStatusIndex(ctx contractapi.TransactionContextInterface, indexName string, id string, oldStatus string, newStatus string) error {
+	if oldStatus == newStatus {
+		return nil
+	}
+
+	if oldStatus != "" {
+		err := s.deleteIndex(ctx, indexName, []string{oldStatus, id})
 		if err != nil {
-			return fmt.Errorf("创建复合键失败：%v", err)
+			return err
 		}
+	}
+
+	return s.putIndex(ctx, indexName, []string{newStatus, id})
+}
 
-		exists, err := ctx.GetStub().GetState(key)
+// 通用方法：更新房产所有者索引（删除不再持有份额的所有者，写入新的所有者）
+func (s *SmartContract) updateOwnerIndex(ctx contractapi.TransactionContextInterface, realEstateID string, oldOwners []Owner, newOwners []Owner) error {
+	current := make(map[string]bool, len(newOwners))
+	for _, owner := range newOwners {
+		current[owner.ID] = true
+	}
+
+	previous := make(map[string]bool, len(oldOwners))
+	for _, owner := range oldOwners {
+		previous[owner.ID] = true
+		if current[owner.ID] {
+			continue
+		}
+		err := s.deleteIndex(ctx, REAL_ESTATE_OWNER_INDEX, []string{owner.ID, realEstateID})
 		if err != nil {
-			return fmt.Errorf("查询房产信息失败：%v", err)
+			return err
+		}
+	}
+
+	for _, owner := range newOwners {
+		if previous[owner.ID] {
+			continue
+		}
+		err := s.putIndex(ctx, REAL_ESTATE_OWNER_INDEX, []string{owner.ID, realEstateID})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// 通用方法：写入交易方索引（卖家和已公开的买家）
+func (s *SmartContract) putPartyIndex(ctx contractapi.TransactionContextInterface, transaction *Transaction) error {
+	for _, party := range []string{transaction.Seller, transaction.Buyer} {
+		if len(party) == 0 {
+			continue
+		}
+		err := s.putIndex(ctx, TRANSACTION_PARTY_INDEX, []string{party, transaction.ID})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// 通用方法：写入索引键
+func (s *SmartContract) putIndex(ctx contractapi.TransactionContextInterface, indexName string, attributes []string) error {
+	key, err := s.getCompositeKey(ctx, indexName, attributes)
+	if err != nil {
+		return err
+	}
+	err = ctx.GetStub().PutState(key, []byte{0x00})
+	if err != nil {
+		return fmt.Errorf("保存索引失败：%v", err)
+	}
+	return nil
+}
+
+// 通用方法：删除索引键
+func (s *SmartContract) deleteIndex(ctx contractapi.TransactionContextInterface, indexName string, attributes []string) error {
+	key, err := s.getCompositeKey(ctx, indexName, attributes)
+	if err != nil {
+		return err
+	}
+	err = ctx.GetStub().DelState(key)
+	if err != nil {
+		return fmt.Errorf("删除索引失败：%v", err)
+	}
+	return nil
+}
+
+// decodeRealEstate 解析房产信息
+func decodeRealEstate(bytes []byte) (interface{}, error) {
+	var realEstate RealEstate
+	err := json.Unmarshal(bytes, &realEstate)
+	if err != nil {
+		return nil, fmt.Errorf("解析房产信息失败：%v", err)
+	}
+	return realEstate, nil
+}
+
+// decodeTransaction 解析交易信息
+func decodeTransaction(bytes []byte) (interface{}, error) {
+	var transaction Transaction
+	err := json.Unmarshal(bytes, &transaction)
+	if err != nil {
+		return nil, fmt.Errorf("解析交易信息失败：%v", err)
+	}
+	return transaction, nil
+}
+
+// UnmarshalJSON 解析交易信息，旧版本的交易没有出售份额，按出售全部份额处理
+func (t *Transaction) UnmarshalJSON(data []byte) error {
+	type transactionJSON Transaction
+	if err := json.Unmarshal(data, (*transactionJSON)(t)); err != nil {
+		return err
+	}
+
+	if t.Share == 0 {
+		t.Share = FULL_SHARE
+	}
+	return nil
+}
+
+// 通用方法：校验所有者列表（所有者不能为空或重复，份额之和必须等于全部份额）
+func (s *SmartContract) validateOwners(owners []Owner) error {
+	if len(owners) == 0 {
+		return fmt.Errorf("所有者不能为空")
+	}
+
+	total := 0
+	seen := make(map[string]bool, len(owners))
+	for _, owner := range owners {
+		if len(owner.ID) == 0 {
+			return fmt.Errorf("所有者不能为空")
+		}
+		if seen[owner.ID] {
+			return fmt.Errorf("所有者 %s 重复", owner.ID)
+		}
+		if owner.Share <= 0 {
+			return fmt.Errorf("所有者 %s 的份额必须大于0", owner.ID)
+		}
+		seen[owner.ID] = true
+		total += owner.Share
+	}
+
+	if total != FULL_SHARE {
+		return fmt.Errorf("所有者份额之和必须等于%d，当前为%d", FULL_SHARE, total)
+	}
+	return nil
+}
+
+// ownerShare 获取所有者持有的份额，不是所有者时返回0
+func (r *RealEstate) ownerShare(ownerID string) int {
+	for _, owner := range r.Owners {
+		if owner.ID == ownerID {
+			return owner.Share
+		}
+	}
+	return 0
+}
+
+// transferShare 将份额从卖家转移给买家，卖家份额清零时移出所有者列表
+func (r *RealEstate) transferShare(seller string, buyer string, share int) error {
+	if share <= 0 {
+		return fmt.Errorf("转让份额必须大于0")
+	}
+	if r.ownerShare(seller) < share {
+		return fmt.Errorf("卖家持有份额不足")
+	}
+
+	owners := make([]Owner, 0, len(r.Owners)+1)
+	buyerFound := false
+	for _, owner := range r.Owners {
+		switch owner.ID {
+		case seller:
+			owner.Share -= share
+		case buyer:
+			owner.Share += share
+			buyerFound = true
 		}
-		if exists != nil {
-			return fmt.Errorf("房产ID %s 已存在", id)
+		if owner.Share > 0 {
+			owners = append(owners, owner)
 		}
 	}
+	if !buyerFound {
+		owners = append(owners, Owner{ID: buyer, Share: share})
+	}
+
+	r.Owners = owners
+	return nil
+}
+
+// CreateRealEstate 创建房产信息（仅拥有登记权限的组织可以调用）
+//
+// 房产信息设置了键级背书策略，之后的修改都必须有调用者所在组织的节点背书（见 SetRealEstateEndorsementPolicy）
+func (s *SmartContract) CreateRealEstate(ctx contractapi.TransactionContextInterface, id string, address string, area string, propertyType string, owners []Owner) error {
+	// 验证调用者所在组织是否拥有登记权限
+	err := s.checkCapability(ctx, "创建房产信息", CAP_REGISTRY)
+	if err != nil {
+		return err
+	}
+
+	// 以账本交易时间作为创建时间
+	createTime, err := s.getTxTime(ctx)
+	if err != nil {
+		return err
+	}
+
+	// 参数验证
+	if len(id) == 0 {
+		return fmt.Errorf("房产ID不能为空")
+	}
+	if len(address) == 0 {
+		return fmt.Errorf("房产地址不能为空")
+	}
+	area, err = parseArea(area)
+	if err != nil {
+		return err
+	}
+	if !validPropertyTypes[PropertyType(propertyType)] {
+		return fmt.Errorf("房产类型 %s 无效", propertyType)
+	}
+	err = s.validateOwners(owners)
+	if err != nil {
+		return err
+	}
+
+	// 检查所有者均已登记为交易方
+	ownerIDs := make([]string, 0, len(owners))
+	for _, owner := range owners {
+		ownerIDs = append(ownerIDs, owner.ID)
+	}
+	err = s.checkPartiesRegistered(ctx, ownerIDs...)
+	if err != nil {
+		return err
+	}
+
+	// 检查房产是否已存在
+	key, err := s.getCompositeKey(ctx, REAL_ESTATE, []string{id})
+	if err != nil {
+		return err
+	}
+
+	exists, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return fmt.Errorf("查询房产信息失败：%v", err)
+	}
+	if exists != nil {
+		return fmt.Errorf("房产ID %s 已存在", id)
+	}
 
 	// 创建房产信息
 	realEstate := RealEstate{
 		ID:              id,
 		PropertyAddress: address,
 		Area:            area,
-		CurrentOwner:    owner,
+		PropertyType:    PropertyType(propertyType),
+		Owners:          owners,
 		Status:          NORMAL,
 		CreateTime:      createTime,
 		UpdateTime:      createTime,
 	}
 
-	// 保存房产信息（复合键：类型_状态_ID）
-	key, err := s.getCompositeKey(ctx, REAL_ESTATE, []string{string(NORMAL), id})
+	err = s.putRealEstate(ctx, &realEstate, "")
 	if err != nil {
 		return err
 	}
 
-	err = s.putState(ctx, key, realEstate)
+	// 之后对房产信息的修改都必须经过登记机构背书（通道的多数背书策略下其他组织联合起来也无法绕过登记机构）
+	clientMSPID, err := s.getClientIdentityMSPID(ctx)
+	if err != nil {
+		return fmt.Errorf("获取调用者身份失败：%v", err)
+	}
+	err = s.setEndorsementPolicy(ctx, key, []string{clientMSPID})
 	if err != nil {
 		return err
 	}
 
-	return nil
+	err = s.updateOwnerIndex(ctx, id, nil, owners)
+	if err != nil {
+		return err
+	}
+
+	return s.setEvent(ctx, EVENT_REAL_ESTATE_CREATED, id, createTime, realEstate)
 }
 
-// CreateTransaction 生成交易（仅交易平台组织可以调用）
-func (s *SmartContract) CreateTransaction(ctx contractapi.TransactionContextInterface, txID string, realEstateID string, seller string, buyer string, price float64, createTime time.Time) error {
-	// 检查调用者身份
-	clientMSPID, err := s.getClientIdentityMSPID(ctx)
+// CreateTransaction 生成交易草稿（仅拥有交易权限的组织可以调用，share 为0时出售卖家持有的全部份额）
+//
+// 买家、价格、币种和盐值通过 transient map 传入（见 TransactionPrivateInput），保存到私有数据集合中，只由集合成员背书；
+// 房产键需要登记机构背书，而登记机构的节点不是集合成员，草稿再由 OpenTransaction 经登记机构背书将房产转为交易中
+func (s *SmartContract) CreateTransaction(ctx contractapi.TransactionContextInterface, txID string, realEstateID string, seller string, share int) error {
+	// 验证调用者所在组织是否拥有交易权限
+	err := s.checkCapability(ctx, "生成交易", CAP_TRADING)
 	if err != nil {
-		return fmt.Errorf("获取调用者身份失败：%v", err)
+		return err
 	}
 
-	// 验证是否是交易平台组织的成员
-	if clientMSPID != TRADE_ORG_MSPID {
-		return fmt.Errorf("只有交易平台组织成员才能生成交易")
+	// 以账本交易时间作为创建时间
+	createTime, err := s.getTxTime(ctx)
+	if err != nil {
+		return err
 	}
 
+	// 读取私有数据
+	privateInput, err := s.getTransactionPrivateInput(ctx)
+	if err != nil {
+		return err
+	}
+	buyer := privateInput.Buyer
+
 	// 参数验证
 	if len(txID) == 0 {
 		return fmt.Errorf("交易ID不能为空")
@@ -223,273 +649,659 @@ func (s *SmartContract) CreateTransaction(ctx contractapi.TransactionContextInte
 	if seller == buyer {
 		return fmt.Errorf("买家和卖家不能是同一人")
 	}
-	if price <= 0 {
-		return fmt.Errorf("价格必须大于0")
+	if share < 0 || share > FULL_SHARE {
+		return fmt.Errorf("出售份额必须在0到%d之间", FULL_SHARE)
+	}
+	priceMoney, err := parseMoney(privateInput.Price, privateInput.Currency)
+	if err != nil {
+		return fmt.Errorf("价格无效：%v", err)
 	}
 
-	// 查询房产信息
-	realEstateKey, err := s.getCompositeKey(ctx, REAL_ESTATE, []string{string(NORMAL), realEstateID})
+	// 检查买家和卖家均已登记为交易方
+	err = s.checkPartiesRegistered(ctx, seller, buyer)
 	if err != nil {
 		return err
 	}
 
-	var realEstate RealEstate
-	err = s.getState(ctx, realEstateKey, &realEstate)
+	return s.createTransaction(ctx, txID, realEstateID, seller, share, &TransactionPrivateDetails{
+		TxID:  txID,
+		Buyer: buyer,
+		Price: priceMoney,
+		Salt:  privateInput.Salt,
+	}, createTime)
+}
+
+// 通用方法：生成交易草稿（校验房产和卖家份额，保存交易私有数据，不修改房产）
+func (s *SmartContract) createTransaction(ctx contractapi.TransactionContextInterface, txID string, realEstateID string, seller string, share int, privateDetails *TransactionPrivateDetails, createTime time.Time) error {
+	// 检查交易是否已存在
+	txKey, err := s.getCompositeKey(ctx, TRANSACTION, []string{txID})
+	if err != nil {
+		return err
+	}
+
+	exists, err := ctx.GetStub().GetState(txKey)
+	if err != nil {
+		return fmt.Errorf("查询交易信息失败：%v", err)
+	}
+	if exists != nil {
+		return fmt.Errorf("交易ID %s 已存在", txID)
+	}
+
+	// 查询房产信息并检查卖家持有的份额（确认草稿时重新检查）
+	_, share, err = s.getSellableRealEstate(ctx, realEstateID, seller, share)
 	if err != nil {
 		return err
 	}
 
-	// 检查卖家是否是房产所有者
-	if realEstate.CurrentOwner != seller {
-		return fmt.Errorf("卖家不是房产所有者")
+	// 检查抵押权人是否同意转让
+	err = s.checkMortgageConsent(ctx, realEstateID)
+	if err != nil {
+		return err
 	}
 
 	// 生成交易信息
+	privateDataHash, err := s.putTransactionPrivateDetails(ctx, privateDetails)
+	if err != nil {
+		return err
+	}
+
+	// 买家在过户前不公开，交易方索引只包含卖家，买家索引写入私有数据集合
+	err = s.putPrivateIndex(ctx, TRANSACTION_BUYER_INDEX, []string{privateDetails.Buyer, txID})
+	if err != nil {
+		return err
+	}
+
 	transaction := Transaction{
-		ID:           txID,
-		RealEstateID: realEstateID,
-		Seller:       seller,
-		Buyer:        buyer,
-		Price:        price,
-		Status:       PENDING,
-		CreateTime:   createTime,
-		UpdateTime:   createTime,
+		ID:              txID,
+		RealEstateID:    realEstateID,
+		Seller:          seller,
+		Share:           share,
+		PrivateDataHash: privateDataHash,
+		Status:          DRAFT,
+		ExpireTime:      createTime.Add(TRANSACTION_VALIDITY),
+		CreateTime:      createTime,
+		UpdateTime:      createTime,
 	}
 
-	// 更新房产状态
+	// 保存状态
+	err = s.putTransaction(ctx, &transaction, "")
+	if err != nil {
+		return err
+	}
+
+	return s.putPartyIndex(ctx, &transaction)
+}
+
+// OpenTransaction 确认交易草稿，房产转为交易中（仅拥有交易权限的组织可以调用，需要登记机构背书）
+//
+// 不读取私有数据、不使用 transient map：登记机构的节点只通过私有数据哈希确认草稿的私有数据已保存，再重新检查房产和抵押
+func (s *SmartContract) OpenTransaction(ctx contractapi.TransactionContextInterface, txID string) error {
+	// 验证调用者所在组织是否拥有交易权限
+	err := s.checkCapability(ctx, "确认交易", CAP_TRADING)
+	if err != nil {
+		return err
+	}
+
+	// 以账本交易时间作为更新时间
+	updateTime, err := s.getTxTime(ctx)
+	if err != nil {
+		return err
+	}
+
+	// 查询交易信息
+	transaction, err := s.getTransaction(ctx, txID)
+	if err != nil {
+		return err
+	}
+	if transaction.Status != DRAFT {
+		return fmt.Errorf("交易 %s 当前状态为 %s，不是待确认的交易草稿", txID, transaction.Status)
+	}
+	if updateTime.After(transaction.ExpireTime) {
+		return fmt.Errorf("交易草稿 %s 已于 %s 过期", txID, transaction.ExpireTime.Format(time.RFC3339))
+	}
+
+	err = s.checkTransactionPrivateDataHash(ctx, transaction)
+	if err != nil {
+		return err
+	}
+
+	// 重新检查房产和卖家持有的份额（生成草稿后房产可能已进入其他交易）
+	realEstate, _, err := s.getSellableRealEstate(ctx, transaction.RealEstateID, transaction.Seller, transaction.Share)
+	if err != nil {
+		return err
+	}
+
+	// 检查抵押权人是否同意转让
+	err = s.checkMortgageConsent(ctx, transaction.RealEstateID)
+	if err != nil {
+		return err
+	}
+
+	// 更新状态
+	transaction.Status = PENDING
+	transaction.UpdateTime = updateTime
+
 	realEstate.Status = IN_TRANSACTION
-	realEstate.UpdateTime = createTime
+	realEstate.UpdateTime = updateTime
 
 	// 保存状态
-	txKey, err := s.getCompositeKey(ctx, TRANSACTION, []string{string(PENDING), txID})
+	err = s.putTransaction(ctx, transaction, DRAFT)
 	if err != nil {
 		return err
 	}
 
-	// 删除旧的房产记录
-	err = ctx.GetStub().DelState(realEstateKey)
+	err = s.putRealEstate(ctx, realEstate, NORMAL)
 	if err != nil {
-		return fmt.Errorf("删除旧的房产记录失败：%v", err)
+		return err
+	}
+
+	return s.setEvent(ctx, EVENT_TRANSACTION_CREATED, txID, updateTime, transaction)
+}
+
+// 通用方法：获取可以出售的房产，检查房产未注销、未冻结、不在交易中且卖家持有足够份额，返回房产和实际出售份额（share 为0时为卖家持有的全部份额）
+func (s *SmartContract) getSellableRealEstate(ctx contractapi.TransactionContextInterface, realEstateID string, seller string, share int) (*RealEstate, int, error) {
+	realEstate, err := s.getRealEstate(ctx, realEstateID)
+	if err != nil {
+		return nil, 0, err
 	}
 
-	// 创建新的房产记录（使用新状态）
-	newRealEstateKey, err := s.getCompositeKey(ctx, REAL_ESTATE, []string{string(IN_TRANSACTION), realEstateID})
+	err = s.checkNotRetired(realEstate, "交易")
+	if err != nil {
+		return nil, 0, err
+	}
+	err = s.checkNotFrozen(ctx, realEstate, "交易")
+	if err != nil {
+		return nil, 0, err
+	}
+	if realEstate.Status != NORMAL {
+		return nil, 0, fmt.Errorf("房产 %s 当前状态为 %s，无法交易", realEstateID, realEstate.Status)
+	}
+
+	holding := realEstate.ownerShare(seller)
+	if holding == 0 {
+		return nil, 0, fmt.Errorf("卖家不是房产所有者")
+	}
+	if share == 0 {
+		share = holding
+	}
+	if share > holding {
+		return nil, 0, fmt.Errorf("卖家持有份额 %d 不足以出售份额 %d", holding, share)
+	}
+	return realEstate, share, nil
+}
+
+// SettleTransaction 结算已付清的交易（仅拥有结算权限的组织可以调用，只由私有数据集合成员背书）
+//
+// 重新验证买卖双方对交易条款的签署，按当前税费标准计算税费，公开买家并记录签署公钥的指纹，交易转为已结算；
+// 再由 CompleteTransaction 经登记机构背书完成过户，登记机构的节点不需要读取私有数据
+func (s *SmartContract) SettleTransaction(ctx contractapi.TransactionContextInterface, txID string) error {
+	// 验证调用者所在组织是否拥有结算权限
+	err := s.checkCapability(ctx, "结算交易", CAP_SETTLEMENT)
 	if err != nil {
 		return err
 	}
 
-	err = s.putState(ctx, txKey, transaction)
+	// 以账本交易时间作为更新时间
+	updateTime, err := s.getTxTime(ctx)
 	if err != nil {
 		return err
 	}
 
-	err = s.putState(ctx, newRealEstateKey, realEstate)
+	// 查询交易信息（结算后更换公钥的交易方重新签署后可以再次结算）
+	transaction, err := s.getOpenTransaction(ctx, txID)
 	if err != nil {
 		return err
 	}
+	if len(transaction.PrivateDataHash) == 0 {
+		return fmt.Errorf("交易 %s 没有私有数据，无需结算", txID)
+	}
+	if transaction.Status != PAID && transaction.Status != SETTLED {
+		return fmt.Errorf("交易 %s 尚未付清，当前状态为 %s", txID, transaction.Status)
+	}
 
-	return nil
+	privateDetails, err := s.getTransactionPrivateDetails(ctx, txID)
+	if err != nil {
+		return err
+	}
+	if privateDetails == nil {
+		return fmt.Errorf("交易 %s 没有私有数据，无法结算", txID)
+	}
+
+	// 重新验证买卖双方的签署（付清后交易方可能已更换公钥）
+	err = s.checkTransactionApprovals(ctx, transaction, privateDetails)
+	if err != nil {
+		return fmt.Errorf("无法结算交易：%v", err)
+	}
+
+	signerKeys, err := s.getSignerKeyFingerprints(ctx, transaction.Seller, privateDetails.Buyer)
+	if err != nil {
+		return err
+	}
+
+	// 按当前税费标准计算契税、印花税等税费
+	err = s.recordTransactionFees(ctx, transaction, privateDetails, updateTime)
+	if err != nil {
+		return err
+	}
+
+	// 过户时买家公开
+	oldStatus := transaction.Status
+	transaction.Buyer = privateDetails.Buyer
+	transaction.SignerKeys = signerKeys
+	transaction.Status = SETTLED
+	transaction.UpdateTime = updateTime
+	return s.putTransaction(ctx, transaction, oldStatus)
 }
 
-// CompleteTransaction 完成交易（仅银行组织可以调用）
-func (s *SmartContract) CompleteTransaction(ctx contractapi.TransactionContextInterface, txID string, updateTime time.Time) error {
-	// 检查调用者身份
-	clientMSPID, err := s.getClientIdentityMSPID(ctx)
+// CompleteTransaction 完成交易（仅拥有结算权限的组织可以调用，需要登记机构背书，交易必须已结算）
+//
+// 不读取私有数据、不使用 transient map：买家和签署公钥指纹已在结算时公开，过户前确认买卖双方未更换公钥
+func (s *SmartContract) CompleteTransaction(ctx contractapi.TransactionContextInterface, txID string) error {
+	// 验证调用者所在组织是否拥有结算权限
+	err := s.checkCapability(ctx, "完成交易", CAP_SETTLEMENT)
 	if err != nil {
-		return fmt.Errorf("获取调用者身份失败：%v", err)
+		return err
 	}
 
-	// 验证是否是银行组织的成员
-	if clientMSPID != BANK_ORG_MSPID {
-		return fmt.Errorf("只有银行组织成员才能完成交易")
+	// 以账本交易时间作为更新时间
+	updateTime, err := s.getTxTime(ctx)
+	if err != nil {
+		return err
 	}
 
 	// 查询交易信息
-	txKey, err := s.getCompositeKey(ctx, TRANSACTION, []string{string(PENDING), txID})
+	transaction, err := s.getOpenTransaction(ctx, txID)
 	if err != nil {
 		return err
 	}
 
-	var transaction Transaction
-	err = s.getState(ctx, txKey, &transaction)
+	// 旧版本交易的买家和价格保存在公开状态中，没有签署、托管付款和税费记录，不能直接过户
+	if len(transaction.PrivateDataHash) == 0 {
+		return fmt.Errorf("交易 %s 是旧版本生成的交易，没有买卖双方的签署和付款记录，需要取消后重新生成", txID)
+	}
+	if transaction.Status != SETTLED {
+		return fmt.Errorf("交易 %s 尚未结算，当前状态为 %s", txID, transaction.Status)
+	}
+
+	signerKeys, err := s.getSignerKeyFingerprints(ctx, transaction.Seller, transaction.Buyer)
 	if err != nil {
 		return err
 	}
+	if !slices.Equal(signerKeys, transaction.SignerKeys) {
+		return fmt.Errorf("交易 %s 的买卖双方在结算后更换了公钥，需要重新签署并结算", txID)
+	}
+
+	return s.completeTransaction(ctx, transaction, updateTime)
+}
+
+// 通用方法：完成交易（处理已同意转让的抵押，转移份额，交易转为已完成，房产恢复正常状态）
+func (s *SmartContract) completeTransaction(ctx contractapi.TransactionContextInterface, transaction *Transaction, updateTime time.Time) error {
+	if len(transaction.Buyer) == 0 {
+		return fmt.Errorf("交易 %s 缺少买家信息", transaction.ID)
+	}
 
 	// 查询房产信息
-	realEstateKey, err := s.getCompositeKey(ctx, REAL_ESTATE, []string{string(IN_TRANSACTION), transaction.RealEstateID})
+	realEstate, err := s.getRealEstate(ctx, transaction.RealEstateID)
 	if err != nil {
 		return err
 	}
 
-	var realEstate RealEstate
-	err = s.getState(ctx, realEstateKey, &realEstate)
+	// 交易期间被冻结的房产不能完成交易
+	err = s.checkNotFrozen(ctx, realEstate, "完成交易")
+	if err != nil {
+		return err
+	}
+
+	// 转让全部份额时注销抵押权人已同意转让的抵押，部分转让时收回同意
+	err = s.releaseConsentedMortgages(ctx, transaction.RealEstateID, transaction.Share, updateTime)
+	if err != nil {
+		return err
+	}
+
+	// 转移份额
+	oldOwners := realEstate.Owners
+	err = realEstate.transferShare(transaction.Seller, transaction.Buyer, transaction.Share)
+	if err != nil {
+		return err
+	}
+
+	err = s.updateOwnerIndex(ctx, realEstate.ID, oldOwners, realEstate.Owners)
+	if err != nil {
+		return err
+	}
+
+	// 所有权变更后买家公开，补充买家的交易方索引
+	err = s.putPartyIndex(ctx, transaction)
 	if err != nil {
 		return err
 	}
 
 	// 更新状态
-	realEstate.CurrentOwner = transaction.Buyer
+	oldRealEstateStatus := realEstate.Status
 	realEstate.Status = NORMAL
 	realEstate.UpdateTime = updateTime
 
+	oldStatus := transaction.Status
 	transaction.Status = COMPLETED
 	transaction.UpdateTime = updateTime
 
-	// 删除旧记录
-	err = ctx.GetStub().DelState(txKey)
+	// 保存状态
+	err = s.putTransaction(ctx, transaction, oldStatus)
 	if err != nil {
-		return fmt.Errorf("删除旧的交易记录失败：%v", err)
+		return err
 	}
 
-	err = ctx.GetStub().DelState(realEstateKey)
+	err = s.putRealEstate(ctx, realEstate, oldRealEstateStatus)
 	if err != nil {
-		return fmt.Errorf("删除旧的房产记录失败：%v", err)
+		return err
 	}
 
-	// 创建新记录
-	newTxKey, err := s.getCompositeKey(ctx, TRANSACTION, []string{string(COMPLETED), txID})
+	return s.setEvent(ctx, EVENT_TRANSACTION_COMPLETED, transaction.ID, updateTime, transaction)
+}
+
+// CancelTransaction 取消交易（拥有交易或结算权限的组织可以调用）
+func (s *SmartContract) CancelTransaction(ctx contractapi.TransactionContextInterface, txID string, reason string) error {
+	// 验证调用者所在组织是否拥有交易或结算权限
+	err := s.checkCapability(ctx, "取消交易", CAP_TRADING, CAP_SETTLEMENT)
 	if err != nil {
 		return err
 	}
 
-	newRealEstateKey, err := s.getCompositeKey(ctx, REAL_ESTATE, []string{string(NORMAL), transaction.RealEstateID})
+	// 以账本交易时间作为更新时间
+	updateTime, err := s.getTxTime(ctx)
 	if err != nil {
 		return err
 	}
 
-	err = s.putState(ctx, newTxKey, transaction)
+	// 参数验证
+	if len(reason) == 0 {
+		return fmt.Errorf("取消原因不能为空")
+	}
+
+	// 查询交易信息（只有未完成的交易可以取消，已托管的款项由银行线下退还）
+	transaction, err := s.getOpenTransaction(ctx, txID)
 	if err != nil {
 		return err
 	}
 
-	err = s.putState(ctx, newRealEstateKey, realEstate)
+	transaction.CancelReason = reason
+	return s.closeOpenTransaction(ctx, transaction, CANCELLED, updateTime)
+}
+
+// ExpireTransaction 使超过有效期的交易过期（任何组织都可以调用，旧版本生成的没有有效期的交易不会过期）
+func (s *SmartContract) ExpireTransaction(ctx contractapi.TransactionContextInterface, txID string) error {
+	// 查询交易信息（只有交易草稿和待付款的交易可以过期，已付款的交易只能取消）
+	transaction, err := s.getOpenTransaction(ctx, txID)
 	if err != nil {
 		return err
 	}
+	if transaction.Status != DRAFT && transaction.Status != PENDING {
+		return fmt.Errorf("交易 %s 已付款，不会过期", txID)
+	}
+	// 旧版本生成的交易没有有效期，只能取消
+	if transaction.ExpireTime.IsZero() {
+		return fmt.Errorf("交易 %s 没有有效期，不会过期", txID)
+	}
 
-	return nil
+	// 以账本交易时间为准判断是否已过期
+	now, err := s.getTxTime(ctx)
+	if err != nil {
+		return err
+	}
+
+	if !now.After(transaction.ExpireTime) {
+		return fmt.Errorf("交易 %s 尚未过期，有效期至 %s", txID, transaction.ExpireTime.Format(time.RFC3339))
+	}
+
+	return s.closeOpenTransaction(ctx, transaction, EXPIRED, now)
 }
 
-// QueryRealEstate 查询房产信息
-func (s *SmartContract) QueryRealEstate(ctx contractapi.TransactionContextInterface, id string) (*RealEstate, error) {
-	// 遍历所有可能的状态查询房产
-	for _, status := range []RealEstateStatus{NORMAL, IN_TRANSACTION} {
-		key, err := s.getCompositeKey(ctx, REAL_ESTATE, []string{string(status), id})
-		if err != nil {
-			return nil, fmt.Errorf("创建复合键失败：%v", err)
-		}
+// 通用方法：结束未完成的交易（交易转为指定状态，房产恢复正常状态，所有者不变；冻结中的房产在冻结结束后恢复正常状态）
+func (s *SmartContract) closeOpenTransaction(ctx contractapi.TransactionContextInterface, transaction *Transaction, status TransactionStatus, updateTime time.Time) error {
+	oldStatus := transaction.Status
+	transaction.Status = status
+	transaction.UpdateTime = updateTime
+
+	// 保存状态
+	err := s.putTransaction(ctx, transaction, oldStatus)
+	if err != nil {
+		return err
+	}
 
-		bytes, err := ctx.GetStub().GetState(key)
+	// 交易草稿尚未修改房产状态
+	if oldStatus == DRAFT {
+		return nil
+	}
+
+	// 查询房产信息
+	realEstate, err := s.getRealEstate(ctx, transaction.RealEstateID)
+	if err != nil {
+		return err
+	}
+
+	// 冻结中的房产保持冻结，冻结结束后恢复正常状态
+	if realEstate.Status == FROZEN {
+		freeze, err := s.getActiveFreeze(ctx, realEstate.ID)
 		if err != nil {
-			return nil, fmt.Errorf("查询房产信息失败：%v", err)
-		}
-		if bytes != nil {
-			var realEstate RealEstate
-			err = json.Unmarshal(bytes, &realEstate)
-			if err != nil {
-				return nil, fmt.Errorf("解析房产信息失败：%v", err)
-			}
-			return &realEstate, nil
+			return err
 		}
+		freeze.PreviousStatus = NORMAL
+		freeze.UpdateTime = updateTime
+		return s.putFreeze(ctx, freeze, FREEZE_ACTIVE)
+	}
+
+	// 更新房产状态
+	oldRealEstateStatus := realEstate.Status
+	realEstate.Status = NORMAL
+	realEstate.UpdateTime = updateTime
+
+	return s.putRealEstate(ctx, realEstate, oldRealEstateStatus)
+}
+
+// QueryRealEstate 查询房产信息（包含司法冻结历史）
+func (s *SmartContract) QueryRealEstate(ctx contractapi.TransactionContextInterface, id string) (*RealEstate, error) {
+	realEstate, err := s.getRealEstate(ctx, id)
+	if err != nil {
+		return nil, err
 	}
 
-	return nil, fmt.Errorf("房产ID %s 不存在", id)
+	freezes, err := s.getFreezeHistory(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(freezes) > 0 {
+		realEstate.Freezes = freezes
+	}
+	return realEstate, nil
 }
 
-// QueryTransaction 查询交易信息
+// QueryTransaction 查询交易信息（拥有结算或交易权限的组织查询已完成的交易时同时返回税费明细）
 func (s *SmartContract) QueryTransaction(ctx contractapi.TransactionContextInterface, txID string) (*Transaction, error) {
-	// 遍历所有可能的状态查询交易
-	for _, status := range []TransactionStatus{PENDING, COMPLETED} {
-		key, err := s.getCompositeKey(ctx, TRANSACTION, []string{string(status), txID})
+	transaction, err := s.getTransaction(ctx, txID)
+	if err != nil {
+		return nil, err
+	}
+	if transaction.Status != COMPLETED || len(transaction.PrivateDataHash) == 0 {
+		return transaction, nil
+	}
+
+	// 税费金额可以推算成交价格，只向拥有结算或交易权限（私有数据集合成员）的组织返回
+	_, ok, err := s.hasCapability(ctx, CAP_SETTLEMENT, CAP_TRADING)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return transaction, nil
+	}
+
+	fees, err := s.getTransactionFees(ctx, txID)
+	if err != nil {
+		return nil, err
+	}
+	if len(fees) > 0 {
+		transaction.Fees = fees
+	}
+	return transaction, nil
+}
+
+// QueryRealEstateHistory 查询房产历史记录（合并迁移前各状态复合键上的变更）
+func (s *SmartContract) QueryRealEstateHistory(ctx contractapi.TransactionContextInterface, id string) ([]*HistoryRecord, error) {
+	return s.getHistory(ctx, REAL_ESTATE, id, legacyRealEstateStatuses, decodeRealEstate)
+}
+
+// QueryTransactionHistory 查询交易历史记录（合并迁移前各状态复合键上的变更）
+func (s *SmartContract) QueryTransactionHistory(ctx contractapi.TransactionContextInterface, txID string) ([]*HistoryRecord, error) {
+	return s.getHistory(ctx, TRANSACTION, txID, legacyTransactionStatuses, decodeTransaction)
+}
+
+// 通用方法：查询ID主键及迁移前各状态复合键上的历史，按时间排序合并为一条时间线
+func (s *SmartContract) getHistory(ctx contractapi.TransactionContextInterface, objectType string, id string, legacyStatuses []string, decode func([]byte) (interface{}, error)) ([]*HistoryRecord, error) {
+	if len(id) == 0 {
+		return nil, fmt.Errorf("ID不能为空")
+	}
+
+	// 主键在前，迁移前的状态复合键在后
+	keyAttributes := [][]string{{id}}
+	for _, status := range legacyStatuses {
+		keyAttributes = append(keyAttributes, []string{status, id})
+	}
+
+	records := make([]*HistoryRecord, 0)
+	for _, attributes := range keyAttributes {
+		key, err := s.getCompositeKey(ctx, objectType, attributes)
 		if err != nil {
-			return nil, fmt.Errorf("创建复合键失败：%v", err)
+			return nil, err
 		}
 
-		bytes, err := ctx.GetStub().GetState(key)
+		iterator, err := ctx.GetStub().GetHistoryForKey(key)
 		if err != nil {
-			return nil, fmt.Errorf("查询交易信息失败：%v", err)
+			return nil, fmt.Errorf("查询历史记录失败：%v", err)
 		}
-		if bytes != nil {
-			var transaction Transaction
-			err = json.Unmarshal(bytes, &transaction)
+
+		for iterator.HasNext() {
+			modification, err := iterator.Next()
 			if err != nil {
-				return nil, fmt.Errorf("解析交易信息失败：%v", err)
+				iterator.Close()
+				return nil, fmt.Errorf("获取下一条历史记录失败：%v", err)
+			}
+
+			record := &HistoryRecord{
+				TxID:      modification.TxId,
+				Timestamp: modification.Timestamp.AsTime(),
+				IsDelete:  modification.IsDelete,
+			}
+			if len(attributes) > 1 {
+				record.Status = attributes[0]
+			}
+			if !modification.IsDelete {
+				record.Value, err = decode(modification.Value)
+				if err != nil {
+					iterator.Close()
+					return nil, err
+				}
+
+				var status struct {
+					Status string `json:"status"`
+				}
+				if err := json.Unmarshal(modification.Value, &status); err == nil {
+					record.Status = status.Status
+				}
 			}
-			return &transaction, nil
+
+			records = append(records, record)
 		}
+		iterator.Close()
+	}
+
+	if len(records) == 0 {
+		return nil, fmt.Errorf("ID %s 不存在历史记录", id)
 	}
 
-	return nil, fmt.Errorf("交易ID %s 不存在", txID)
+	// 按时间排序，同一交易中先删除旧记录再写入新记录
+	sort.SliceStable(records, func(i, j int) bool {
+		if !records[i].Timestamp.Equal(records[j].Timestamp) {
+			return records[i].Timestamp.Before(records[j].Timestamp)
+		}
+		return records[i].IsDelete && !records[j].IsDelete
+	})
+
+	return records, nil
 }
 
 // QueryRealEstateList 分页查询房产列表
+//
+// 未指定状态时不返回已注销的房产，跳过已注销的房产后继续读取直到凑满 pageSize 条或没有更多记录；查询已注销的房产需要指定状态 RETIRED
 func (s *SmartContract) QueryRealEstateList(ctx contractapi.TransactionContextInterface, pageSize int32, bookmark string, status string) (*QueryResult, error) {
-	var iterator shim.StateQueryIteratorInterface
-	var metadata *peer.QueryResponseMetadata
-	var err error
-
 	if status != "" {
-		iterator, metadata, err = ctx.GetStub().GetStateByPartialCompositeKeyWithPagination(
-			REAL_ESTATE,
-			[]string{status},
-			pageSize,
-			bookmark,
-		)
-	} else {
-		iterator, metadata, err = ctx.GetStub().GetStateByPartialCompositeKeyWithPagination(
-			REAL_ESTATE,
-			[]string{},
-			pageSize,
-			bookmark,
-		)
-	}
-
-	if err != nil {
-		return nil, fmt.Errorf("查询列表失败：%v", err)
+		return s.queryPage(ctx, REAL_ESTATE, REAL_ESTATE_STATUS_INDEX, []string{status}, pageSize, bookmark, decodeRealEstate)
 	}
-	defer iterator.Close()
 
-	records := make([]interface{}, 0)
-	for iterator.HasNext() {
-		queryResponse, err := iterator.Next()
+	page := &QueryResult{Records: make([]interface{}, 0), Bookmark: bookmark}
+	for {
+		// 每次只读取还差的条数，使返回的书签正好指向本页最后一条记录之后
+		remaining := pageSize - page.RecordsCount
+		result, err := s.queryPage(ctx, REAL_ESTATE, "", nil, remaining, page.Bookmark, decodeRealEstate)
 		if err != nil {
-			return nil, fmt.Errorf("获取下一条记录失败：%v", err)
+			return nil, err
 		}
 
-		var realEstate RealEstate
-		err = json.Unmarshal(queryResponse.Value, &realEstate)
-		if err != nil {
-			return nil, fmt.Errorf("解析房产信息失败：%v", err)
+		for _, record := range result.Records {
+			if record.(RealEstate).Status == RETIRED {
+				continue
+			}
+			page.Records = append(page.Records, record)
 		}
+		page.RecordsCount = int32(len(page.Records))
+		page.Bookmark = result.Bookmark
+		page.FetchedRecordsCount += result.FetchedRecordsCount
 
-		records = append(records, realEstate)
+		// 本页已满、已读完所有记录或未指定分页大小时结束
+		if pageSize <= 0 || page.RecordsCount >= pageSize || result.Bookmark == "" || result.FetchedRecordsCount < remaining {
+			return page, nil
+		}
 	}
-
-	return &QueryResult{
-		Records:             records,
-		RecordsCount:        int32(len(records)),
-		Bookmark:            metadata.Bookmark,
-		FetchedRecordsCount: metadata.FetchedRecordsCount,
-	}, nil
 }
 
 // QueryTransactionList 分页查询交易列表
 func (s *SmartContract) QueryTransactionList(ctx contractapi.TransactionContextInterface, pageSize int32, bookmark string, status string) (*QueryResult, error) {
+	if status != "" {
+		return s.queryPage(ctx, TRANSACTION, TRANSACTION_STATUS_INDEX, []string{status}, pageSize, bookmark, decodeTransaction)
+	}
+	return s.queryPage(ctx, TRANSACTION, "", nil, pageSize, bookmark, decodeTransaction)
+}
+
+// QueryRealEstateByOwner 分页查询所有者持有的房产列表
+func (s *SmartContract) QueryRealEstateByOwner(ctx contractapi.TransactionContextInterface, owner string, pageSize int32, bookmark string) (*QueryResult, error) {
+	if len(owner) == 0 {
+		return nil, fmt.Errorf("所有者不能为空")
+	}
+	return s.queryPage(ctx, REAL_ESTATE, REAL_ESTATE_OWNER_INDEX, []string{owner}, pageSize, bookmark, decodeRealEstate)
+}
+
+// QueryTransactionsByParty 分页查询交易方（买家或卖家）参与的交易列表
+func (s *SmartContract) QueryTransactionsByParty(ctx contractapi.TransactionContextInterface, party string, pageSize int32, bookmark string) (*QueryResult, error) {
+	if len(party) == 0 {
+		return nil, fmt.Errorf("交易方不能为空")
+	}
+	return s.queryPage(ctx, TRANSACTION, TRANSACTION_PARTY_INDEX, []string{party}, pageSize, bookmark, decodeTransaction)
+}
+
+// 通用方法：分页查询列表（指定索引时遍历索引键并按ID读取主键数据，否则遍历主键）
+func (s *SmartContract) queryPage(ctx contractapi.TransactionContextInterface, objectType string, indexName string, indexAttributes []string, pageSize int32, bookmark string, decode func([]byte) (interface{}, error)) (*QueryResult, error) {
 	var iterator shim.StateQueryIteratorInterface
 	var metadata *peer.QueryResponseMetadata
 	var err error
 
-	if status != "" {
+	if indexName != "" {
 		iterator, metadata, err = ctx.GetStub().GetStateByPartialCompositeKeyWithPagination(
-			TRANSACTION,
-			[]string{status},
+			indexName,
+			indexAttributes,
 			pageSize,
 			bookmark,
 		)
 	} else {
 		iterator, metadata, err = ctx.GetStub().GetStateByPartialCompositeKeyWithPagination(
-			TRANSACTION,
+			objectType,
 			[]string{},
 			pageSize,
 			bookmark,
@@ -508,13 +1320,36 @@ func (s *SmartContract) QueryTransactionList(ctx contractapi.TransactionContextI
 			return nil, fmt.Errorf("获取下一条记录失败：%v", err)
 		}
 
-		var transaction Transaction
-		err = json.Unmarshal(queryResponse.Value, &transaction)
+		_, attributes, err := ctx.GetStub().SplitCompositeKey(queryResponse.Key)
 		if err != nil {
-			return nil, fmt.Errorf("解析交易信息失败：%v", err)
+			return nil, fmt.Errorf("解析复合键失败：%v", err)
 		}
 
-		records = append(records, transaction)
+		value := queryResponse.Value
+		if indexName != "" {
+			// 索引键的最后一个属性为ID，按ID读取主键数据
+			key, err := s.getCompositeKey(ctx, objectType, []string{attributes[len(attributes)-1]})
+			if err != nil {
+				return nil, err
+			}
+			value, err = ctx.GetStub().GetState(key)
+			if err != nil {
+				return nil, fmt.Errorf("读取状态失败：%v", err)
+			}
+			if value == nil {
+				continue
+			}
+		} else if len(attributes) != 1 {
+			// 跳过尚未迁移的旧格式键（类型_状态_ID）
+			continue
+		}
+
+		record, err := decode(value)
+		if err != nil {
+			return nil, err
+		}
+
+		records = append(records, record)
 	}
 
 	return &QueryResult{
@@ -537,12 +1372,17 @@ func (s *SmartContract) InitLedger(ctx contractapi.TransactionContextInterface)
 }
 
 func main() {
-	chaincode, err := contractapi.NewChaincode(&SmartContract{})
+	// 每个合约函数执行前检查调用者角色
+	contract := &SmartContract{}
+	contract.BeforeTransaction = contract.checkFunctionRoles
+
+	chaincode, err := contractapi.NewChaincode(contract)
 	if err != nil {
 		log.Panicf("创建智能合约失败：%v", err)
 	}
 
-	if err := chaincode.Start(); err != nil {
+	// 使用兼容包装启动，忽略旧版本客户端多传的时间参数
+	if err := shim.Start(&compatChaincode{chaincode}); err != nil {
 		log.Panicf("启动智能合约失败：%v", err)
 	}
 }
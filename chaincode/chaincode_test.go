@@ -0,0 +1,336 @@
+package main
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestTransferShare(t *testing.T) {
+	tests := []struct {
+		name    string
+		owners  []Owner
+		seller  string
+		buyer   string
+		share   int
+		want    []Owner
+		wantErr bool
+	}{
+		{
+			name:   "出售全部份额",
+			owners: []Owner{{ID: "S", Share: FULL_SHARE}},
+			seller: "S", buyer: "B", share: FULL_SHARE,
+			want: []Owner{{ID: "B", Share: FULL_SHARE}},
+		},
+		{
+			name:   "出售部分份额",
+			owners: []Owner{{ID: "S", Share: FULL_SHARE}},
+			seller: "S", buyer: "B", share: 2500,
+			want: []Owner{{ID: "S", Share: 7500}, {ID: "B", Share: 2500}},
+		},
+		{
+			name:   "买家已是共有人时合并份额",
+			owners: []Owner{{ID: "S", Share: 6000}, {ID: "B", Share: 4000}},
+			seller: "S", buyer: "B", share: 1000,
+			want: []Owner{{ID: "S", Share: 5000}, {ID: "B", Share: 5000}},
+		},
+		{
+			name:   "卖家份额清零后移出所有者列表",
+			owners: []Owner{{ID: "A", Share: 3000}, {ID: "S", Share: 7000}},
+			seller: "S", buyer: "B", share: 7000,
+			want: []Owner{{ID: "A", Share: 3000}, {ID: "B", Share: 7000}},
+		},
+		{
+			name:   "卖家份额不足",
+			owners: []Owner{{ID: "S", Share: 5000}, {ID: "A", Share: 5000}},
+			seller: "S", buyer: "B", share: 5001,
+			wantErr: true,
+		},
+		{
+			name:   "卖家不是所有者",
+			owners: []Owner{{ID: "A", Share: FULL_SHARE}},
+			seller: "S", buyer: "B", share: 1,
+			wantErr: true,
+		},
+		{
+			name:   "份额为0",
+			owners: []Owner{{ID: "S", Share: FULL_SHARE}},
+			seller: "S", buyer: "B", share: 0,
+			wantErr: true,
+		},
+		{
+			name:   "份额为负数",
+			owners: []Owner{{ID: "S", Share: 5000}, {ID: "B", Share: 5000}},
+			seller: "S", buyer: "B", share: -1000,
+			wantErr: true,
+		},
+	}
+
+	s := &SmartContract{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			realEstate := &RealEstate{Owners: append([]Owner(nil), tt.owners...)}
+			err := realEstate.transferShare(tt.seller, tt.buyer, tt.share)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("错误 = %v，期望出错 = %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				// 失败时不修改所有者列表
+				if !reflect.DeepEqual(realEstate.Owners, tt.owners) {
+					t.Fatalf("转移失败后所有者 = %+v，期望保持 %+v", realEstate.Owners, tt.owners)
+				}
+				return
+			}
+			if !reflect.DeepEqual(realEstate.Owners, tt.want) {
+				t.Fatalf("所有者 = %+v，期望 %+v", realEstate.Owners, tt.want)
+			}
+			// 转移后的份额之和仍等于全部份额
+			if err := s.validateOwners(realEstate.Owners); err != nil {
+				t.Fatalf("转移后的所有者列表无效：%v", err)
+			}
+		})
+	}
+}
+
+func TestTransactionLifecycleWithoutRegistryPrivateData(t *testing.T) {
+	s := &SmartContract{}
+	stub := newMockStub()
+	ctx := newTestContext(stub)
+
+	sellerKey := registerTestSigner(t, s, stub, "S")
+	buyerKey := registerTestSigner(t, s, stub, "B")
+	stub.setCaller(t, REALTY_ORG_MSPID, "client", nil)
+	err := s.CreateRealEstate(ctx, "R1", "北京市朝阳区", "89.50", string(RESIDENTIAL), []Owner{{ID: "S", Share: FULL_SHARE}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	checkStatus := func(want TransactionStatus, wantRealEstate RealEstateStatus) {
+		t.Helper()
+		transaction, err := s.getTransaction(ctx, "T1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		realEstate, err := s.getRealEstate(ctx, "R1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if transaction.Status != want || realEstate.Status != wantRealEstate {
+			t.Fatalf("交易状态 = %s，房产状态 = %s，期望 %s、%s", transaction.Status, realEstate.Status, want, wantRealEstate)
+		}
+	}
+
+	// 集合成员背书生成交易草稿，不修改房产
+	stub.setCaller(t, TRADE_ORG_MSPID, "client", nil)
+	stub.transient = map[string][]byte{TRANSACTION_PRIVATE_TRANSIENT_KEY: []byte(`{"buyer":"B","price":"1000000","currency":"CNY","salt":"` + strings.Repeat("0", 32) + `"}`)}
+	if err := s.CreateTransaction(ctx, "T1", "R1", "S", 0); err != nil {
+		t.Fatal(err)
+	}
+	checkStatus(DRAFT, NORMAL)
+
+	// 登记机构的节点不读取私有数据、没有 transient 数据也能确认草稿
+	stub.transient = nil
+	stub.nonMember = true
+	if err := s.OpenTransaction(ctx, "T1"); err != nil {
+		t.Fatal(err)
+	}
+	checkStatus(PENDING, IN_TRANSACTION)
+	stub.nonMember = false
+
+	approveTestTransaction(t, s, stub, "T1", "S", sellerKey)
+	approveTestTransaction(t, s, stub, "T1", "B", buyerKey)
+	stub.setCaller(t, BANK_ORG_MSPID, "client", nil)
+	if err := s.RecordPayment(ctx, "T1", "1000000", "R1"); err != nil {
+		t.Fatal(err)
+	}
+	checkStatus(PAID, IN_TRANSACTION)
+
+	// 未结算的交易不能过户
+	stub.nonMember = true
+	if err := s.CompleteTransaction(ctx, "T1"); err == nil {
+		t.Fatal("未结算的交易过户应当失败")
+	}
+	stub.nonMember = false
+
+	if err := s.SettleTransaction(ctx, "T1"); err != nil {
+		t.Fatal(err)
+	}
+	checkStatus(SETTLED, IN_TRANSACTION)
+
+	// 结算后买家更换公钥，原签署不再有效，需要重新签署并结算
+	newBuyerKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKIXPublicKey(&newBuyerKey.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stub.setCaller(t, REALTY_ORG_MSPID, "client", nil)
+	err = s.RegisterPartyPublicKey(ctx, "B", string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stub.setCaller(t, BANK_ORG_MSPID, "client", nil)
+	stub.nonMember = true
+	if err := s.CompleteTransaction(ctx, "T1"); err == nil {
+		t.Fatal("买家结算后更换公钥时过户应当失败")
+	}
+	stub.nonMember = false
+	if err := s.SettleTransaction(ctx, "T1"); err == nil {
+		t.Fatal("买家更换公钥后未重新签署时结算应当失败")
+	}
+
+	approveTestTransaction(t, s, stub, "T1", "B", newBuyerKey)
+	stub.setCaller(t, BANK_ORG_MSPID, "client", nil)
+	if err := s.SettleTransaction(ctx, "T1"); err != nil {
+		t.Fatal(err)
+	}
+
+	stub.nonMember = true
+	if err := s.CompleteTransaction(ctx, "T1"); err != nil {
+		t.Fatal(err)
+	}
+	checkStatus(COMPLETED, NORMAL)
+
+	realEstate, err := s.getRealEstate(ctx, "R1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(realEstate.Owners, []Owner{{ID: "B", Share: FULL_SHARE}}) {
+		t.Fatalf("过户后所有者 = %+v，期望 B 持有全部份额", realEstate.Owners)
+	}
+}
+
+func TestQueryRealEstateListSkipsRetiredWithoutShortPages(t *testing.T) {
+	s := &SmartContract{}
+	stub := newMockStub()
+	ctx := newTestContext(stub)
+
+	registerTestSigner(t, s, stub, "S")
+	stub.setCaller(t, REALTY_ORG_MSPID, "client", nil)
+	for _, id := range []string{"R1", "R2", "R3", "R4", "R5", "R6"} {
+		err := s.CreateRealEstate(ctx, id, "北京市朝阳区", "89.50", string(RESIDENTIAL), []Owner{{ID: "S", Share: FULL_SHARE}})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	// 注销连续的两套房产，使第二页的前两条记录都被跳过
+	for _, id := range []string{"R3", "R4"} {
+		if err := s.RetireRealEstate(ctx, id, "拆除", "2025-12-31"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var pages [][]string
+	bookmark := ""
+	for {
+		result, err := s.QueryRealEstateList(ctx, 2, bookmark, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids := make([]string, 0, len(result.Records))
+		for _, record := range result.Records {
+			ids = append(ids, record.(RealEstate).ID)
+		}
+		if int(result.RecordsCount) != len(ids) {
+			t.Fatalf("记录数 = %d，实际返回 %d 条", result.RecordsCount, len(ids))
+		}
+		if len(ids) > 0 {
+			pages = append(pages, ids)
+		}
+		if result.Bookmark == "" {
+			break
+		}
+		bookmark = result.Bookmark
+	}
+
+	want := [][]string{{"R1", "R2"}, {"R5", "R6"}}
+	if !reflect.DeepEqual(pages, want) {
+		t.Fatalf("分页结果 = %v，期望 %v", pages, want)
+	}
+
+	// 指定状态时按状态索引查询已注销的房产
+	retired, err := s.QueryRealEstateList(ctx, 10, "", string(RETIRED))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if retired.RecordsCount != 2 {
+		t.Fatalf("已注销的房产 = %d 套，期望2套", retired.RecordsCount)
+	}
+}
+
+func TestExpireTransactionKeepsLegacyTransaction(t *testing.T) {
+	s := &SmartContract{}
+	stub := newMockStub()
+	ctx := newTestContext(stub)
+	stub.setCaller(t, REALTY_ORG_MSPID, "admin", nil)
+
+	// 旧版本生成的待付款交易没有过期时间
+	putLegacyState(t, stub, REAL_ESTATE, "IN_TRANSACTION", "R1", `{"id":"R1","propertyAddress":"北京市朝阳区","area":89.5,"currentOwner":"S","status":"IN_TRANSACTION","createTime":"2024-01-01T00:00:00Z","updateTime":"2024-01-01T00:00:00Z"}`)
+	putLegacyState(t, stub, TRANSACTION, "PENDING", "T1", `{"id":"T1","realEstateId":"R1","seller":"S","buyer":"B","price":1000000.5,"status":"PENDING","createTime":"2024-01-01T00:00:00Z","updateTime":"2024-01-01T00:00:00Z"}`)
+	if _, err := s.MigrateStorage(ctx, 10); err != nil {
+		t.Fatal(err)
+	}
+
+	stub.setCaller(t, BANK_ORG_MSPID, "client", nil)
+	if err := s.ExpireTransaction(ctx, "T1"); err == nil {
+		t.Fatal("没有有效期的旧版本交易过期应当失败")
+	}
+
+	transaction, err := s.getTransaction(ctx, "T1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	realEstate, err := s.getRealEstate(ctx, "R1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if transaction.Status != PENDING || realEstate.Status != IN_TRANSACTION {
+		t.Fatalf("交易状态 = %s，房产状态 = %s，期望保持 %s、%s", transaction.Status, realEstate.Status, PENDING, IN_TRANSACTION)
+	}
+}
+
+func TestCompleteTransactionRejectsLegacyTransaction(t *testing.T) {
+	s := &SmartContract{}
+	stub := newMockStub()
+	ctx := newTestContext(stub)
+	stub.setCaller(t, REALTY_ORG_MSPID, "admin", nil)
+
+	// 旧版本生成的交易买家和价格保存在公开状态中，没有签署和托管付款记录
+	putLegacyState(t, stub, REAL_ESTATE, "IN_TRANSACTION", "R1", `{"id":"R1","propertyAddress":"北京市朝阳区","area":89.5,"currentOwner":"S","status":"IN_TRANSACTION","createTime":"2024-01-01T00:00:00Z","updateTime":"2024-01-01T00:00:00Z"}`)
+	putLegacyState(t, stub, TRANSACTION, "PENDING", "T1", `{"id":"T1","realEstateId":"R1","seller":"S","buyer":"B","price":1000000.5,"status":"PENDING","createTime":"2024-01-01T00:00:00Z","updateTime":"2024-01-01T00:00:00Z"}`)
+	if _, err := s.MigrateStorage(ctx, 10); err != nil {
+		t.Fatal(err)
+	}
+
+	stub.setCaller(t, BANK_ORG_MSPID, "client", nil)
+	if err := s.CompleteTransaction(ctx, "T1"); err == nil {
+		t.Fatal("没有签署和付款记录的旧版本交易过户应当失败")
+	}
+	realEstate, err := s.getRealEstate(ctx, "R1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(realEstate.Owners, []Owner{{ID: "S", Share: FULL_SHARE}}) {
+		t.Fatalf("过户失败后所有者 = %+v，期望 S 仍持有全部份额", realEstate.Owners)
+	}
+
+	// 旧版本交易可以取消后按当前流程重新生成
+	if err := s.CancelTransaction(ctx, "T1", "升级后重新生成"); err != nil {
+		t.Fatal(err)
+	}
+	realEstate, err = s.getRealEstate(ctx, "R1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if realEstate.Status != NORMAL {
+		t.Fatalf("取消后房产状态 = %s，期望 %s", realEstate.Status, NORMAL)
+	}
+}
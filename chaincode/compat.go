@@ -0,0 +1,153 @@
+package main
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/v2/shim"
+	"github.com/hyperledger/fabric-contract-api-go/v2/contractapi"
+	"github.com/hyperledger/fabric-protos-go-apiv2/peer"
+)
+
+// legacyTimeArgFunctions 旧版本客户端会在最后多传一个时间参数的函数（函数名 -> 当前版本的参数个数）
+//
+// 兼容一个版本：旧客户端多传的时间参数会被忽略，时间统一取自账本交易时间，下个版本移除
+var legacyTimeArgFunctions = map[string]int{
+	"CompleteTransaction":     1,
+	"CancelTransaction":       2,
+	"ConsentMortgageTransfer": 2,
+	"ReleaseMortgage":         2,
+}
+
+// legacySignatureRewrites 新增参数后参数个数与旧版本相同的函数（函数名 -> 将旧签名的参数改写为当前签名），同样兼容一个版本
+//
+// 旧客户端的调用以时间参数结尾，据此与当前版本的调用区分：
+//   - CreateRealEstate(id, address, area, owner, createTime) 按住宅类型、owner 持有全部份额改写为 CreateRealEstate(id, address, area, RESIDENTIAL, owners)
+//   - RegisterMortgage(id, realEstateID, amount, createTime) 按人民币改写为 RegisterMortgage(id, realEstateID, amount, CNY)
+//
+// CreateTransaction 不兼容：旧签名在公开参数中传入买家和价格，而当前版本的买家和价格只能通过 transient map 写入私有数据集合，旧客户端必须升级
+var legacySignatureRewrites = map[string]struct {
+	paramCount int                                     // 旧签名的参数个数（含时间参数）
+	rewrite    func(params [][]byte) ([][]byte, error) // 改写为当前签名的参数
+}{
+	"CreateRealEstate": {5, func(params [][]byte) ([][]byte, error) {
+		area, err := decodeArea(params[2])
+		if err != nil {
+			return nil, err
+		}
+		owners, err := legacyOwners(params[3])
+		if err != nil {
+			return nil, err
+		}
+		return [][]byte{params[0], params[1], []byte(area), []byte(RESIDENTIAL), owners}, nil
+	}},
+	"RegisterMortgage": {4, func(params [][]byte) ([][]byte, error) {
+		amount, err := decodeMoney(params[2])
+		if err != nil {
+			return nil, err
+		}
+		return [][]byte{params[0], params[1], []byte(formatDecimal(amount.Amount, currencyMinorUnits[amount.Currency])), []byte(amount.Currency)}, nil
+	}},
+}
+
+// legacyOwners 将旧签名的单一所有者（交易方ID，可能带 JSON 引号）转换为持有全部份额的所有者列表，已经是所有者列表时原样返回
+func legacyOwners(arg []byte) ([]byte, error) {
+	var owners []Owner
+	if json.Unmarshal(arg, &owners) == nil {
+		return arg, nil
+	}
+
+	owner := string(arg)
+	var quoted string
+	if json.Unmarshal(arg, &quoted) == nil {
+		owner = quoted
+	}
+	return json.Marshal([]Owner{{ID: owner, Share: FULL_SHARE}})
+}
+
+// compatChaincode 兼容旧版本客户端调用的链码包装
+type compatChaincode struct {
+	*contractapi.ContractChaincode
+}
+
+// Init 去掉旧版本客户端传入的时间参数后交给合约处理
+func (c *compatChaincode) Init(stub shim.ChaincodeStubInterface) *peer.Response {
+	return c.ContractChaincode.Init(stripLegacyTimeArg(stub))
+}
+
+// Invoke 去掉旧版本客户端传入的时间参数后交给合约处理
+func (c *compatChaincode) Invoke(stub shim.ChaincodeStubInterface) *peer.Response {
+	return c.ContractChaincode.Invoke(stripLegacyTimeArg(stub))
+}
+
+// legacyArgsStub 替换了调用参数的 stub，其余方法委托给原 stub
+type legacyArgsStub struct {
+	shim.ChaincodeStubInterface
+	args [][]byte
+}
+
+func (s *legacyArgsStub) GetArgs() [][]byte {
+	return s.args
+}
+
+func (s *legacyArgsStub) GetStringArgs() []string {
+	args := make([]string, 0, len(s.args))
+	for _, arg := range s.args {
+		args = append(args, string(arg))
+	}
+	return args
+}
+
+func (s *legacyArgsStub) GetFunctionAndParameters() (string, []string) {
+	args := s.GetStringArgs()
+	if len(args) == 0 {
+		return "", []string{}
+	}
+	return args[0], args[1:]
+}
+
+// stripLegacyTimeArg 如果是旧版本客户端的调用（参数比当前版本多一个），去掉最后的时间参数
+func stripLegacyTimeArg(stub shim.ChaincodeStubInterface) shim.ChaincodeStubInterface {
+	args := stub.GetArgs()
+	if len(args) == 0 {
+		return stub
+	}
+
+	fn := unqualifiedFunctionName(string(args[0]))
+
+	if legacy, ok := legacySignatureRewrites[fn]; ok {
+		if len(args)-1 != legacy.paramCount || !isLegacyTimeArg(args[len(args)-1]) {
+			return stub
+		}
+		// 无法改写时按原参数调用，由合约返回参数错误
+		params, err := legacy.rewrite(args[1:])
+		if err != nil {
+			return stub
+		}
+		return &legacyArgsStub{
+			ChaincodeStubInterface: stub,
+			args:                   append([][]byte{args[0]}, params...),
+		}
+	}
+
+	paramCount, ok := legacyTimeArgFunctions[fn]
+	if !ok || len(args)-1 != paramCount+1 {
+		return stub
+	}
+
+	return &legacyArgsStub{
+		ChaincodeStubInterface: stub,
+		args:                   args[:len(args)-1],
+	}
+}
+
+// isLegacyTimeArg 判断参数是否为旧版本客户端传入的时间（RFC 3339 格式，可能带 JSON 引号）
+func isLegacyTimeArg(arg []byte) bool {
+	value := string(arg)
+	var quoted string
+	if json.Unmarshal(arg, &quoted) == nil {
+		value = quoted
+	}
+	_, err := time.Parse(time.RFC3339, value)
+	return err == nil
+}
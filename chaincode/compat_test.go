@@ -0,0 +1,44 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestStripLegacyTimeArg(t *testing.T) {
+	const now = "2024-05-01T08:00:00+08:00"
+	owners := `[{"id":"S","share":10000}]`
+
+	tests := []struct {
+		name string
+		args []string
+		want []string
+	}{
+		{"当前签名不改写", []string{"CompleteTransaction", "T1"}, []string{"CompleteTransaction", "T1"}},
+		{"去掉多传的时间参数", []string{"CancelTransaction", "T1", "协商取消", now}, []string{"CancelTransaction", "T1", "协商取消"}},
+		{"带合约名前缀", []string{"SmartContract:ReleaseMortgage", "R1", "M1", now}, []string{"SmartContract:ReleaseMortgage", "R1", "M1"}},
+		{"旧签名创建房产", []string{"CreateRealEstate", "R1", "北京", "89.500000", "S", now}, []string{"CreateRealEstate", "R1", "北京", "89.50", "RESIDENTIAL", owners}},
+		{"旧签名创建房产时所有者带引号", []string{"CreateRealEstate", "R1", "北京", "89.5", `"S"`, now}, []string{"CreateRealEstate", "R1", "北京", "89.50", "RESIDENTIAL", owners}},
+		{"当前签名创建房产", []string{"CreateRealEstate", "R1", "北京", "89.5", "OFFICE", owners}, []string{"CreateRealEstate", "R1", "北京", "89.5", "OFFICE", owners}},
+		{"旧签名登记抵押", []string{"RegisterMortgage", "M1", "R1", "1000000.000000", now}, []string{"RegisterMortgage", "M1", "R1", "1000000.00", "CNY"}},
+		{"当前签名登记抵押", []string{"RegisterMortgage", "M1", "R1", "1000000", "HKD"}, []string{"RegisterMortgage", "M1", "R1", "1000000", "HKD"}},
+		{"旧签名参数无法改写时原样调用", []string{"RegisterMortgage", "M1", "R1", "abc", now}, []string{"RegisterMortgage", "M1", "R1", "abc", now}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stub := newMockStub()
+			for _, arg := range tt.args {
+				stub.args = append(stub.args, []byte(arg))
+			}
+
+			var got []string
+			for _, arg := range stripLegacyTimeArg(stub).GetArgs() {
+				got = append(got, string(arg))
+			}
+			if strings.Join(got, "|") != strings.Join(tt.want, "|") {
+				t.Fatalf("参数 = %q，期望 %q", got, tt.want)
+			}
+		})
+	}
+}
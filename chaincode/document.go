@@ -0,0 +1,201 @@
+package main
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/v2/contractapi"
+)
+
+// DOCUMENT 文档存证记录文档类型（复合键：DOC_关联对象类型_关联对象ID_文档哈希）
+const DOCUMENT = "DOC"
+
+// DocumentEntityType 文档关联的对象类型
+type DocumentEntityType string
+
+const (
+	DOC_ENTITY_REAL_ESTATE DocumentEntityType = "REAL_ESTATE" // 房产
+	DOC_ENTITY_TRANSACTION DocumentEntityType = "TRANSACTION" // 交易
+	DOC_ENTITY_PARTY       DocumentEntityType = "PARTY"       // 交易方
+)
+
+// DocumentType 文档类型
+type DocumentType string
+
+const (
+	TITLE_DEED DocumentType = "TITLE_DEED" // 产权证书
+	CONTRACT   DocumentType = "CONTRACT"   // 合同
+	ID_SCAN    DocumentType = "ID_SCAN"    // 证件扫描件
+	OTHER_DOC  DocumentType = "OTHER"      // 其他
+)
+
+// validDocumentTypes 支持的文档类型
+var validDocumentTypes = map[DocumentType]bool{
+	TITLE_DEED: true,
+	CONTRACT:   true,
+	ID_SCAN:    true,
+	OTHER_DOC:  true,
+}
+
+// Document 文档存证记录（账本上只保存文件的 SHA-256 哈希和存储位置，文件本身保存在链下）
+type Document struct {
+	EntityType DocumentEntityType `json:"entityType"` // 关联对象类型
+	EntityID   string             `json:"entityId"`   // 关联对象ID
+	DocType    DocumentType       `json:"docType"`    // 文档类型
+	SHA256     string             `json:"sha256"`     // 文件内容的 SHA-256 哈希（十六进制小写）
+	URI        string             `json:"uri"`        // 文件的存储位置
+	Operator   string             `json:"operator"`   // 存证的组织 MSP ID
+	TxID       string             `json:"txId"`       // 存证的 Fabric 交易ID
+	CreateTime time.Time          `json:"createTime"` // 存证时间
+}
+
+// AttachDocument 为房产、交易或交易方存证文档（房产和交易方需要登记权限，交易需要登记、交易或结算权限）
+//
+// 同一文件不能重复存证到同一对象上
+func (s *SmartContract) AttachDocument(ctx contractapi.TransactionContextInterface, entityType string, entityID string, docType string, sha256 string, uri string) error {
+	// 验证调用者所在组织是否拥有该对象的存证权限
+	switch DocumentEntityType(entityType) {
+	case DOC_ENTITY_REAL_ESTATE, DOC_ENTITY_PARTY:
+		err := s.checkCapability(ctx, "存证文档", CAP_REGISTRY)
+		if err != nil {
+			return err
+		}
+	case DOC_ENTITY_TRANSACTION:
+		err := s.checkCapability(ctx, "存证文档", CAP_REGISTRY, CAP_TRADING, CAP_SETTLEMENT)
+		if err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("关联对象类型必须为 %s、%s 或 %s", DOC_ENTITY_REAL_ESTATE, DOC_ENTITY_TRANSACTION, DOC_ENTITY_PARTY)
+	}
+
+	clientMSPID, err := s.getClientIdentityMSPID(ctx)
+	if err != nil {
+		return fmt.Errorf("获取调用者身份失败：%v", err)
+	}
+
+	// 以账本交易时间作为存证时间
+	createTime, err := s.getTxTime(ctx)
+	if err != nil {
+		return err
+	}
+
+	// 参数验证
+	if !validDocumentTypes[DocumentType(docType)] {
+		return fmt.Errorf("不支持的文档类型：%s", docType)
+	}
+	err = validateDocumentHash(sha256)
+	if err != nil {
+		return err
+	}
+	if len(strings.TrimSpace(uri)) == 0 {
+		return fmt.Errorf("文件存储位置不能为空")
+	}
+
+	// 确认关联对象存在
+	err = s.checkDocumentEntity(ctx, DocumentEntityType(entityType), entityID)
+	if err != nil {
+		return err
+	}
+
+	// 检查是否已存证
+	key, err := s.getCompositeKey(ctx, DOCUMENT, []string{entityType, entityID, sha256})
+	if err != nil {
+		return err
+	}
+
+	exists, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return fmt.Errorf("查询文档存证记录失败：%v", err)
+	}
+	if exists != nil {
+		return fmt.Errorf("该文件已存证到 %s %s", entityType, entityID)
+	}
+
+	document := Document{
+		EntityType: DocumentEntityType(entityType),
+		EntityID:   entityID,
+		DocType:    DocumentType(docType),
+		SHA256:     sha256,
+		URI:        uri,
+		Operator:   clientMSPID,
+		TxID:       ctx.GetStub().GetTxID(),
+		CreateTime: createTime,
+	}
+	return s.putState(ctx, key, document)
+}
+
+// VerifyDocument 校验文件哈希是否已存证到指定对象上
+func (s *SmartContract) VerifyDocument(ctx contractapi.TransactionContextInterface, entityType string, entityID string, sha256 string) (bool, error) {
+	err := validateDocumentHash(sha256)
+	if err != nil {
+		return false, err
+	}
+
+	key, err := s.getCompositeKey(ctx, DOCUMENT, []string{entityType, entityID, sha256})
+	if err != nil {
+		return false, err
+	}
+
+	bytes, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return false, fmt.Errorf("查询文档存证记录失败：%v", err)
+	}
+	return bytes != nil, nil
+}
+
+// QueryDocuments 查询对象的文档存证记录（按存证时间排序）
+func (s *SmartContract) QueryDocuments(ctx contractapi.TransactionContextInterface, entityType string, entityID string) ([]*Document, error) {
+	iterator, err := ctx.GetStub().GetStateByPartialCompositeKey(DOCUMENT, []string{entityType, entityID})
+	if err != nil {
+		return nil, fmt.Errorf("查询文档存证记录失败：%v", err)
+	}
+	defer iterator.Close()
+
+	documents := make([]*Document, 0)
+	for iterator.HasNext() {
+		queryResponse, err := iterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("获取下一条记录失败：%v", err)
+		}
+
+		var document Document
+		err = json.Unmarshal(queryResponse.Value, &document)
+		if err != nil {
+			return nil, fmt.Errorf("解析文档存证记录失败：%v", err)
+		}
+		documents = append(documents, &document)
+	}
+
+	sort.Slice(documents, func(i, j int) bool {
+		return documents[i].CreateTime.Before(documents[j].CreateTime)
+	})
+	return documents, nil
+}
+
+// validateDocumentHash 检查文件哈希是否为64位小写十六进制的 SHA-256 值
+func validateDocumentHash(sha256 string) error {
+	decoded, err := hex.DecodeString(sha256)
+	if err != nil || len(decoded) != 32 || strings.ToLower(sha256) != sha256 {
+		return fmt.Errorf("文件哈希必须为64位小写十六进制的 SHA-256 值")
+	}
+	return nil
+}
+
+// 通用方法：确认文档关联的对象存在
+func (s *SmartContract) checkDocumentEntity(ctx contractapi.TransactionContextInterface, entityType DocumentEntityType, entityID string) error {
+	var err error
+	switch entityType {
+	case DOC_ENTITY_REAL_ESTATE:
+		_, err = s.getRealEstate(ctx, entityID)
+	case DOC_ENTITY_TRANSACTION:
+		_, err = s.getTransaction(ctx, entityID)
+	case DOC_ENTITY_PARTY:
+		_, err = s.getParty(ctx, entityID)
+	}
+	return err
+}
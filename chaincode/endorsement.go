@@ -0,0 +1,112 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/hyperledger/fabric-chaincode-go/v2/pkg/cid"
+	"github.com/hyperledger/fabric-chaincode-go/v2/pkg/statebased"
+	"github.com/hyperledger/fabric-contract-api-go/v2/contractapi"
+)
+
+// RealEstateEndorsementPolicy 房产信息的键级背书策略（列出的组织的节点必须全部背书，才能修改房产信息）
+type RealEstateEndorsementPolicy struct {
+	RealEstateID string   `json:"realEstateId"` // 房产ID
+	Orgs         []string `json:"orgs"`         // 必须背书的组织 MSP ID（为空表示使用通道的链码背书策略）
+}
+
+// QueryRealEstateEndorsementPolicy 查询房产信息的键级背书策略
+func (s *SmartContract) QueryRealEstateEndorsementPolicy(ctx contractapi.TransactionContextInterface, realEstateID string) (*RealEstateEndorsementPolicy, error) {
+	// 确认房产存在
+	_, err := s.getRealEstate(ctx, realEstateID)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := s.getCompositeKey(ctx, REAL_ESTATE, []string{realEstateID})
+	if err != nil {
+		return nil, err
+	}
+
+	policy, err := ctx.GetStub().GetStateValidationParameter(key)
+	if err != nil {
+		return nil, fmt.Errorf("读取房产背书策略失败：%v", err)
+	}
+
+	ep, err := statebased.NewStateEP(policy)
+	if err != nil {
+		return nil, fmt.Errorf("解析房产背书策略失败：%v", err)
+	}
+
+	orgs := ep.ListOrgs()
+	sort.Strings(orgs)
+	return &RealEstateEndorsementPolicy{
+		RealEstateID: realEstateID,
+		Orgs:         orgs,
+	}, nil
+}
+
+// SetRealEstateEndorsementPolicy 设置房产信息的键级背书策略（仅拥有登记权限的组织的管理员可以调用，orgs 为空表示恢复使用通道的链码背书策略）
+//
+// 修改策略本身也是对房产信息的写入，需要满足修改前的键级背书策略
+func (s *SmartContract) SetRealEstateEndorsementPolicy(ctx contractapi.TransactionContextInterface, realEstateID string, orgs []string) error {
+	// 检查调用者是否为组织管理员
+	isAdmin, err := cid.HasOUValue(ctx.GetStub(), "admin")
+	if err != nil {
+		return fmt.Errorf("获取调用者身份失败：%v", err)
+	}
+	if !isAdmin {
+		return fmt.Errorf("只有组织管理员才能设置房产背书策略")
+	}
+
+	// 验证调用者所在组织是否拥有登记权限
+	err = s.checkCapability(ctx, "设置房产背书策略", CAP_REGISTRY)
+	if err != nil {
+		return err
+	}
+
+	// 确认房产存在
+	_, err = s.getRealEstate(ctx, realEstateID)
+	if err != nil {
+		return err
+	}
+
+	for _, org := range orgs {
+		if len(org) == 0 {
+			return fmt.Errorf("组织 MSP ID 不能为空")
+		}
+	}
+
+	key, err := s.getCompositeKey(ctx, REAL_ESTATE, []string{realEstateID})
+	if err != nil {
+		return err
+	}
+	return s.setEndorsementPolicy(ctx, key, orgs)
+}
+
+// 通用方法：设置键级背书策略，要求列出的组织的节点全部背书（orgs 为空时删除键级背书策略）
+func (s *SmartContract) setEndorsementPolicy(ctx contractapi.TransactionContextInterface, key string, orgs []string) error {
+	var policy []byte
+	if len(orgs) > 0 {
+		ep, err := statebased.NewStateEP(nil)
+		if err != nil {
+			return fmt.Errorf("创建背书策略失败：%v", err)
+		}
+
+		err = ep.AddOrgs(statebased.RoleTypePeer, orgs...)
+		if err != nil {
+			return fmt.Errorf("设置背书组织失败：%v", err)
+		}
+
+		policy, err = ep.Policy()
+		if err != nil {
+			return fmt.Errorf("序列化背书策略失败：%v", err)
+		}
+	}
+
+	err := ctx.GetStub().SetStateValidationParameter(key, policy)
+	if err != nil {
+		return fmt.Errorf("保存背书策略失败：%v", err)
+	}
+	return nil
+}
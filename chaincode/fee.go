@@ -0,0 +1,393 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/v2/contractapi"
+)
+
+// FEE 交易税费记录文档类型（保存在交易私有数据集合中，复合键：FEE_交易ID_税费类型_缴纳方）
+const FEE = "FEE"
+
+// FEE_SCHEDULE_CONFIG 交易税费标准的配置项（复合键：CONFIG_FEE_SCHEDULE）
+const FEE_SCHEDULE_CONFIG = "FEE_SCHEDULE"
+
+// FEE_RATE_BASE 税率的基数（税率以万分比表示）
+const FEE_RATE_BASE = 10000
+
+// FeeType 税费类型
+type FeeType string
+
+const (
+	DEED_TAX     FeeType = "DEED_TAX"     // 契税
+	STAMP_DUTY   FeeType = "STAMP_DUTY"   // 印花税
+	PLATFORM_FEE FeeType = "PLATFORM_FEE" // 平台服务费
+)
+
+// validFeeTypes 合法的税费类型
+var validFeeTypes = map[FeeType]bool{
+	DEED_TAX:     true,
+	STAMP_DUTY:   true,
+	PLATFORM_FEE: true,
+}
+
+// FeeRule 税费标准中的一条规则（成交价格落在 [minPrice, maxPrice) 区间时按成交价格全额计算）
+type FeeRule struct {
+	FeeType      FeeType      `json:"feeType"`      // 税费类型
+	PropertyType PropertyType `json:"propertyType"` // 适用的房产类型（为空表示适用于所有类型，同时存在时以指定类型的规则为准）
+	Payer        PartyRole    `json:"payer"`        // 缴纳方（买家或卖家）
+	Currency     string       `json:"currency"`     // 适用的成交价格币种
+	MinPrice     string       `json:"minPrice"`     // 价格区间下限（含，十进制字符串，为空表示0）
+	MaxPrice     string       `json:"maxPrice"`     // 价格区间上限（不含，十进制字符串，为空表示不设上限）
+	Rate         int          `json:"rate"`         // 税率（万分比，如 150 表示 1.5%）
+	FixedAmount  string       `json:"fixedAmount"`  // 固定金额（十进制字符串，与按税率计算的金额相加，为空表示0）
+}
+
+// FeeSchedule 交易税费标准
+type FeeSchedule struct {
+	Rules      []FeeRule `json:"rules"`      // 规则列表
+	Operator   string    `json:"operator"`   // 最近一次更新的组织 MSP ID
+	UpdateTime time.Time `json:"updateTime"` // 最近一次更新时间
+}
+
+// Fee 交易完成时计算的税费（金额可以推算成交价格，与交易私有数据保存在同一私有数据集合中）
+type Fee struct {
+	TxID         string       `json:"txId"`         // 交易ID
+	FeeType      FeeType      `json:"feeType"`      // 税费类型
+	Payer        PartyRole    `json:"payer"`        // 缴纳方角色
+	PayerID      string       `json:"payerId"`      // 缴纳方（交易方ID）
+	PropertyType PropertyType `json:"propertyType"` // 计算时的房产类型
+	Rate         int          `json:"rate"`         // 适用的税率（万分比）
+	Amount       Money        `json:"amount"`       // 税费金额（币种与成交价格一致）
+	CreateTime   time.Time    `json:"createTime"`   // 计算时间（交易完成时间）
+}
+
+// SetFeeSchedule 更新交易税费标准（仅拥有税务或登记权限的组织可以调用，整体替换现有规则，只影响之后完成的交易）
+func (s *SmartContract) SetFeeSchedule(ctx contractapi.TransactionContextInterface, rules []FeeRule) error {
+	// 验证调用者所在组织是否拥有税务或登记权限
+	err := s.checkCapability(ctx, "更新税费标准", CAP_TAXATION, CAP_REGISTRY)
+	if err != nil {
+		return err
+	}
+
+	clientMSPID, err := s.getClientIdentityMSPID(ctx)
+	if err != nil {
+		return fmt.Errorf("获取调用者身份失败：%v", err)
+	}
+
+	// 以账本交易时间作为更新时间
+	updateTime, err := s.getTxTime(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = validateFeeRules(rules)
+	if err != nil {
+		return err
+	}
+	if rules == nil {
+		rules = []FeeRule{}
+	}
+
+	key, err := s.getCompositeKey(ctx, CONFIG, []string{FEE_SCHEDULE_CONFIG})
+	if err != nil {
+		return err
+	}
+	return s.putState(ctx, key, FeeSchedule{
+		Rules:      rules,
+		Operator:   clientMSPID,
+		UpdateTime: updateTime,
+	})
+}
+
+// QueryFeeSchedule 查询交易税费标准（尚未设置时返回空的规则列表）
+func (s *SmartContract) QueryFeeSchedule(ctx contractapi.TransactionContextInterface) (*FeeSchedule, error) {
+	return s.getFeeSchedule(ctx)
+}
+
+// QueryTransactionFees 查询交易完成时计算的税费明细（仅拥有结算或交易权限的组织可以调用）
+func (s *SmartContract) QueryTransactionFees(ctx contractapi.TransactionContextInterface, txID string) ([]*Fee, error) {
+	// 验证调用者所在组织是否拥有结算或交易权限
+	err := s.checkCapability(ctx, "查询交易税费", CAP_SETTLEMENT, CAP_TRADING)
+	if err != nil {
+		return nil, err
+	}
+
+	// 确认交易存在
+	_, err = s.getTransaction(ctx, txID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.getTransactionFees(ctx, txID)
+}
+
+// 通用方法：按当前税费标准计算交易税费并保存到私有数据集合（替换交易已有的税费记录）
+func (s *SmartContract) recordTransactionFees(ctx contractapi.TransactionContextInterface, transaction *Transaction, privateDetails *TransactionPrivateDetails, createTime time.Time) error {
+	schedule, err := s.getFeeSchedule(ctx)
+	if err != nil {
+		return err
+	}
+
+	realEstate, err := s.getRealEstate(ctx, transaction.RealEstateID)
+	if err != nil {
+		return err
+	}
+	propertyType := realEstate.PropertyType
+	if len(propertyType) == 0 {
+		propertyType = RESIDENTIAL
+	}
+
+	fees, err := calculateFees(schedule.Rules, propertyType, privateDetails.Price)
+	if err != nil {
+		return err
+	}
+
+	// 再次结算时删除上次计算的税费记录，避免保留已不适用的税费类型或缴纳方
+	err = s.deleteTransactionFees(ctx, transaction.ID)
+	if err != nil {
+		return err
+	}
+
+	for _, fee := range fees {
+		fee.TxID = transaction.ID
+		fee.PayerID = transaction.Seller
+		if fee.Payer == PARTY_BUYER {
+			fee.PayerID = privateDetails.Buyer
+		}
+		fee.CreateTime = createTime
+
+		key, err := s.getCompositeKey(ctx, FEE, []string{fee.TxID, string(fee.FeeType), string(fee.Payer)})
+		if err != nil {
+			return err
+		}
+
+		data, err := json.Marshal(fee)
+		if err != nil {
+			return fmt.Errorf("序列化税费记录失败：%v", err)
+		}
+
+		err = ctx.GetStub().PutPrivateData(TRANSACTION_PRIVATE_COLLECTION, key, data)
+		if err != nil {
+			return fmt.Errorf("保存税费记录失败：%v", err)
+		}
+	}
+	return nil
+}
+
+// 通用方法：按税费标准计算成交价格对应的税费（同一税费类型和缴纳方只取一条规则，指定房产类型的规则优先）
+func calculateFees(rules []FeeRule, propertyType PropertyType, price Money) ([]*Fee, error) {
+	type feeKey struct {
+		feeType FeeType
+		payer   PartyRole
+	}
+	matched := make(map[feeKey]FeeRule)
+
+	for _, rule := range rules {
+		if rule.Currency != price.Currency {
+			continue
+		}
+		if len(rule.PropertyType) > 0 && rule.PropertyType != propertyType {
+			continue
+		}
+
+		minPrice, maxPrice, err := feeRuleBracket(rule)
+		if err != nil {
+			return nil, err
+		}
+		if price.Amount < minPrice || (maxPrice > 0 && price.Amount >= maxPrice) {
+			continue
+		}
+
+		key := feeKey{rule.FeeType, rule.Payer}
+		if existing, ok := matched[key]; ok && len(existing.PropertyType) > 0 {
+			continue
+		}
+		matched[key] = rule
+	}
+
+	fees := make([]*Fee, 0, len(matched))
+	for key, rule := range matched {
+		fixedAmount, err := parseOptionalDecimal(rule.FixedAmount, rule.Currency)
+		if err != nil {
+			return nil, err
+		}
+
+		// 按万分比计算并四舍五入到最小货币单位，先除后乘避免溢出
+		amount := price.Amount/FEE_RATE_BASE*int64(rule.Rate) + (price.Amount%FEE_RATE_BASE*int64(rule.Rate)+FEE_RATE_BASE/2)/FEE_RATE_BASE + fixedAmount
+		if amount == 0 {
+			continue
+		}
+
+		fees = append(fees, &Fee{
+			FeeType:      key.feeType,
+			Payer:        key.payer,
+			PropertyType: propertyType,
+			Rate:         rule.Rate,
+			Amount:       Money{Amount: amount, Currency: price.Currency},
+		})
+	}
+
+	sort.Slice(fees, func(i, j int) bool {
+		if fees[i].FeeType != fees[j].FeeType {
+			return fees[i].FeeType < fees[j].FeeType
+		}
+		return fees[i].Payer < fees[j].Payer
+	})
+	return fees, nil
+}
+
+// 通用方法：校验税费规则，同一税费类型、缴纳方、房产类型和币种的价格区间不能重叠
+func validateFeeRules(rules []FeeRule) error {
+	type bracket struct {
+		min, max int64
+	}
+	type groupKey struct {
+		feeType      FeeType
+		payer        PartyRole
+		propertyType PropertyType
+		currency     string
+	}
+	groups := make(map[groupKey][]bracket)
+
+	for i, rule := range rules {
+		if !validFeeTypes[rule.FeeType] {
+			return fmt.Errorf("第%d条规则的税费类型 %s 无效", i+1, rule.FeeType)
+		}
+		if len(rule.PropertyType) > 0 && !validPropertyTypes[rule.PropertyType] {
+			return fmt.Errorf("第%d条规则的房产类型 %s 无效", i+1, rule.PropertyType)
+		}
+		if rule.Payer != PARTY_BUYER && rule.Payer != PARTY_SELLER {
+			return fmt.Errorf("第%d条规则的缴纳方 %s 无效", i+1, rule.Payer)
+		}
+		if _, ok := currencyMinorUnits[rule.Currency]; !ok {
+			return fmt.Errorf("第%d条规则的币种 %s 不受支持", i+1, rule.Currency)
+		}
+		if rule.Rate < 0 || rule.Rate > FEE_RATE_BASE {
+			return fmt.Errorf("第%d条规则的税率必须在0到%d之间", i+1, FEE_RATE_BASE)
+		}
+
+		minPrice, maxPrice, err := feeRuleBracket(rule)
+		if err != nil {
+			return fmt.Errorf("第%d条规则无效：%v", i+1, err)
+		}
+		if maxPrice > 0 && maxPrice <= minPrice {
+			return fmt.Errorf("第%d条规则的价格区间上限必须大于下限", i+1)
+		}
+
+		fixedAmount, err := parseOptionalDecimal(rule.FixedAmount, rule.Currency)
+		if err != nil {
+			return fmt.Errorf("第%d条规则的固定金额无效：%v", i+1, err)
+		}
+		if rule.Rate == 0 && fixedAmount == 0 {
+			return fmt.Errorf("第%d条规则的税率和固定金额不能同时为0", i+1)
+		}
+
+		key := groupKey{rule.FeeType, rule.Payer, rule.PropertyType, rule.Currency}
+		for _, other := range groups[key] {
+			if (maxPrice == 0 || other.min < maxPrice) && (other.max == 0 || minPrice < other.max) {
+				return fmt.Errorf("第%d条规则的价格区间与同类规则重叠", i+1)
+			}
+		}
+		groups[key] = append(groups[key], bracket{minPrice, maxPrice})
+	}
+	return nil
+}
+
+// 通用方法：解析税费规则的价格区间（最小货币单位，上限为0表示不设上限）
+func feeRuleBracket(rule FeeRule) (int64, int64, error) {
+	minPrice, err := parseOptionalDecimal(rule.MinPrice, rule.Currency)
+	if err != nil {
+		return 0, 0, fmt.Errorf("价格区间下限无效：%v", err)
+	}
+	maxPrice, err := parseOptionalDecimal(rule.MaxPrice, rule.Currency)
+	if err != nil {
+		return 0, 0, fmt.Errorf("价格区间上限无效：%v", err)
+	}
+	return minPrice, maxPrice, nil
+}
+
+// 通用方法：按币种精度解析可以为空的非负十进制金额（为空时返回0）
+func parseOptionalDecimal(value string, currency string) (int64, error) {
+	if len(value) == 0 {
+		return 0, nil
+	}
+	return parseDecimal(value, currencyMinorUnits[currency])
+}
+
+// 通用方法：读取交易税费标准（尚未设置时返回空的规则列表）
+func (s *SmartContract) getFeeSchedule(ctx contractapi.TransactionContextInterface) (*FeeSchedule, error) {
+	key, err := s.getCompositeKey(ctx, CONFIG, []string{FEE_SCHEDULE_CONFIG})
+	if err != nil {
+		return nil, err
+	}
+
+	bytes, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return nil, fmt.Errorf("读取税费标准失败：%v", err)
+	}
+	if bytes == nil {
+		return &FeeSchedule{Rules: []FeeRule{}}, nil
+	}
+
+	var schedule FeeSchedule
+	err = json.Unmarshal(bytes, &schedule)
+	if err != nil {
+		return nil, fmt.Errorf("解析税费标准失败：%v", err)
+	}
+	return &schedule, nil
+}
+
+// 通用方法：读取交易的税费记录（按复合键排序，即按税费类型和缴纳方排序）
+func (s *SmartContract) getTransactionFees(ctx contractapi.TransactionContextInterface, txID string) ([]*Fee, error) {
+	iterator, err := ctx.GetStub().GetPrivateDataByPartialCompositeKey(TRANSACTION_PRIVATE_COLLECTION, FEE, []string{txID})
+	if err != nil {
+		return nil, fmt.Errorf("查询税费记录失败：%v", err)
+	}
+	defer iterator.Close()
+
+	fees := make([]*Fee, 0)
+	for iterator.HasNext() {
+		queryResponse, err := iterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("获取下一条记录失败：%v", err)
+		}
+
+		var fee Fee
+		err = json.Unmarshal(queryResponse.Value, &fee)
+		if err != nil {
+			return nil, fmt.Errorf("解析税费记录失败：%v", err)
+		}
+		fees = append(fees, &fee)
+	}
+	return fees, nil
+}
+
+// 通用方法：删除交易的全部税费记录
+func (s *SmartContract) deleteTransactionFees(ctx contractapi.TransactionContextInterface, txID string) error {
+	iterator, err := ctx.GetStub().GetPrivateDataByPartialCompositeKey(TRANSACTION_PRIVATE_COLLECTION, FEE, []string{txID})
+	if err != nil {
+		return fmt.Errorf("查询税费记录失败：%v", err)
+	}
+	defer iterator.Close()
+
+	var keys []string
+	for iterator.HasNext() {
+		queryResponse, err := iterator.Next()
+		if err != nil {
+			return fmt.Errorf("获取下一条记录失败：%v", err)
+		}
+		keys = append(keys, queryResponse.Key)
+	}
+
+	for _, key := range keys {
+		err = ctx.GetStub().DelPrivateData(TRANSACTION_PRIVATE_COLLECTION, key)
+		if err != nil {
+			return fmt.Errorf("删除税费记录失败：%v", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,185 @@
+package main
+
+import (
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCalculateFees(t *testing.T) {
+	// 通用契税规则在前，住宅规则在后，验证指定房产类型的规则优先与规则顺序无关
+	rules := []FeeRule{
+		{FeeType: DEED_TAX, Payer: PARTY_BUYER, Currency: "CNY", Rate: 300},
+		{FeeType: DEED_TAX, PropertyType: RESIDENTIAL, Payer: PARTY_BUYER, Currency: "CNY", MaxPrice: "900000", Rate: 100},
+		{FeeType: DEED_TAX, PropertyType: RESIDENTIAL, Payer: PARTY_BUYER, Currency: "CNY", MinPrice: "900000", Rate: 150},
+		{FeeType: STAMP_DUTY, Payer: PARTY_SELLER, Currency: "CNY", Rate: 5},
+		{FeeType: PLATFORM_FEE, Payer: PARTY_BUYER, Currency: "CNY", FixedAmount: "500"},
+		{FeeType: DEED_TAX, Payer: PARTY_BUYER, Currency: "USD", Rate: 200},
+	}
+	if err := validateFeeRules(rules); err != nil {
+		t.Fatal(err)
+	}
+
+	type fee struct {
+		feeType FeeType
+		payer   PartyRole
+		rate    int
+		amount  int64
+	}
+	tests := []struct {
+		name         string
+		propertyType PropertyType
+		price        Money
+		want         []fee
+	}{
+		{
+			name:         "住宅低档税率",
+			propertyType: RESIDENTIAL,
+			price:        Money{Amount: 80000000, Currency: "CNY"},
+			want: []fee{
+				{DEED_TAX, PARTY_BUYER, 100, 800000},
+				{PLATFORM_FEE, PARTY_BUYER, 0, 50000},
+				{STAMP_DUTY, PARTY_SELLER, 5, 40000},
+			},
+		},
+		{
+			name:         "价格等于区间上限时适用高档税率",
+			propertyType: RESIDENTIAL,
+			price:        Money{Amount: 90000000, Currency: "CNY"},
+			want: []fee{
+				{DEED_TAX, PARTY_BUYER, 150, 1350000},
+				{PLATFORM_FEE, PARTY_BUYER, 0, 50000},
+				{STAMP_DUTY, PARTY_SELLER, 5, 45000},
+			},
+		},
+		{
+			name:         "价格略低于区间上限时适用低档税率",
+			propertyType: RESIDENTIAL,
+			price:        Money{Amount: 89999999, Currency: "CNY"},
+			want: []fee{
+				{DEED_TAX, PARTY_BUYER, 100, 900000},
+				{PLATFORM_FEE, PARTY_BUYER, 0, 50000},
+				{STAMP_DUTY, PARTY_SELLER, 5, 45000},
+			},
+		},
+		{
+			name:         "没有专门规则的房产类型适用通用规则",
+			propertyType: COMMERCIAL,
+			price:        Money{Amount: 100000000, Currency: "CNY"},
+			want: []fee{
+				{DEED_TAX, PARTY_BUYER, 300, 3000000},
+				{PLATFORM_FEE, PARTY_BUYER, 0, 50000},
+				{STAMP_DUTY, PARTY_SELLER, 5, 50000},
+			},
+		},
+		{
+			name:         "四舍五入到最小货币单位，金额为0的税费不记录",
+			propertyType: RESIDENTIAL,
+			price:        Money{Amount: 99, Currency: "CNY"},
+			want: []fee{
+				{DEED_TAX, PARTY_BUYER, 100, 1},
+				{PLATFORM_FEE, PARTY_BUYER, 0, 50000},
+			},
+		},
+		{
+			name:         "只适用成交价格币种的规则",
+			propertyType: RESIDENTIAL,
+			price:        Money{Amount: 50000000, Currency: "USD"},
+			want: []fee{
+				{DEED_TAX, PARTY_BUYER, 200, 1000000},
+			},
+		},
+		{
+			name:         "没有适用的规则",
+			propertyType: RESIDENTIAL,
+			price:        Money{Amount: 50000000, Currency: "EUR"},
+			want:         []fee{},
+		},
+		{
+			name:         "最大金额不溢出",
+			propertyType: RESIDENTIAL,
+			price:        Money{Amount: math.MaxInt64, Currency: "CNY"},
+			want: []fee{
+				{DEED_TAX, PARTY_BUYER, 150, 138350580552821637},
+				{PLATFORM_FEE, PARTY_BUYER, 0, 50000},
+				{STAMP_DUTY, PARTY_SELLER, 5, 4611686018427388},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fees, err := calculateFees(rules, tt.propertyType, tt.price)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got := make([]fee, 0, len(fees))
+			for _, f := range fees {
+				if f.Amount.Currency != tt.price.Currency || f.PropertyType != tt.propertyType {
+					t.Fatalf("税费 %+v 的币种或房产类型与交易不一致", f)
+				}
+				got = append(got, fee{f.FeeType, f.Payer, f.Rate, f.Amount.Amount})
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("税费 = %+v，期望 %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSettleTransactionReplacesFees(t *testing.T) {
+	s := &SmartContract{}
+	stub := newMockStub()
+	ctx := newTestContext(stub)
+
+	sellerKey := registerTestSigner(t, s, stub, "S")
+	buyerKey := registerTestSigner(t, s, stub, "B")
+	stub.setCaller(t, REALTY_ORG_MSPID, "client", nil)
+	err := s.CreateRealEstate(ctx, "R1", "北京市朝阳区", "89.50", string(RESIDENTIAL), []Owner{{ID: "S", Share: FULL_SHARE}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	putTestTransaction(t, s, stub, &Transaction{ID: "T1", RealEstateID: "R1", Seller: "S", Share: FULL_SHARE, Status: PAID}, &TransactionPrivateDetails{
+		TxID:  "T1",
+		Buyer: "B",
+		Price: Money{Amount: 100000000, Currency: "CNY"},
+		Salt:  strings.Repeat("0", 32),
+	})
+	approveTestTransaction(t, s, stub, "T1", "S", sellerKey)
+	approveTestTransaction(t, s, stub, "T1", "B", buyerKey)
+
+	settle := func(rules []FeeRule) []*Fee {
+		t.Helper()
+		stub.setCaller(t, REALTY_ORG_MSPID, "client", nil)
+		if err := s.SetFeeSchedule(ctx, rules); err != nil {
+			t.Fatal(err)
+		}
+		stub.setCaller(t, BANK_ORG_MSPID, "client", nil)
+		if err := s.SettleTransaction(ctx, "T1"); err != nil {
+			t.Fatal(err)
+		}
+		fees, err := s.QueryTransactionFees(ctx, "T1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		return fees
+	}
+
+	fees := settle([]FeeRule{
+		{FeeType: DEED_TAX, Payer: PARTY_BUYER, Currency: "CNY", Rate: 150},
+		{FeeType: STAMP_DUTY, Payer: PARTY_SELLER, Currency: "CNY", Rate: 5},
+	})
+	if len(fees) != 2 {
+		t.Fatalf("首次结算的税费记录 = %d 条，期望2条", len(fees))
+	}
+
+	// 税费标准变更后再次结算，不再适用的印花税记录应当删除
+	fees = settle([]FeeRule{
+		{FeeType: DEED_TAX, Payer: PARTY_BUYER, Currency: "CNY", Rate: 100},
+	})
+	if len(fees) != 1 || fees[0].FeeType != DEED_TAX || fees[0].Amount.Amount != 1000000 {
+		t.Fatalf("再次结算的税费记录 = %+v，期望只有按 1%% 计算的契税", fees)
+	}
+}
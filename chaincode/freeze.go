@@ -0,0 +1,300 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/v2/contractapi"
+)
+
+// FREEZE 司法冻结记录文档类型（复合键：FZ_冻结记录ID）
+const FREEZE = "FZ"
+
+// 司法冻结索引类型常量（复合键：索引类型_索引值_冻结记录ID，值为空）
+const (
+	FREEZE_STATUS_INDEX      = "FZ-STATUS" // 冻结状态索引（按状态查询冻结记录）
+	FREEZE_REAL_ESTATE_INDEX = "FZ-RE"     // 冻结房产索引（查询房产的冻结历史）
+)
+
+// FreezeStatus 司法冻结状态
+type FreezeStatus string
+
+const (
+	FREEZE_ACTIVE  FreezeStatus = "ACTIVE"  // 冻结中
+	FREEZE_LIFTED  FreezeStatus = "LIFTED"  // 已解除
+	FREEZE_EXPIRED FreezeStatus = "EXPIRED" // 已到期
+)
+
+// Freeze 司法冻结记录
+type Freeze struct {
+	ID             string           `json:"id"`             // 冻结记录ID（办理冻结的 Fabric 交易ID）
+	RealEstateID   string           `json:"realEstateId"`   // 房产ID
+	CaseNumber     string           `json:"caseNumber"`     // 案号
+	Authority      string           `json:"authority"`      // 出具裁定的机关
+	Operator       string           `json:"operator"`       // 办理冻结的组织 MSP ID
+	PreviousStatus RealEstateStatus `json:"previousStatus"` // 冻结前的房产状态（冻结结束后恢复）
+	Status         FreezeStatus     `json:"status"`         // 冻结状态
+	LiftReason     string           `json:"liftReason"`     // 解除原因
+	ExpireTime     time.Time        `json:"expireTime"`     // 冻结期限（到期后任何组织都可以结束冻结）
+	CreateTime     time.Time        `json:"createTime"`     // 创建时间
+	UpdateTime     time.Time        `json:"updateTime"`     // 更新时间
+}
+
+// FreezeRealEstate 司法冻结房产（仅拥有司法或登记权限的组织可以调用，expireTime 为 RFC3339 格式的冻结期限）
+//
+// 交易中的房产也可以冻结，冻结期间交易不能完成；冻结结束后房产恢复冻结前的状态
+func (s *SmartContract) FreezeRealEstate(ctx contractapi.TransactionContextInterface, realEstateID string, caseNumber string, authority string, expireTime string) error {
+	// 验证调用者所在组织是否拥有司法或登记权限
+	err := s.checkCapability(ctx, "冻结房产", CAP_JUDICIAL, CAP_REGISTRY)
+	if err != nil {
+		return err
+	}
+
+	clientMSPID, err := s.getClientIdentityMSPID(ctx)
+	if err != nil {
+		return fmt.Errorf("获取调用者身份失败：%v", err)
+	}
+
+	// 以账本交易时间作为创建时间
+	createTime, err := s.getTxTime(ctx)
+	if err != nil {
+		return err
+	}
+
+	// 参数验证
+	if len(realEstateID) == 0 {
+		return fmt.Errorf("房产ID不能为空")
+	}
+	if len(caseNumber) == 0 {
+		return fmt.Errorf("案号不能为空")
+	}
+	if len(authority) == 0 {
+		return fmt.Errorf("出具裁定的机关不能为空")
+	}
+	expire, err := time.Parse(time.RFC3339, expireTime)
+	if err != nil {
+		return fmt.Errorf("冻结期限格式错误，应为 RFC3339 格式：%v", err)
+	}
+	if !expire.After(createTime) {
+		return fmt.Errorf("冻结期限必须晚于当前时间")
+	}
+
+	// 查询房产信息
+	realEstate, err := s.getRealEstate(ctx, realEstateID)
+	if err != nil {
+		return err
+	}
+
+	err = s.checkNotRetired(realEstate, "冻结")
+	if err != nil {
+		return err
+	}
+	if realEstate.Status == FROZEN {
+		return fmt.Errorf("房产 %s 已被冻结", realEstateID)
+	}
+
+	freeze := &Freeze{
+		ID:             ctx.GetStub().GetTxID(),
+		RealEstateID:   realEstateID,
+		CaseNumber:     caseNumber,
+		Authority:      authority,
+		Operator:       clientMSPID,
+		PreviousStatus: realEstate.Status,
+		Status:         FREEZE_ACTIVE,
+		ExpireTime:     expire,
+		CreateTime:     createTime,
+		UpdateTime:     createTime,
+	}
+
+	// 更新房产状态
+	oldStatus := realEstate.Status
+	realEstate.Status = FROZEN
+	realEstate.UpdateTime = createTime
+
+	// 保存状态
+	err = s.putFreeze(ctx, freeze, "")
+	if err != nil {
+		return err
+	}
+
+	err = s.putIndex(ctx, FREEZE_REAL_ESTATE_INDEX, []string{realEstateID, freeze.ID})
+	if err != nil {
+		return err
+	}
+
+	return s.putRealEstate(ctx, realEstate, oldStatus)
+}
+
+// UnfreezeRealEstate 解除房产的司法冻结（仅拥有司法或登记权限的组织可以调用）
+func (s *SmartContract) UnfreezeRealEstate(ctx contractapi.TransactionContextInterface, realEstateID string, reason string) error {
+	// 验证调用者所在组织是否拥有司法或登记权限
+	err := s.checkCapability(ctx, "解除冻结", CAP_JUDICIAL, CAP_REGISTRY)
+	if err != nil {
+		return err
+	}
+
+	// 以账本交易时间作为更新时间
+	updateTime, err := s.getTxTime(ctx)
+	if err != nil {
+		return err
+	}
+
+	// 参数验证
+	if len(reason) == 0 {
+		return fmt.Errorf("解除原因不能为空")
+	}
+
+	freeze, err := s.getActiveFreeze(ctx, realEstateID)
+	if err != nil {
+		return err
+	}
+
+	freeze.LiftReason = reason
+	return s.closeFreeze(ctx, freeze, FREEZE_LIFTED, updateTime)
+}
+
+// ExpireFreeze 结束已到期的司法冻结（任何组织都可以调用）
+func (s *SmartContract) ExpireFreeze(ctx contractapi.TransactionContextInterface, realEstateID string) error {
+	freeze, err := s.getActiveFreeze(ctx, realEstateID)
+	if err != nil {
+		return err
+	}
+
+	// 以账本交易时间为准判断是否已到期
+	now, err := s.getTxTime(ctx)
+	if err != nil {
+		return err
+	}
+
+	if !now.After(freeze.ExpireTime) {
+		return fmt.Errorf("房产 %s 的冻结尚未到期，冻结期限至 %s", realEstateID, freeze.ExpireTime.Format(time.RFC3339))
+	}
+
+	return s.closeFreeze(ctx, freeze, FREEZE_EXPIRED, now)
+}
+
+// QueryFreezeList 分页查询司法冻结记录列表（status 为空时查询全部）
+func (s *SmartContract) QueryFreezeList(ctx contractapi.TransactionContextInterface, pageSize int32, bookmark string, status string) (*QueryResult, error) {
+	if status != "" {
+		return s.queryPage(ctx, FREEZE, FREEZE_STATUS_INDEX, []string{status}, pageSize, bookmark, decodeFreeze)
+	}
+	return s.queryPage(ctx, FREEZE, "", nil, pageSize, bookmark, decodeFreeze)
+}
+
+// decodeFreeze 解析司法冻结记录
+func decodeFreeze(bytes []byte) (interface{}, error) {
+	var freeze Freeze
+	err := json.Unmarshal(bytes, &freeze)
+	if err != nil {
+		return nil, fmt.Errorf("解析冻结记录失败：%v", err)
+	}
+	return freeze, nil
+}
+
+// 通用方法：结束冻结（冻结记录转为指定状态，房产恢复冻结前的状态）
+func (s *SmartContract) closeFreeze(ctx contractapi.TransactionContextInterface, freeze *Freeze, status FreezeStatus, updateTime time.Time) error {
+	// 查询房产信息
+	realEstate, err := s.getRealEstate(ctx, freeze.RealEstateID)
+	if err != nil {
+		return err
+	}
+
+	// 更新状态
+	realEstate.Status = freeze.PreviousStatus
+	realEstate.UpdateTime = updateTime
+
+	freeze.Status = status
+	freeze.UpdateTime = updateTime
+
+	// 保存状态
+	err = s.putFreeze(ctx, freeze, FREEZE_ACTIVE)
+	if err != nil {
+		return err
+	}
+
+	return s.putRealEstate(ctx, realEstate, FROZEN)
+}
+
+// 通用方法：查询房产的冻结历史（按冻结时间排序）
+func (s *SmartContract) getFreezeHistory(ctx contractapi.TransactionContextInterface, realEstateID string) ([]*Freeze, error) {
+	iterator, err := ctx.GetStub().GetStateByPartialCompositeKey(FREEZE_REAL_ESTATE_INDEX, []string{realEstateID})
+	if err != nil {
+		return nil, fmt.Errorf("查询冻结记录失败：%v", err)
+	}
+	defer iterator.Close()
+
+	freezes := make([]*Freeze, 0)
+	for iterator.HasNext() {
+		queryResponse, err := iterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("获取下一条记录失败：%v", err)
+		}
+
+		_, attributes, err := ctx.GetStub().SplitCompositeKey(queryResponse.Key)
+		if err != nil {
+			return nil, fmt.Errorf("解析复合键失败：%v", err)
+		}
+
+		key, err := s.getCompositeKey(ctx, FREEZE, []string{attributes[len(attributes)-1]})
+		if err != nil {
+			return nil, err
+		}
+
+		var freeze Freeze
+		err = s.getState(ctx, key, &freeze)
+		if err != nil {
+			return nil, err
+		}
+		freezes = append(freezes, &freeze)
+	}
+
+	sort.Slice(freezes, func(i, j int) bool {
+		return freezes[i].CreateTime.Before(freezes[j].CreateTime)
+	})
+	return freezes, nil
+}
+
+// 通用方法：获取房产当前生效的冻结记录
+func (s *SmartContract) getActiveFreeze(ctx contractapi.TransactionContextInterface, realEstateID string) (*Freeze, error) {
+	freezes, err := s.getFreezeHistory(ctx, realEstateID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, freeze := range freezes {
+		if freeze.Status == FREEZE_ACTIVE {
+			return freeze, nil
+		}
+	}
+	return nil, fmt.Errorf("房产 %s 未被冻结", realEstateID)
+}
+
+// 通用方法：检查房产是否被冻结
+func (s *SmartContract) checkNotFrozen(ctx contractapi.TransactionContextInterface, realEstate *RealEstate, action string) error {
+	if realEstate.Status != FROZEN {
+		return nil
+	}
+
+	freeze, err := s.getActiveFreeze(ctx, realEstate.ID)
+	if err != nil {
+		return err
+	}
+	return fmt.Errorf("房产 %s 已被司法冻结（案号 %s，%s），无法%s", realEstate.ID, freeze.CaseNumber, freeze.Authority, action)
+}
+
+// 通用方法：保存冻结记录并维护状态索引（oldStatus 为空表示新建）
+func (s *SmartContract) putFreeze(ctx contractapi.TransactionContextInterface, freeze *Freeze, oldStatus FreezeStatus) error {
+	key, err := s.getCompositeKey(ctx, FREEZE, []string{freeze.ID})
+	if err != nil {
+		return err
+	}
+
+	err = s.putState(ctx, key, freeze)
+	if err != nil {
+		return err
+	}
+
+	return s.updateStatusIndex(ctx, FREEZE_STATUS_INDEX, freeze.ID, string(oldStatus), string(freeze.Status))
+}
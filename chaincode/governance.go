@@ -0,0 +1,423 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/v2/contractapi"
+)
+
+// ORG_CAPABILITIES 组织权限治理记录文档类型（复合键：GOV-ORG_MSPID）
+const ORG_CAPABILITIES = "GOV-ORG"
+
+// GOVERNANCE_PROPOSAL 治理提案文档类型（复合键：GOV-PROP_提案ID）
+const GOVERNANCE_PROPOSAL = "GOV-PROP"
+
+// 组织权限常量
+const (
+	CAP_REGISTRY   = "REGISTRY"   // 登记：登记房产和交易方，校验交易私有数据
+	CAP_TRADING    = "TRADING"    // 交易：生成和取消交易，查询交易私有数据
+	CAP_SETTLEMENT = "SETTLEMENT" // 结算：完成和取消交易，办理抵押，查询交易私有数据
+	CAP_JUDICIAL   = "JUDICIAL"   // 司法：冻结和解除冻结房产
+	CAP_TAXATION   = "TAXATION"   // 税务：更新交易税费标准
+)
+
+// supportedCapabilities 可以授予组织的权限
+var supportedCapabilities = map[string]bool{
+	CAP_REGISTRY:   true,
+	CAP_TRADING:    true,
+	CAP_SETTLEMENT: true,
+	CAP_JUDICIAL:   true,
+	CAP_TAXATION:   true,
+}
+
+// defaultOrgCapabilities 账本上还没有治理记录时各初始组织的权限（首个提案生效时写入账本）
+var defaultOrgCapabilities = map[string][]string{
+	REALTY_ORG_MSPID: {CAP_REGISTRY},
+	BANK_ORG_MSPID:   {CAP_SETTLEMENT},
+	TRADE_ORG_MSPID:  {CAP_TRADING},
+}
+
+// ProposalStatus 治理提案状态
+type ProposalStatus string
+
+const (
+	PROPOSAL_PENDING ProposalStatus = "PENDING" // 待批准
+	PROPOSAL_APPLIED ProposalStatus = "APPLIED" // 已生效
+)
+
+// ProposalType 治理提案类型
+type ProposalType string
+
+const (
+	PROPOSAL_ORG_CAPABILITIES ProposalType = "ORG_CAPABILITIES" // 变更组织权限
+	PROPOSAL_ROLE_ENFORCEMENT ProposalType = "ROLE_ENFORCEMENT" // 启用或关闭角色检查
+)
+
+// OrgCapabilities 组织权限治理记录
+type OrgCapabilities struct {
+	MSPID        string    `json:"mspId"`        // 组织 MSP ID
+	Capabilities []string  `json:"capabilities"` // 组织拥有的权限
+	ProposalID   string    `json:"proposalId"`   // 使该记录生效的提案ID（初始组织为空）
+	UpdateTime   time.Time `json:"updateTime"`   // 更新时间
+}
+
+// GovernanceProposal 治理提案（由现有组织过半数批准后生效）
+//
+// 变更组织权限的提案中权限为空表示移除该组织；角色检查提案只设置 RoleEnforcement
+type GovernanceProposal struct {
+	ID              string         `json:"id"`                        // 提案ID
+	Type            ProposalType   `json:"type"`                      // 提案类型（旧版本的提案为空，按变更组织权限处理）
+	MSPID           string         `json:"mspId"`                     // 目标组织 MSP ID
+	Capabilities    []string       `json:"capabilities"`              // 变更后的权限
+	RoleEnforcement *bool          `json:"roleEnforcement,omitempty"` // 是否启用角色检查（仅角色检查提案）
+	Proposer        string         `json:"proposer"`                  // 提案组织 MSP ID
+	Approvals       []string       `json:"approvals"`                 // 已批准的组织 MSP ID
+	Status          ProposalStatus `json:"status"`                    // 提案状态
+	CreateTime      time.Time      `json:"createTime"`                // 创建时间
+	UpdateTime      time.Time      `json:"updateTime"`                // 更新时间
+}
+
+// ProposeOrgCapabilities 提议变更组织权限（仅现有组织成员可以调用，提案组织视为已批准）
+//
+// 目标组织可以是尚未加入治理的新组织；capabilities 为空表示移除该组织的全部权限
+func (s *SmartContract) ProposeOrgCapabilities(ctx contractapi.TransactionContextInterface, proposalID string, mspID string, capabilities []string) error {
+	clientMSPID, members, err := s.checkGovernanceMember(ctx, "提交治理提案")
+	if err != nil {
+		return err
+	}
+
+	// 以账本交易时间作为创建时间
+	createTime, err := s.getTxTime(ctx)
+	if err != nil {
+		return err
+	}
+
+	// 参数验证
+	if len(mspID) == 0 || strings.TrimSpace(mspID) != mspID {
+		return fmt.Errorf("组织 MSP ID 不能为空，且不能包含首尾空白字符")
+	}
+	capabilities, err = normalizeCapabilities(capabilities)
+	if err != nil {
+		return err
+	}
+	if len(capabilities) == 0 {
+		if _, ok := members[mspID]; !ok {
+			return fmt.Errorf("组织 %s 没有任何权限，无需移除", mspID)
+		}
+		if len(members) == 1 {
+			return fmt.Errorf("不能移除最后一个组织")
+		}
+	}
+
+	err = s.checkProposalNotExists(ctx, proposalID)
+	if err != nil {
+		return err
+	}
+
+	proposal := &GovernanceProposal{
+		ID:           proposalID,
+		Type:         PROPOSAL_ORG_CAPABILITIES,
+		MSPID:        mspID,
+		Capabilities: capabilities,
+		Proposer:     clientMSPID,
+		Approvals:    []string{clientMSPID},
+		Status:       PROPOSAL_PENDING,
+		CreateTime:   createTime,
+		UpdateTime:   createTime,
+	}
+
+	return s.applyProposalIfApproved(ctx, proposal, members, createTime)
+}
+
+// ApproveGovernanceProposal 批准治理提案（仅现有组织成员可以调用，过半数组织批准后立即生效）
+func (s *SmartContract) ApproveGovernanceProposal(ctx contractapi.TransactionContextInterface, proposalID string) error {
+	clientMSPID, members, err := s.checkGovernanceMember(ctx, "批准治理提案")
+	if err != nil {
+		return err
+	}
+
+	// 以账本交易时间作为更新时间
+	updateTime, err := s.getTxTime(ctx)
+	if err != nil {
+		return err
+	}
+
+	proposal, err := s.getGovernanceProposal(ctx, proposalID)
+	if err != nil {
+		return err
+	}
+	if proposal.Status != PROPOSAL_PENDING {
+		return fmt.Errorf("提案 %s 已生效，无需批准", proposalID)
+	}
+	for _, approval := range proposal.Approvals {
+		if approval == clientMSPID {
+			return fmt.Errorf("组织 %s 已批准过提案 %s", clientMSPID, proposalID)
+		}
+	}
+
+	proposal.Approvals = append(proposal.Approvals, clientMSPID)
+	proposal.UpdateTime = updateTime
+	return s.applyProposalIfApproved(ctx, proposal, members, updateTime)
+}
+
+// QueryGovernanceProposal 查询治理提案
+func (s *SmartContract) QueryGovernanceProposal(ctx contractapi.TransactionContextInterface, proposalID string) (*GovernanceProposal, error) {
+	return s.getGovernanceProposal(ctx, proposalID)
+}
+
+// QueryGovernanceProposalList 分页查询治理提案列表
+func (s *SmartContract) QueryGovernanceProposalList(ctx contractapi.TransactionContextInterface, pageSize int32, bookmark string) (*QueryResult, error) {
+	return s.queryPage(ctx, GOVERNANCE_PROPOSAL, "", nil, pageSize, bookmark, decodeGovernanceProposal)
+}
+
+// QueryOrgCapabilities 查询组织拥有的权限（没有任何权限时返回空列表）
+func (s *SmartContract) QueryOrgCapabilities(ctx contractapi.TransactionContextInterface, mspID string) (*OrgCapabilities, error) {
+	members, err := s.getOrgCapabilitiesMap(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if record, ok := members[mspID]; ok {
+		return record, nil
+	}
+	return &OrgCapabilities{MSPID: mspID, Capabilities: []string{}}, nil
+}
+
+// QueryOrgCapabilitiesList 查询所有参与治理的组织及其权限
+func (s *SmartContract) QueryOrgCapabilitiesList(ctx contractapi.TransactionContextInterface) ([]*OrgCapabilities, error) {
+	members, err := s.getOrgCapabilitiesMap(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	records := make([]*OrgCapabilities, 0, len(members))
+	for _, record := range members {
+		records = append(records, record)
+	}
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].MSPID < records[j].MSPID
+	})
+	return records, nil
+}
+
+// decodeGovernanceProposal 解析治理提案
+func decodeGovernanceProposal(bytes []byte) (interface{}, error) {
+	var proposal GovernanceProposal
+	err := json.Unmarshal(bytes, &proposal)
+	if err != nil {
+		return nil, fmt.Errorf("解析治理提案失败：%v", err)
+	}
+	return proposal, nil
+}
+
+// 通用方法：检查调用者所在组织是否拥有任一指定权限
+func (s *SmartContract) checkCapability(ctx contractapi.TransactionContextInterface, action string, capabilities ...string) error {
+	clientMSPID, ok, err := s.hasCapability(ctx, capabilities...)
+	if err != nil {
+		return err
+	}
+	if ok {
+		return nil
+	}
+
+	return fmt.Errorf("只有拥有 %s 权限的组织成员才能%s，组织 %s 没有该权限", strings.Join(capabilities, " 或 "), action, clientMSPID)
+}
+
+// 通用方法：判断调用者所在组织是否拥有任一指定权限，同时返回调用者 MSP ID
+func (s *SmartContract) hasCapability(ctx contractapi.TransactionContextInterface, capabilities ...string) (string, bool, error) {
+	clientMSPID, err := s.getClientIdentityMSPID(ctx)
+	if err != nil {
+		return "", false, fmt.Errorf("获取调用者身份失败：%v", err)
+	}
+
+	members, err := s.getOrgCapabilitiesMap(ctx)
+	if err != nil {
+		return "", false, err
+	}
+	if record, ok := members[clientMSPID]; ok {
+		for _, owned := range record.Capabilities {
+			for _, required := range capabilities {
+				if owned == required {
+					return clientMSPID, true, nil
+				}
+			}
+		}
+	}
+	return clientMSPID, false, nil
+}
+
+// 通用方法：检查调用者所在组织是否参与治理，返回调用者 MSP ID 和当前参与治理的组织
+func (s *SmartContract) checkGovernanceMember(ctx contractapi.TransactionContextInterface, action string) (string, map[string]*OrgCapabilities, error) {
+	clientMSPID, err := s.getClientIdentityMSPID(ctx)
+	if err != nil {
+		return "", nil, fmt.Errorf("获取调用者身份失败：%v", err)
+	}
+
+	members, err := s.getOrgCapabilitiesMap(ctx)
+	if err != nil {
+		return "", nil, err
+	}
+	if _, ok := members[clientMSPID]; !ok {
+		return "", nil, fmt.Errorf("只有参与治理的组织成员才能%s", action)
+	}
+	return clientMSPID, members, nil
+}
+
+// 通用方法：获取所有参与治理的组织权限（账本上没有治理记录时使用初始组织的默认权限）
+func (s *SmartContract) getOrgCapabilitiesMap(ctx contractapi.TransactionContextInterface) (map[string]*OrgCapabilities, error) {
+	iterator, err := ctx.GetStub().GetStateByPartialCompositeKey(ORG_CAPABILITIES, []string{})
+	if err != nil {
+		return nil, fmt.Errorf("查询组织权限失败：%v", err)
+	}
+	defer iterator.Close()
+
+	members := make(map[string]*OrgCapabilities)
+	for iterator.HasNext() {
+		queryResponse, err := iterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("获取下一条记录失败：%v", err)
+		}
+
+		var record OrgCapabilities
+		err = json.Unmarshal(queryResponse.Value, &record)
+		if err != nil {
+			return nil, fmt.Errorf("解析组织权限失败：%v", err)
+		}
+		members[record.MSPID] = &record
+	}
+
+	if len(members) == 0 {
+		for mspID, capabilities := range defaultOrgCapabilities {
+			members[mspID] = &OrgCapabilities{MSPID: mspID, Capabilities: capabilities}
+		}
+	}
+	return members, nil
+}
+
+// 通用方法：保存提案，现有组织过半数批准时写入组织权限并将提案标记为已生效
+func (s *SmartContract) applyProposalIfApproved(ctx contractapi.TransactionContextInterface, proposal *GovernanceProposal, members map[string]*OrgCapabilities, updateTime time.Time) error {
+	// 只统计当前仍参与治理的组织
+	approved := 0
+	for _, approval := range proposal.Approvals {
+		if _, ok := members[approval]; ok {
+			approved++
+		}
+	}
+
+	if approved*2 > len(members) {
+		// 首个提案生效时把初始组织的默认权限写入账本
+		for _, record := range members {
+			if record.UpdateTime.IsZero() {
+				record.UpdateTime = updateTime
+				err := s.putOrgCapabilities(ctx, record)
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+		var err error
+		switch proposal.Type {
+		case PROPOSAL_ROLE_ENFORCEMENT:
+			err = s.putRoleEnforcement(ctx, *proposal.RoleEnforcement)
+		default:
+			err = s.putOrgCapabilities(ctx, &OrgCapabilities{
+				MSPID:        proposal.MSPID,
+				Capabilities: proposal.Capabilities,
+				ProposalID:   proposal.ID,
+				UpdateTime:   updateTime,
+			})
+		}
+		if err != nil {
+			return err
+		}
+		proposal.Status = PROPOSAL_APPLIED
+	}
+
+	key, err := s.getCompositeKey(ctx, GOVERNANCE_PROPOSAL, []string{proposal.ID})
+	if err != nil {
+		return err
+	}
+	return s.putState(ctx, key, proposal)
+}
+
+// 通用方法：保存组织权限（权限为空时删除记录，该组织不再参与治理）
+func (s *SmartContract) putOrgCapabilities(ctx contractapi.TransactionContextInterface, record *OrgCapabilities) error {
+	key, err := s.getCompositeKey(ctx, ORG_CAPABILITIES, []string{record.MSPID})
+	if err != nil {
+		return err
+	}
+
+	if len(record.Capabilities) == 0 {
+		err = ctx.GetStub().DelState(key)
+		if err != nil {
+			return fmt.Errorf("删除组织权限失败：%v", err)
+		}
+		return nil
+	}
+	return s.putState(ctx, key, record)
+}
+
+// 通用方法：检查提案ID是否已被使用
+func (s *SmartContract) checkProposalNotExists(ctx contractapi.TransactionContextInterface, proposalID string) error {
+	if len(proposalID) == 0 {
+		return fmt.Errorf("提案ID不能为空")
+	}
+
+	key, err := s.getCompositeKey(ctx, GOVERNANCE_PROPOSAL, []string{proposalID})
+	if err != nil {
+		return err
+	}
+
+	exists, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return fmt.Errorf("查询治理提案失败：%v", err)
+	}
+	if exists != nil {
+		return fmt.Errorf("提案ID %s 已存在", proposalID)
+	}
+	return nil
+}
+
+// 通用方法：获取治理提案
+func (s *SmartContract) getGovernanceProposal(ctx contractapi.TransactionContextInterface, proposalID string) (*GovernanceProposal, error) {
+	key, err := s.getCompositeKey(ctx, GOVERNANCE_PROPOSAL, []string{proposalID})
+	if err != nil {
+		return nil, err
+	}
+
+	bytes, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return nil, fmt.Errorf("查询治理提案失败：%v", err)
+	}
+	if bytes == nil {
+		return nil, fmt.Errorf("提案ID %s 不存在", proposalID)
+	}
+
+	var proposal GovernanceProposal
+	err = json.Unmarshal(bytes, &proposal)
+	if err != nil {
+		return nil, fmt.Errorf("解析治理提案失败：%v", err)
+	}
+	return &proposal, nil
+}
+
+// 通用方法：校验权限列表，去重并排序
+func normalizeCapabilities(capabilities []string) ([]string, error) {
+	seen := make(map[string]bool)
+	result := make([]string, 0, len(capabilities))
+	for _, capability := range capabilities {
+		if !supportedCapabilities[capability] {
+			return nil, fmt.Errorf("不支持的权限 %q", capability)
+		}
+		if !seen[capability] {
+			seen[capability] = true
+			result = append(result, capability)
+		}
+	}
+	sort.Strings(result)
+	return result, nil
+}
@@ -0,0 +1,168 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/hyperledger/fabric-chaincode-go/v2/pkg/cid"
+	"github.com/hyperledger/fabric-contract-api-go/v2/contractapi"
+)
+
+// 旧存储格式（复合键：类型_状态_ID）中使用过的状态，仅用于迁移和合并历史记录
+var (
+	legacyRealEstateStatuses  = []string{"NORMAL", "IN_TRANSACTION"}
+	legacyTransactionStatuses = []string{"PENDING", "COMPLETED", "CANCELLED", "EXPIRED"}
+)
+
+// MigrationResult 存储迁移结果
+type MigrationResult struct {
+	Migrated int32 `json:"migrated"` // 本次迁移的记录数
+	HasMore  bool  `json:"hasMore"`  // 是否还有未迁移的记录
+}
+
+// MigrateStorage 将旧格式（类型_状态_ID）的记录迁移为主键（类型_ID）加状态、所有者、交易方索引（仅组织管理员可以调用）
+//
+// 每次最多迁移 pageSize 条记录，已迁移的旧键会被删除，重复调用直到 hasMore 为 false 即可完成迁移
+func (s *SmartContract) MigrateStorage(ctx contractapi.TransactionContextInterface, pageSize int32) (*MigrationResult, error) {
+	// 检查调用者是否为组织管理员
+	isAdmin, err := cid.HasOUValue(ctx.GetStub(), "admin")
+	if err != nil {
+		return nil, fmt.Errorf("获取调用者身份失败：%v", err)
+	}
+	if !isAdmin {
+		return nil, fmt.Errorf("只有组织管理员才能迁移存储")
+	}
+
+	if pageSize <= 0 {
+		return nil, fmt.Errorf("每页记录数必须大于0")
+	}
+
+	result := &MigrationResult{}
+	targets := []struct {
+		objectType string
+		indexName  string
+		statuses   []string
+	}{
+		{REAL_ESTATE, REAL_ESTATE_STATUS_INDEX, legacyRealEstateStatuses},
+		{TRANSACTION, TRANSACTION_STATUS_INDEX, legacyTransactionStatuses},
+	}
+
+	for _, target := range targets {
+		for _, status := range target.statuses {
+			remaining := pageSize - result.Migrated
+			if remaining <= 0 {
+				result.HasMore = true
+				return result, nil
+			}
+
+			migrated, err := s.migrateLegacyKeys(ctx, target.objectType, target.indexName, status, remaining)
+			if err != nil {
+				return nil, err
+			}
+			result.Migrated += migrated
+		}
+	}
+
+	result.HasMore = result.Migrated >= pageSize
+	return result, nil
+}
+
+// 通用方法：迁移指定状态下的旧格式记录，返回迁移的记录数
+func (s *SmartContract) migrateLegacyKeys(ctx contractapi.TransactionContextInterface, objectType string, indexName string, status string, pageSize int32) (int32, error) {
+	// 分页查询只支持只读交易，这里使用普通范围查询并在达到 pageSize 后停止
+	iterator, err := ctx.GetStub().GetStateByPartialCompositeKey(objectType, []string{status})
+	if err != nil {
+		return 0, fmt.Errorf("查询旧格式记录失败：%v", err)
+	}
+	defer iterator.Close()
+
+	var migrated int32
+	for iterator.HasNext() && migrated < pageSize {
+		queryResponse, err := iterator.Next()
+		if err != nil {
+			return 0, fmt.Errorf("获取下一条记录失败：%v", err)
+		}
+
+		_, attributes, err := ctx.GetStub().SplitCompositeKey(queryResponse.Key)
+		if err != nil {
+			return 0, fmt.Errorf("解析复合键失败：%v", err)
+		}
+		// 新格式主键只有ID一个属性，不需要迁移
+		if len(attributes) != 2 {
+			continue
+		}
+		id := attributes[1]
+
+		key, err := s.getCompositeKey(ctx, objectType, []string{id})
+		if err != nil {
+			return 0, err
+		}
+
+		err = s.putLegacyRecord(ctx, objectType, key, queryResponse.Value)
+		if err != nil {
+			return 0, err
+		}
+
+		// 迁移的房产与新建的房产一样，之后的修改必须经过登记机构背书
+		if objectType == REAL_ESTATE {
+			err = s.setEndorsementPolicy(ctx, key, []string{REALTY_ORG_MSPID})
+			if err != nil {
+				return 0, err
+			}
+		}
+
+		err = s.updateStatusIndex(ctx, indexName, id, "", status)
+		if err != nil {
+			return 0, err
+		}
+
+		err = ctx.GetStub().DelState(queryResponse.Key)
+		if err != nil {
+			return 0, fmt.Errorf("删除旧格式记录失败：%v", err)
+		}
+
+		migrated++
+	}
+
+	return migrated, nil
+}
+
+// 通用方法：将旧格式记录保存到新主键，并写入所有者索引或交易方索引
+//
+// 旧版本房产只有单一所有者（currentOwner），解析时转换为持有全部份额的所有者后以当前格式保存，
+// 交易按原样保存（旧版本交易公开状态中的买家和价格由交易相关方法兼容读取）
+func (s *SmartContract) putLegacyRecord(ctx contractapi.TransactionContextInterface, objectType string, key string, value []byte) error {
+	switch objectType {
+	case REAL_ESTATE:
+		var realEstate RealEstate
+		err := json.Unmarshal(value, &realEstate)
+		if err != nil {
+			return fmt.Errorf("解析房产信息失败：%v", err)
+		}
+		if len(realEstate.Owners) == 0 {
+			return fmt.Errorf("房产 %s 缺少所有者信息，无法迁移", realEstate.ID)
+		}
+
+		realEstateJSON, err := json.Marshal(realEstate)
+		if err != nil {
+			return fmt.Errorf("序列化房产信息失败：%v", err)
+		}
+		err = ctx.GetStub().PutState(key, realEstateJSON)
+		if err != nil {
+			return fmt.Errorf("保存房产信息失败：%v", err)
+		}
+		return s.updateOwnerIndex(ctx, realEstate.ID, nil, realEstate.Owners)
+	case TRANSACTION:
+		var transaction Transaction
+		err := json.Unmarshal(value, &transaction)
+		if err != nil {
+			return fmt.Errorf("解析交易信息失败：%v", err)
+		}
+		err = ctx.GetStub().PutState(key, value)
+		if err != nil {
+			return fmt.Errorf("保存交易信息失败：%v", err)
+		}
+		return s.putPartyIndex(ctx, &transaction)
+	}
+	return nil
+}
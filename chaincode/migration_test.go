@@ -0,0 +1,91 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/hyperledger/fabric-chaincode-go/v2/shim"
+)
+
+// putLegacyState 以旧存储格式（类型_状态_ID）写入记录
+func putLegacyState(t *testing.T, stub *mockStub, objectType string, status string, id string, value string) {
+	t.Helper()
+
+	key, err := shim.CreateCompositeKey(objectType, []string{status, id})
+	if err != nil {
+		t.Fatal(err)
+	}
+	stub.state[key] = []byte(value)
+}
+
+func TestMigrateStorageKeepsLegacyOwner(t *testing.T) {
+	s := &SmartContract{}
+	stub := newMockStub()
+	ctx := newTestContext(stub)
+	stub.setCaller(t, REALTY_ORG_MSPID, "admin", nil)
+
+	putLegacyState(t, stub, REAL_ESTATE, "NORMAL", "R1", `{"id":"R1","propertyAddress":"北京市朝阳区","area":89.5,"currentOwner":"S","status":"NORMAL","createTime":"2024-01-01T00:00:00Z","updateTime":"2024-01-01T00:00:00Z"}`)
+	putLegacyState(t, stub, TRANSACTION, "COMPLETED", "T1", `{"id":"T1","realEstateId":"R1","seller":"P","buyer":"S","price":1000000.5,"status":"COMPLETED","createTime":"2024-01-01T00:00:00Z","updateTime":"2024-01-01T00:00:00Z"}`)
+
+	result, err := s.MigrateStorage(ctx, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Migrated != 2 || result.HasMore {
+		t.Fatalf("迁移结果 = %+v，期望迁移2条且没有剩余记录", result)
+	}
+
+	realEstate, err := s.getRealEstate(ctx, "R1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(realEstate.Owners) != 1 || realEstate.Owners[0] != (Owner{ID: "S", Share: FULL_SHARE}) {
+		t.Fatalf("所有者 = %+v，期望 S 持有全部份额", realEstate.Owners)
+	}
+	if realEstate.Area != "89.50" {
+		t.Fatalf("面积 = %s，期望 89.50", realEstate.Area)
+	}
+
+	// 迁移后以当前格式保存，不再依赖解析时的兼容处理
+	key, err := shim.CreateCompositeKey(REAL_ESTATE, []string{"R1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var stored map[string]json.RawMessage
+	if err := json.Unmarshal(stub.state[key], &stored); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := stored["currentOwner"]; ok {
+		t.Fatalf("迁移后的房产仍保存旧格式的 currentOwner 字段：%s", stub.state[key])
+	}
+
+	owned, err := s.QueryRealEstateByOwner(ctx, "S", 10, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if owned.RecordsCount != 1 {
+		t.Fatalf("按所有者 S 查询到 %d 条房产，期望1条", owned.RecordsCount)
+	}
+
+	for _, party := range []string{"P", "S"} {
+		transactions, err := s.QueryTransactionsByParty(ctx, party, 10, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if transactions.RecordsCount != 1 {
+			t.Fatalf("按交易方 %s 查询到 %d 条交易，期望1条", party, transactions.RecordsCount)
+		}
+		transaction := transactions.Records[0].(Transaction)
+		if transaction.Share != FULL_SHARE {
+			t.Fatalf("旧版本交易的出售份额 = %d，期望 %d", transaction.Share, FULL_SHARE)
+		}
+	}
+
+	legacyKey, err := shim.CreateCompositeKey(REAL_ESTATE, []string{"NORMAL", "R1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := stub.state[legacyKey]; ok {
+		t.Fatal("旧格式的房产记录没有删除")
+	}
+}
@@ -0,0 +1,262 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/v2/contractapi"
+)
+
+// MORTGAGE 抵押信息文档类型（复合键：MG_房产ID_抵押ID）
+const MORTGAGE = "MG"
+
+// MortgageStatus 抵押状态
+type MortgageStatus string
+
+const (
+	MORTGAGE_ACTIVE   MortgageStatus = "ACTIVE"   // 抵押中
+	MORTGAGE_RELEASED MortgageStatus = "RELEASED" // 已注销
+)
+
+// Mortgage 抵押信息
+type Mortgage struct {
+	ID              string         `json:"id"`              // 抵押ID
+	RealEstateID    string         `json:"realEstateId"`    // 房产ID
+	Mortgagee       string         `json:"mortgagee"`       // 抵押权人（登记抵押的银行组织 MSP ID）
+	Amount          Money          `json:"amount"`          // 担保金额
+	Status          MortgageStatus `json:"status"`          // 状态
+	TransferConsent bool           `json:"transferConsent"` // 抵押权人是否同意转让房产
+	CreateTime      time.Time      `json:"createTime"`      // 创建时间
+	UpdateTime      time.Time      `json:"updateTime"`      // 更新时间
+}
+
+// RegisterMortgage 登记抵押（仅拥有结算权限的组织可以调用，amount 为十进制字符串，currency 为 ISO 4217 币种代码）
+func (s *SmartContract) RegisterMortgage(ctx contractapi.TransactionContextInterface, id string, realEstateID string, amount string, currency string) error {
+	// 检查调用者身份
+	clientMSPID, err := s.getClientIdentityMSPID(ctx)
+	if err != nil {
+		return fmt.Errorf("获取调用者身份失败：%v", err)
+	}
+
+	// 验证调用者所在组织是否拥有结算权限
+	err = s.checkCapability(ctx, "登记抵押", CAP_SETTLEMENT)
+	if err != nil {
+		return err
+	}
+
+	// 以账本交易时间作为创建时间
+	createTime, err := s.getTxTime(ctx)
+	if err != nil {
+		return err
+	}
+
+	// 参数验证
+	if len(id) == 0 {
+		return fmt.Errorf("抵押ID不能为空")
+	}
+	if len(realEstateID) == 0 {
+		return fmt.Errorf("房产ID不能为空")
+	}
+	amountMoney, err := parseMoney(amount, currency)
+	if err != nil {
+		return fmt.Errorf("担保金额无效：%v", err)
+	}
+
+	// 只有正常状态的房产可以登记抵押
+	realEstate, err := s.getRealEstate(ctx, realEstateID)
+	if err != nil {
+		return err
+	}
+	err = s.checkNotRetired(realEstate, "登记抵押")
+	if err != nil {
+		return err
+	}
+	if realEstate.Status != NORMAL {
+		return fmt.Errorf("房产 %s 当前状态为 %s，无法登记抵押", realEstateID, realEstate.Status)
+	}
+
+	// 检查抵押是否已存在
+	key, err := s.getCompositeKey(ctx, MORTGAGE, []string{realEstateID, id})
+	if err != nil {
+		return err
+	}
+
+	exists, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return fmt.Errorf("查询抵押信息失败：%v", err)
+	}
+	if exists != nil {
+		return fmt.Errorf("抵押ID %s 已存在", id)
+	}
+
+	mortgage := Mortgage{
+		ID:           id,
+		RealEstateID: realEstateID,
+		Mortgagee:    clientMSPID,
+		Amount:       amountMoney,
+		Status:       MORTGAGE_ACTIVE,
+		CreateTime:   createTime,
+		UpdateTime:   createTime,
+	}
+
+	return s.putState(ctx, key, mortgage)
+}
+
+// ConsentMortgageTransfer 抵押权人同意转让抵押房产（仅登记该抵押的组织可以调用）
+func (s *SmartContract) ConsentMortgageTransfer(ctx contractapi.TransactionContextInterface, realEstateID string, mortgageID string) error {
+	mortgage, key, err := s.getMortgageAsMortgagee(ctx, realEstateID, mortgageID)
+	if err != nil {
+		return err
+	}
+
+	// 以账本交易时间作为更新时间
+	updateTime, err := s.getTxTime(ctx)
+	if err != nil {
+		return err
+	}
+
+	if mortgage.Status != MORTGAGE_ACTIVE {
+		return fmt.Errorf("抵押 %s 已注销，无需同意转让", mortgageID)
+	}
+
+	mortgage.TransferConsent = true
+	mortgage.UpdateTime = updateTime
+
+	return s.putState(ctx, key, mortgage)
+}
+
+// ReleaseMortgage 注销抵押（仅登记该抵押的组织可以调用）
+func (s *SmartContract) ReleaseMortgage(ctx contractapi.TransactionContextInterface, realEstateID string, mortgageID string) error {
+	mortgage, key, err := s.getMortgageAsMortgagee(ctx, realEstateID, mortgageID)
+	if err != nil {
+		return err
+	}
+
+	// 以账本交易时间作为更新时间
+	updateTime, err := s.getTxTime(ctx)
+	if err != nil {
+		return err
+	}
+
+	if mortgage.Status != MORTGAGE_ACTIVE {
+		return fmt.Errorf("抵押 %s 已注销", mortgageID)
+	}
+
+	mortgage.Status = MORTGAGE_RELEASED
+	mortgage.UpdateTime = updateTime
+
+	return s.putState(ctx, key, mortgage)
+}
+
+// QueryMortgageList 查询房产的抵押列表
+func (s *SmartContract) QueryMortgageList(ctx contractapi.TransactionContextInterface, realEstateID string) ([]*Mortgage, error) {
+	if len(realEstateID) == 0 {
+		return nil, fmt.Errorf("房产ID不能为空")
+	}
+
+	iterator, err := ctx.GetStub().GetStateByPartialCompositeKey(MORTGAGE, []string{realEstateID})
+	if err != nil {
+		return nil, fmt.Errorf("查询抵押列表失败：%v", err)
+	}
+	defer iterator.Close()
+
+	mortgages := make([]*Mortgage, 0)
+	for iterator.HasNext() {
+		queryResponse, err := iterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("获取下一条记录失败：%v", err)
+		}
+
+		var mortgage Mortgage
+		err = json.Unmarshal(queryResponse.Value, &mortgage)
+		if err != nil {
+			return nil, fmt.Errorf("解析抵押信息失败：%v", err)
+		}
+
+		mortgages = append(mortgages, &mortgage)
+	}
+
+	return mortgages, nil
+}
+
+// 通用方法：获取抵押信息，并校验调用者是该抵押的抵押权人
+func (s *SmartContract) getMortgageAsMortgagee(ctx contractapi.TransactionContextInterface, realEstateID string, mortgageID string) (*Mortgage, string, error) {
+	// 检查调用者身份
+	clientMSPID, err := s.getClientIdentityMSPID(ctx)
+	if err != nil {
+		return nil, "", fmt.Errorf("获取调用者身份失败：%v", err)
+	}
+
+	// 验证调用者所在组织是否拥有结算权限
+	err = s.checkCapability(ctx, "操作抵押", CAP_SETTLEMENT)
+	if err != nil {
+		return nil, "", err
+	}
+
+	key, err := s.getCompositeKey(ctx, MORTGAGE, []string{realEstateID, mortgageID})
+	if err != nil {
+		return nil, "", err
+	}
+
+	var mortgage Mortgage
+	err = s.getState(ctx, key, &mortgage)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if mortgage.Mortgagee != clientMSPID {
+		return nil, "", fmt.Errorf("只有抵押权人才能操作抵押 %s", mortgageID)
+	}
+
+	return &mortgage, key, nil
+}
+
+// 通用方法：检查房产是否存在未经抵押权人同意转让的抵押
+func (s *SmartContract) checkMortgageConsent(ctx contractapi.TransactionContextInterface, realEstateID string) error {
+	mortgages, err := s.QueryMortgageList(ctx, realEstateID)
+	if err != nil {
+		return err
+	}
+
+	for _, mortgage := range mortgages {
+		if mortgage.Status == MORTGAGE_ACTIVE && !mortgage.TransferConsent {
+			return fmt.Errorf("房产存在抵押 %s，抵押权人尚未同意转让", mortgage.ID)
+		}
+	}
+	return nil
+}
+
+// 通用方法：交易完成时处理抵押权人已同意转让的抵押
+//
+// 转让全部份额时注销抵押；只转让部分份额时抵押仍担保整个房产，保持抵押中并收回转让同意，下次转让需要抵押权人重新同意
+func (s *SmartContract) releaseConsentedMortgages(ctx contractapi.TransactionContextInterface, realEstateID string, share int, updateTime time.Time) error {
+	mortgages, err := s.QueryMortgageList(ctx, realEstateID)
+	if err != nil {
+		return err
+	}
+
+	for _, mortgage := range mortgages {
+		if mortgage.Status != MORTGAGE_ACTIVE || !mortgage.TransferConsent {
+			continue
+		}
+
+		key, err := s.getCompositeKey(ctx, MORTGAGE, []string{realEstateID, mortgage.ID})
+		if err != nil {
+			return err
+		}
+
+		if share == FULL_SHARE {
+			mortgage.Status = MORTGAGE_RELEASED
+		} else {
+			mortgage.TransferConsent = false
+		}
+		mortgage.UpdateTime = updateTime
+
+		err = s.putState(ctx, key, mortgage)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
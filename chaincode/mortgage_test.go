@@ -0,0 +1,61 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestReleaseConsentedMortgages(t *testing.T) {
+	tests := []struct {
+		name        string
+		share       int
+		consent     bool
+		wantStatus  MortgageStatus
+		wantConsent bool
+	}{
+		{"转让全部份额注销抵押", FULL_SHARE, true, MORTGAGE_RELEASED, true},
+		{"转让部分份额保留抵押并收回同意", 4000, true, MORTGAGE_ACTIVE, false},
+		{"未同意转让的抵押不处理", FULL_SHARE, false, MORTGAGE_ACTIVE, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &SmartContract{}
+			stub := newMockStub()
+			ctx := newTestContext(stub)
+
+			key, err := s.getCompositeKey(ctx, MORTGAGE, []string{"R1", "M1"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = s.putState(ctx, key, Mortgage{
+				ID:              "M1",
+				RealEstateID:    "R1",
+				Mortgagee:       BANK_ORG_MSPID,
+				Status:          MORTGAGE_ACTIVE,
+				TransferConsent: tt.consent,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err = s.releaseConsentedMortgages(ctx, "R1", tt.share, stub.txTime)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			mortgages, err := s.QueryMortgageList(ctx, "R1")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(mortgages) != 1 {
+				t.Fatalf("抵押数量 = %d，期望 1", len(mortgages))
+			}
+			if mortgages[0].Status != tt.wantStatus {
+				t.Fatalf("抵押状态 = %s，期望 %s", mortgages[0].Status, tt.wantStatus)
+			}
+			if mortgages[0].TransferConsent != tt.wantConsent {
+				t.Fatalf("转让同意 = %v，期望 %v", mortgages[0].TransferConsent, tt.wantConsent)
+			}
+		})
+	}
+}
@@ -0,0 +1,676 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/v2/contractapi"
+)
+
+// OFFER 报价文档类型（复合键：OF_报价ID）
+const OFFER = "OF"
+
+// OFFER_REAL_ESTATE_INDEX 报价房产索引（复合键：OF-RE_房产ID_报价ID，用于按房产查询报价）
+const OFFER_REAL_ESTATE_INDEX = "OF-RE"
+
+// OFFER_BUYER_INDEX 报价买家索引（保存在交易私有数据集合中，复合键：OF-BUYER_买家_报价ID，用于删除交易方前检查其待答复的报价）
+const OFFER_BUYER_INDEX = "OF-BUYER"
+
+// OFFER_RESPONDER_TRANSIENT_KEY 答复报价时通过 transient map 传入答复方（交易方ID）使用的键
+//
+// 卖家还价后由买家答复，答复方作为交易参数会公开买家身份，因此与其他私有数据一样通过 transient map 传入
+const OFFER_RESPONDER_TRANSIENT_KEY = "responder"
+
+// OFFER_RESPONSE_SIGNATURE_TRANSIENT_KEY 答复报价时通过 transient map 传入答复方对答复内容签名使用的键
+//
+// 答复方由调用的客户端传入，必须用答复方登记的公钥验证签名，否则客户端可以冒充对方答复报价
+const OFFER_RESPONSE_SIGNATURE_TRANSIENT_KEY = "responseSignature"
+
+// OfferStatus 报价状态
+type OfferStatus string
+
+const (
+	OFFER_PENDING   OfferStatus = "PENDING"   // 待答复
+	OFFER_COUNTERED OfferStatus = "COUNTERED" // 已还价（由新的报价取代）
+	OFFER_ACCEPTED  OfferStatus = "ACCEPTED"  // 已接受（已生成交易）
+	OFFER_REJECTED  OfferStatus = "REJECTED"  // 已拒绝
+	OFFER_CLOSED    OfferStatus = "CLOSED"    // 已失效（同一房产的其他报价已被接受）
+)
+
+// OfferParty 报价方
+type OfferParty string
+
+const (
+	OFFER_BY_BUYER  OfferParty = "BUYER"  // 买家出价
+	OFFER_BY_SELLER OfferParty = "SELLER" // 卖家还价
+)
+
+// OfferAction 答复报价的方式
+type OfferAction string
+
+const (
+	OFFER_ACTION_COUNTER OfferAction = "COUNTER" // 还价
+	OFFER_ACTION_ACCEPT  OfferAction = "ACCEPT"  // 接受
+	OFFER_ACTION_REJECT  OfferAction = "REJECT"  // 拒绝
+)
+
+// OfferResponseTerms 答复方签署的答复内容（签署内容为其 JSON 序列化结果，字段顺序固定）
+type OfferResponseTerms struct {
+	OfferID   string      `json:"offerId"`          // 被答复的报价ID
+	OfferHash string      `json:"offerHash"`        // 被答复报价的私有数据哈希（确定报价的买家、价格和盐值）
+	Responder string      `json:"responder"`        // 答复方（交易方ID）
+	Action    OfferAction `json:"action"`           // 答复方式
+	TargetID  string      `json:"targetId"`         // 还价生成的报价ID或接受后生成的交易ID（拒绝时为空）
+	Price     *Money      `json:"price,omitempty"`  // 还价金额（仅还价）
+	Reason    string      `json:"reason,omitempty"` // 拒绝原因（仅拒绝）
+}
+
+// OfferResponsePayload 答复报价的待签署内容
+type OfferResponsePayload struct {
+	OfferID   string `json:"offerId"`   // 报价ID
+	Terms     string `json:"terms"`     // 待签署的答复内容（规范 JSON，使用 SHA-256 摘要签名）
+	TermsHash string `json:"termsHash"` // 答复内容的 SHA-256 哈希（十六进制）
+}
+
+// Offer 报价（买家出价，卖家可以接受、拒绝或还价，双方可以继续还价，接受后生成交易）
+type Offer struct {
+	ID              string      `json:"id"`              // 报价ID
+	RealEstateID    string      `json:"realEstateId"`    // 房产ID
+	Seller          string      `json:"seller"`          // 卖家（交易方ID）
+	Share           int         `json:"share"`           // 出售份额（万分比）
+	ProposedBy      OfferParty  `json:"proposedBy"`      // 报价方
+	ParentID        string      `json:"parentId"`        // 被还价的报价ID（首次出价为空）
+	PrivateDataHash string      `json:"privateDataHash"` // 私有数据（买家、价格、盐值）的 SHA-256 哈希
+	Status          OfferStatus `json:"status"`          // 状态
+	RejectReason    string      `json:"rejectReason"`    // 拒绝原因
+	TransactionID   string      `json:"transactionId"`   // 接受报价后生成的交易ID
+	CreateTime      time.Time   `json:"createTime"`      // 创建时间
+	UpdateTime      time.Time   `json:"updateTime"`      // 更新时间
+}
+
+// OfferPrivateDetails 报价私有数据（与交易私有数据保存在同一私有数据集合中，公开状态只保存其哈希）
+type OfferPrivateDetails struct {
+	OfferID  string `json:"offerId"`            // 报价ID
+	Buyer    string `json:"buyer"`              // 买家（交易方ID）
+	Proposer string `json:"proposer,omitempty"` // 报价方（交易方ID，旧版本的报价没有该字段，按 ProposedBy 推断）
+	Price    Money  `json:"price"`              // 报价金额
+	Salt     string `json:"salt"`               // 随机盐值
+}
+
+// SubmitOffer 买家对房产出价（仅拥有交易权限的组织可以调用，share 为0时购买卖家持有的全部份额）
+//
+// 买家、价格、币种和盐值与生成交易一样通过 transient map 的 transaction 字段传入
+func (s *SmartContract) SubmitOffer(ctx contractapi.TransactionContextInterface, offerID string, realEstateID string, seller string, share int) error {
+	// 验证调用者所在组织是否拥有交易权限
+	err := s.checkCapability(ctx, "出价", CAP_TRADING)
+	if err != nil {
+		return err
+	}
+
+	// 以账本交易时间作为创建时间
+	createTime, err := s.getTxTime(ctx)
+	if err != nil {
+		return err
+	}
+
+	// 读取私有数据
+	privateInput, err := s.getTransactionPrivateInput(ctx)
+	if err != nil {
+		return err
+	}
+	buyer := privateInput.Buyer
+
+	// 参数验证
+	if len(realEstateID) == 0 {
+		return fmt.Errorf("房产ID不能为空")
+	}
+	if len(seller) == 0 {
+		return fmt.Errorf("卖家不能为空")
+	}
+	if len(buyer) == 0 {
+		return fmt.Errorf("买家不能为空")
+	}
+	if seller == buyer {
+		return fmt.Errorf("买家和卖家不能是同一人")
+	}
+	if share < 0 || share > FULL_SHARE {
+		return fmt.Errorf("出售份额必须在0到%d之间", FULL_SHARE)
+	}
+	priceMoney, err := parseMoney(privateInput.Price, privateInput.Currency)
+	if err != nil {
+		return fmt.Errorf("价格无效：%v", err)
+	}
+
+	// 检查买家和卖家均已登记为交易方
+	err = s.checkPartiesRegistered(ctx, seller, buyer)
+	if err != nil {
+		return err
+	}
+
+	// 检查房产是否可以交易、卖家持有的份额
+	_, share, err = s.getSellableRealEstate(ctx, realEstateID, seller, share)
+	if err != nil {
+		return err
+	}
+
+	offer := &Offer{
+		ID:           offerID,
+		RealEstateID: realEstateID,
+		Seller:       seller,
+		Share:        share,
+		ProposedBy:   OFFER_BY_BUYER,
+		Status:       OFFER_PENDING,
+		CreateTime:   createTime,
+		UpdateTime:   createTime,
+	}
+	return s.createOffer(ctx, offer, &OfferPrivateDetails{
+		OfferID:  offerID,
+		Buyer:    buyer,
+		Proposer: buyer,
+		Price:    priceMoney,
+		Salt:     privateInput.Salt,
+	})
+}
+
+// CounterOffer 对待答复的报价还价（仅拥有交易权限的组织可以调用，原报价转为已还价，由对方答复新的报价）
+//
+// 新的价格、币种和盐值通过 transient map 的 transaction 字段传入，买家沿用原报价的买家；
+// 还价方和还价方对答复内容的签名通过 transient map 的 responder、responseSignature 字段传入，还价方必须是原报价的对方（不能对自己的报价还价）
+func (s *SmartContract) CounterOffer(ctx contractapi.TransactionContextInterface, offerID string, counterOfferID string) error {
+	// 验证调用者所在组织是否拥有交易权限
+	err := s.checkCapability(ctx, "还价", CAP_TRADING)
+	if err != nil {
+		return err
+	}
+
+	// 以账本交易时间作为创建时间
+	createTime, err := s.getTxTime(ctx)
+	if err != nil {
+		return err
+	}
+
+	// 读取私有数据
+	privateInput, err := s.getTransactionPrivateInput(ctx)
+	if err != nil {
+		return err
+	}
+	priceMoney, err := parseMoney(privateInput.Price, privateInput.Currency)
+	if err != nil {
+		return fmt.Errorf("价格无效：%v", err)
+	}
+
+	offer, err := s.getPendingOffer(ctx, offerID)
+	if err != nil {
+		return err
+	}
+
+	parentDetails, err := s.getOfferPrivateDetails(ctx, offerID)
+	if err != nil {
+		return err
+	}
+
+	// 检查还价方是原报价的对方
+	responder, err := s.checkOfferResponder(ctx, offer, parentDetails, OfferResponseTerms{
+		Action:   OFFER_ACTION_COUNTER,
+		TargetID: counterOfferID,
+		Price:    &priceMoney,
+	})
+	if err != nil {
+		return err
+	}
+
+	// 检查房产是否仍可以交易
+	_, _, err = s.getSellableRealEstate(ctx, offer.RealEstateID, offer.Seller, offer.Share)
+	if err != nil {
+		return err
+	}
+
+	proposedBy := OFFER_BY_SELLER
+	if offer.ProposedBy == OFFER_BY_SELLER {
+		proposedBy = OFFER_BY_BUYER
+	}
+
+	offer.Status = OFFER_COUNTERED
+	offer.UpdateTime = createTime
+	err = s.putOffer(ctx, offer)
+	if err != nil {
+		return err
+	}
+
+	counter := &Offer{
+		ID:           counterOfferID,
+		RealEstateID: offer.RealEstateID,
+		Seller:       offer.Seller,
+		Share:        offer.Share,
+		ProposedBy:   proposedBy,
+		ParentID:     offer.ID,
+		Status:       OFFER_PENDING,
+		CreateTime:   createTime,
+		UpdateTime:   createTime,
+	}
+	return s.createOffer(ctx, counter, &OfferPrivateDetails{
+		OfferID:  counterOfferID,
+		Buyer:    parentDetails.Buyer,
+		Proposer: responder,
+		Price:    priceMoney,
+		Salt:     privateInput.Salt,
+	})
+}
+
+// AcceptOffer 接受待答复的报价并按报价生成交易草稿（仅拥有交易权限的组织可以调用，只由私有数据集合成员背书）
+//
+// 报价转为已接受与生成交易草稿在同一笔交易中完成，同一房产其他待答复的报价同时失效，草稿再由 OpenTransaction 经登记机构背书确认；
+// 接受方和接受方对答复内容的签名通过 transient map 的 responder、responseSignature 字段传入，接受方必须是报价的对方（不能接受自己的报价）
+func (s *SmartContract) AcceptOffer(ctx contractapi.TransactionContextInterface, offerID string, txID string) error {
+	// 验证调用者所在组织是否拥有交易权限
+	err := s.checkCapability(ctx, "接受报价", CAP_TRADING)
+	if err != nil {
+		return err
+	}
+
+	// 以账本交易时间作为创建时间
+	createTime, err := s.getTxTime(ctx)
+	if err != nil {
+		return err
+	}
+
+	if len(txID) == 0 {
+		return fmt.Errorf("交易ID不能为空")
+	}
+
+	offer, err := s.getPendingOffer(ctx, offerID)
+	if err != nil {
+		return err
+	}
+
+	details, err := s.getOfferPrivateDetails(ctx, offerID)
+	if err != nil {
+		return err
+	}
+
+	// 检查接受方是报价的对方
+	_, err = s.checkOfferResponder(ctx, offer, details, OfferResponseTerms{
+		Action:   OFFER_ACTION_ACCEPT,
+		TargetID: txID,
+	})
+	if err != nil {
+		return err
+	}
+
+	// 按报价生成交易草稿
+	err = s.createTransaction(ctx, txID, offer.RealEstateID, offer.Seller, offer.Share, &TransactionPrivateDetails{
+		TxID:  txID,
+		Buyer: details.Buyer,
+		Price: details.Price,
+		Salt:  details.Salt,
+	}, createTime)
+	if err != nil {
+		return err
+	}
+
+	offer.Status = OFFER_ACCEPTED
+	offer.TransactionID = txID
+	offer.UpdateTime = createTime
+	err = s.putOffer(ctx, offer)
+	if err != nil {
+		return err
+	}
+
+	return s.closePendingOffers(ctx, offer.RealEstateID, offerID, createTime)
+}
+
+// RejectOffer 拒绝待答复的报价（仅拥有交易权限的组织可以调用）
+//
+// 拒绝方和拒绝方对答复内容的签名通过 transient map 的 responder、responseSignature 字段传入，拒绝方必须是报价的对方（报价方不能拒绝自己的报价）
+func (s *SmartContract) RejectOffer(ctx contractapi.TransactionContextInterface, offerID string, reason string) error {
+	// 验证调用者所在组织是否拥有交易权限
+	err := s.checkCapability(ctx, "拒绝报价", CAP_TRADING)
+	if err != nil {
+		return err
+	}
+
+	// 以账本交易时间作为更新时间
+	updateTime, err := s.getTxTime(ctx)
+	if err != nil {
+		return err
+	}
+
+	// 参数验证
+	if len(reason) == 0 {
+		return fmt.Errorf("拒绝原因不能为空")
+	}
+
+	offer, err := s.getPendingOffer(ctx, offerID)
+	if err != nil {
+		return err
+	}
+
+	details, err := s.getOfferPrivateDetails(ctx, offerID)
+	if err != nil {
+		return err
+	}
+
+	// 检查拒绝方是报价的对方
+	_, err = s.checkOfferResponder(ctx, offer, details, OfferResponseTerms{
+		Action: OFFER_ACTION_REJECT,
+		Reason: reason,
+	})
+	if err != nil {
+		return err
+	}
+
+	offer.Status = OFFER_REJECTED
+	offer.RejectReason = reason
+	offer.UpdateTime = updateTime
+	return s.putOffer(ctx, offer)
+}
+
+// QueryOffer 查询报价信息
+func (s *SmartContract) QueryOffer(ctx contractapi.TransactionContextInterface, offerID string) (*Offer, error) {
+	return s.getOffer(ctx, offerID)
+}
+
+// QueryOffersByRealEstate 分页查询房产的报价列表
+func (s *SmartContract) QueryOffersByRealEstate(ctx contractapi.TransactionContextInterface, realEstateID string, pageSize int32, bookmark string) (*QueryResult, error) {
+	if len(realEstateID) == 0 {
+		return nil, fmt.Errorf("房产ID不能为空")
+	}
+	return s.queryPage(ctx, OFFER, OFFER_REAL_ESTATE_INDEX, []string{realEstateID}, pageSize, bookmark, decodeOffer)
+}
+
+// QueryOfferPrivateDetails 查询报价私有数据（仅拥有结算或交易权限的组织可以调用）
+func (s *SmartContract) QueryOfferPrivateDetails(ctx contractapi.TransactionContextInterface, offerID string) (*OfferPrivateDetails, error) {
+	// 验证调用者所在组织是否拥有结算或交易权限
+	err := s.checkCapability(ctx, "查询报价私有数据", CAP_SETTLEMENT, CAP_TRADING)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.getOfferPrivateDetails(ctx, offerID)
+}
+
+// QueryOfferResponsePayload 查询答复报价时答复方需要签署的内容（仅拥有交易权限的组织可以调用）
+//
+// targetID 为还价生成的报价ID或接受后生成的交易ID，price、currency 仅还价时传入，reason 仅拒绝时传入
+func (s *SmartContract) QueryOfferResponsePayload(ctx contractapi.TransactionContextInterface, offerID string, responder string, action string, targetID string, price string, currency string, reason string) (*OfferResponsePayload, error) {
+	// 验证调用者所在组织是否拥有交易权限
+	err := s.checkCapability(ctx, "查询报价答复签署内容", CAP_TRADING)
+	if err != nil {
+		return nil, err
+	}
+
+	offer, err := s.getPendingOffer(ctx, offerID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := OfferResponseTerms{
+		OfferID:   offer.ID,
+		OfferHash: offer.PrivateDataHash,
+		Responder: responder,
+		Action:    OfferAction(action),
+		TargetID:  targetID,
+	}
+	switch response.Action {
+	case OFFER_ACTION_COUNTER:
+		priceMoney, err := parseMoney(price, currency)
+		if err != nil {
+			return nil, fmt.Errorf("价格无效：%v", err)
+		}
+		response.Price = &priceMoney
+	case OFFER_ACTION_ACCEPT:
+	case OFFER_ACTION_REJECT:
+		response.Reason = reason
+	default:
+		return nil, fmt.Errorf("答复方式必须为 %s、%s 或 %s", OFFER_ACTION_COUNTER, OFFER_ACTION_ACCEPT, OFFER_ACTION_REJECT)
+	}
+
+	terms, termsHash, err := offerResponseTerms(response)
+	if err != nil {
+		return nil, err
+	}
+	return &OfferResponsePayload{
+		OfferID:   offer.ID,
+		Terms:     string(terms),
+		TermsHash: hex.EncodeToString(termsHash),
+	}, nil
+}
+
+// decodeOffer 解析报价信息
+func decodeOffer(bytes []byte) (interface{}, error) {
+	var offer Offer
+	err := json.Unmarshal(bytes, &offer)
+	if err != nil {
+		return nil, fmt.Errorf("解析报价信息失败：%v", err)
+	}
+	return offer, nil
+}
+
+// 通用方法：保存新报价及其私有数据，并写入房产索引
+func (s *SmartContract) createOffer(ctx contractapi.TransactionContextInterface, offer *Offer, details *OfferPrivateDetails) error {
+	if len(offer.ID) == 0 {
+		return fmt.Errorf("报价ID不能为空")
+	}
+
+	// 检查报价是否已存在
+	key, err := s.getCompositeKey(ctx, OFFER, []string{offer.ID})
+	if err != nil {
+		return err
+	}
+
+	exists, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return fmt.Errorf("查询报价信息失败：%v", err)
+	}
+	if exists != nil {
+		return fmt.Errorf("报价ID %s 已存在", offer.ID)
+	}
+
+	// 保存私有数据
+	data, err := json.Marshal(details)
+	if err != nil {
+		return fmt.Errorf("序列化报价私有数据失败：%v", err)
+	}
+
+	err = ctx.GetStub().PutPrivateData(TRANSACTION_PRIVATE_COLLECTION, key, data)
+	if err != nil {
+		return fmt.Errorf("保存报价私有数据失败：%v", err)
+	}
+
+	err = s.putPrivateIndex(ctx, OFFER_BUYER_INDEX, []string{details.Buyer, offer.ID})
+	if err != nil {
+		return err
+	}
+
+	hash := sha256.Sum256(data)
+	offer.PrivateDataHash = hex.EncodeToString(hash[:])
+
+	err = s.putOffer(ctx, offer)
+	if err != nil {
+		return err
+	}
+
+	return s.putIndex(ctx, OFFER_REAL_ESTATE_INDEX, []string{offer.RealEstateID, offer.ID})
+}
+
+// 通用方法：使房产其他待答复的报价失效
+func (s *SmartContract) closePendingOffers(ctx contractapi.TransactionContextInterface, realEstateID string, acceptedOfferID string, updateTime time.Time) error {
+	iterator, err := ctx.GetStub().GetStateByPartialCompositeKey(OFFER_REAL_ESTATE_INDEX, []string{realEstateID})
+	if err != nil {
+		return fmt.Errorf("查询报价列表失败：%v", err)
+	}
+	defer iterator.Close()
+
+	for iterator.HasNext() {
+		queryResponse, err := iterator.Next()
+		if err != nil {
+			return fmt.Errorf("获取下一条记录失败：%v", err)
+		}
+
+		_, attributes, err := ctx.GetStub().SplitCompositeKey(queryResponse.Key)
+		if err != nil {
+			return fmt.Errorf("解析复合键失败：%v", err)
+		}
+
+		offerID := attributes[len(attributes)-1]
+		if offerID == acceptedOfferID {
+			continue
+		}
+
+		offer, err := s.getOffer(ctx, offerID)
+		if err != nil {
+			return err
+		}
+		if offer.Status != OFFER_PENDING {
+			continue
+		}
+
+		offer.Status = OFFER_CLOSED
+		offer.UpdateTime = updateTime
+		err = s.putOffer(ctx, offer)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// 通用方法：获取报价信息
+func (s *SmartContract) getOffer(ctx contractapi.TransactionContextInterface, offerID string) (*Offer, error) {
+	key, err := s.getCompositeKey(ctx, OFFER, []string{offerID})
+	if err != nil {
+		return nil, err
+	}
+
+	bytes, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return nil, fmt.Errorf("查询报价信息失败：%v", err)
+	}
+	if bytes == nil {
+		return nil, fmt.Errorf("报价ID %s 不存在", offerID)
+	}
+
+	var offer Offer
+	err = json.Unmarshal(bytes, &offer)
+	if err != nil {
+		return nil, fmt.Errorf("解析报价信息失败：%v", err)
+	}
+	return &offer, nil
+}
+
+// 通用方法：获取待答复的报价信息
+func (s *SmartContract) getPendingOffer(ctx contractapi.TransactionContextInterface, offerID string) (*Offer, error) {
+	offer, err := s.getOffer(ctx, offerID)
+	if err != nil {
+		return nil, err
+	}
+
+	if offer.Status != OFFER_PENDING {
+		return nil, fmt.Errorf("报价 %s 当前状态为 %s，不是待答复状态", offerID, offer.Status)
+	}
+	return offer, nil
+}
+
+// 通用方法：读取 transient map 中的答复方及其签名，检查答复方是报价的对方并用其登记的公钥验证对答复内容的签名，返回答复方
+//
+// response 只需填写答复方式、目标ID、还价金额和拒绝原因，报价ID、报价哈希和答复方由本方法填写
+func (s *SmartContract) checkOfferResponder(ctx contractapi.TransactionContextInterface, offer *Offer, details *OfferPrivateDetails, response OfferResponseTerms) (string, error) {
+	transient, err := ctx.GetStub().GetTransient()
+	if err != nil {
+		return "", fmt.Errorf("读取 transient 数据失败：%v", err)
+	}
+
+	responder := string(transient[OFFER_RESPONDER_TRANSIENT_KEY])
+	if len(responder) == 0 {
+		return "", fmt.Errorf("答复方必须通过 transient map 的 %s 字段传入", OFFER_RESPONDER_TRANSIENT_KEY)
+	}
+	signature := string(transient[OFFER_RESPONSE_SIGNATURE_TRANSIENT_KEY])
+	if len(signature) == 0 {
+		return "", fmt.Errorf("答复方的签名必须通过 transient map 的 %s 字段传入", OFFER_RESPONSE_SIGNATURE_TRANSIENT_KEY)
+	}
+
+	err = checkOfferCounterparty(offer, details, responder)
+	if err != nil {
+		return "", err
+	}
+
+	response.OfferID = offer.ID
+	response.OfferHash = offer.PrivateDataHash
+	response.Responder = responder
+	_, termsHash, err := offerResponseTerms(response)
+	if err != nil {
+		return "", err
+	}
+
+	err = s.verifyPartySignature(ctx, responder, termsHash, signature)
+	if err != nil {
+		return "", fmt.Errorf("答复方 %s 对报价 %s 的签名无效：%v", responder, offer.ID, err)
+	}
+	return responder, nil
+}
+
+// 通用方法：检查答复方是报价的对方（报价方不能答复自己的报价）
+func checkOfferCounterparty(offer *Offer, details *OfferPrivateDetails, responder string) error {
+	proposer, counterparty := details.Buyer, offer.Seller
+	if offer.ProposedBy == OFFER_BY_SELLER {
+		proposer, counterparty = offer.Seller, details.Buyer
+	}
+	if len(details.Proposer) > 0 && details.Proposer != proposer {
+		return fmt.Errorf("报价 %s 的报价方与私有数据不一致", offer.ID)
+	}
+
+	if responder == proposer {
+		return fmt.Errorf("报价 %s 由 %s 提出，报价方不能答复自己的报价", offer.ID, responder)
+	}
+	if responder != counterparty {
+		return fmt.Errorf("%s 不是报价 %s 的对方，无权答复", responder, offer.ID)
+	}
+	return nil
+}
+
+// 通用方法：生成答复内容的规范 JSON 及其 SHA-256 摘要
+func offerResponseTerms(response OfferResponseTerms) ([]byte, []byte, error) {
+	terms, err := json.Marshal(response)
+	if err != nil {
+		return nil, nil, fmt.Errorf("序列化答复内容失败：%v", err)
+	}
+
+	hash := sha256.Sum256(terms)
+	return terms, hash[:], nil
+}
+
+// 通用方法：保存报价信息
+func (s *SmartContract) putOffer(ctx contractapi.TransactionContextInterface, offer *Offer) error {
+	key, err := s.getCompositeKey(ctx, OFFER, []string{offer.ID})
+	if err != nil {
+		return err
+	}
+	return s.putState(ctx, key, offer)
+}
+
+// 通用方法：读取报价私有数据
+func (s *SmartContract) getOfferPrivateDetails(ctx contractapi.TransactionContextInterface, offerID string) (*OfferPrivateDetails, error) {
+	key, err := s.getCompositeKey(ctx, OFFER, []string{offerID})
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := ctx.GetStub().GetPrivateData(TRANSACTION_PRIVATE_COLLECTION, key)
+	if err != nil {
+		return nil, fmt.Errorf("读取报价私有数据失败：%v", err)
+	}
+	if data == nil {
+		return nil, fmt.Errorf("报价 %s 没有私有数据", offerID)
+	}
+
+	var details OfferPrivateDetails
+	err = json.Unmarshal(data, &details)
+	if err != nil {
+		return nil, fmt.Errorf("解析报价私有数据失败：%v", err)
+	}
+	return &details, nil
+}
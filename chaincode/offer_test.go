@@ -0,0 +1,148 @@
+package main
+
+import (
+	"crypto/ecdsa"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"testing"
+)
+
+// putTestOffer 写入待答复的报价及其私有数据
+func putTestOffer(t *testing.T, s *SmartContract, stub *mockStub, offer *Offer, details *OfferPrivateDetails) {
+	t.Helper()
+
+	err := s.createOffer(newTestContext(stub), offer, details)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// signTestOfferResponse 以私钥签署答复内容，并将答复方和签名写入 transient map
+func signTestOfferResponse(t *testing.T, s *SmartContract, stub *mockStub, offerID string, responder string, key *ecdsa.PrivateKey, response OfferResponseTerms) {
+	t.Helper()
+
+	offer, err := s.getOffer(newTestContext(stub), offerID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	response.OfferID = offer.ID
+	response.OfferHash = offer.PrivateDataHash
+	response.Responder = responder
+	_, termsHash, err := offerResponseTerms(response)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig, err := ecdsa.SignASN1(rand.Reader, key, termsHash)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stub.transient = map[string][]byte{
+		OFFER_RESPONDER_TRANSIENT_KEY:          []byte(responder),
+		OFFER_RESPONSE_SIGNATURE_TRANSIENT_KEY: []byte(base64.StdEncoding.EncodeToString(sig)),
+	}
+}
+
+func TestCheckOfferCounterparty(t *testing.T) {
+	tests := []struct {
+		name       string
+		proposedBy OfferParty
+		proposer   string
+		responder  string
+		wantErr    bool
+	}{
+		{"卖家答复买家出价", OFFER_BY_BUYER, "B", "S", false},
+		{"买家答复卖家还价", OFFER_BY_SELLER, "S", "B", false},
+		{"买家答复自己的出价", OFFER_BY_BUYER, "B", "B", true},
+		{"卖家答复自己的还价", OFFER_BY_SELLER, "S", "S", true},
+		{"第三方答复", OFFER_BY_BUYER, "B", "X", true},
+		{"未传入答复方", OFFER_BY_BUYER, "B", "", true},
+		{"旧版本报价没有记录报价方", OFFER_BY_SELLER, "", "B", false},
+		{"记录的报价方与报价方向不一致", OFFER_BY_SELLER, "B", "S", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			offer := &Offer{ID: "O1", Seller: "S", ProposedBy: tt.proposedBy}
+			details := &OfferPrivateDetails{OfferID: "O1", Buyer: "B", Proposer: tt.proposer}
+			err := checkOfferCounterparty(offer, details, tt.responder)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("错误 = %v，期望出错 = %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRejectOfferRequiresCounterpartySignature(t *testing.T) {
+	s := &SmartContract{}
+	stub := newMockStub()
+	ctx := newTestContext(stub)
+
+	sellerKey := registerTestSigner(t, s, stub, "S")
+	buyerKey := registerTestSigner(t, s, stub, "B")
+	stub.setCaller(t, TRADE_ORG_MSPID, "client", nil)
+
+	putTestOffer(t, s, stub, &Offer{
+		ID:           "O1",
+		RealEstateID: "R1",
+		Seller:       "S",
+		Share:        FULL_SHARE,
+		ProposedBy:   OFFER_BY_BUYER,
+		Status:       OFFER_PENDING,
+	}, &OfferPrivateDetails{OfferID: "O1", Buyer: "B", Proposer: "B"})
+
+	reject := OfferResponseTerms{Action: OFFER_ACTION_REJECT, Reason: "价格太低"}
+	tests := []struct {
+		name      string
+		responder string
+		key       *ecdsa.PrivateKey
+		response  OfferResponseTerms
+	}{
+		{"买家拒绝自己的出价", "B", buyerKey, reject},
+		{"以卖家名义提交买家的签名", "S", buyerKey, reject},
+		{"签署的拒绝原因与提交的不一致", "S", sellerKey, OfferResponseTerms{Action: OFFER_ACTION_REJECT, Reason: "不卖了"}},
+		{"签署的答复方式与提交的不一致", "S", sellerKey, OfferResponseTerms{Action: OFFER_ACTION_ACCEPT}},
+	}
+	for _, tt := range tests {
+		signTestOfferResponse(t, s, stub, "O1", tt.responder, tt.key, tt.response)
+		if err := s.RejectOffer(ctx, "O1", "价格太低"); err == nil {
+			t.Fatalf("%s：拒绝报价应当失败", tt.name)
+		}
+	}
+
+	// 未传入签名
+	stub.transient = map[string][]byte{OFFER_RESPONDER_TRANSIENT_KEY: []byte("S")}
+	if err := s.RejectOffer(ctx, "O1", "价格太低"); err == nil {
+		t.Fatal("未传入答复方签名时拒绝报价应当失败")
+	}
+
+	// 按查询到的待签署内容签名
+	payload, err := s.QueryOfferResponsePayload(ctx, "O1", "S", string(OFFER_ACTION_REJECT), "", "", "", "价格太低")
+	if err != nil {
+		t.Fatal(err)
+	}
+	termsHash, err := hex.DecodeString(payload.TermsHash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig, err := ecdsa.SignASN1(rand.Reader, sellerKey, termsHash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stub.transient = map[string][]byte{
+		OFFER_RESPONDER_TRANSIENT_KEY:          []byte("S"),
+		OFFER_RESPONSE_SIGNATURE_TRANSIENT_KEY: []byte(base64.StdEncoding.EncodeToString(sig)),
+	}
+	if err := s.RejectOffer(ctx, "O1", "价格太低"); err != nil {
+		t.Fatal(err)
+	}
+
+	offer, err := s.QueryOffer(ctx, "O1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if offer.Status != OFFER_REJECTED {
+		t.Fatalf("报价状态 = %s，期望 %s", offer.Status, OFFER_REJECTED)
+	}
+}
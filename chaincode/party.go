@@ -0,0 +1,328 @@
+package main
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/v2/contractapi"
+)
+
+// PARTY 交易方文档类型（复合键：PT_交易方ID）
+const PARTY = "PT"
+
+// PARTY_DOCUMENT_INDEX 证件号哈希索引（复合键：PT-DOC_证件号哈希_交易方ID，用于防止同一证件重复登记）
+const PARTY_DOCUMENT_INDEX = "PT-DOC"
+
+// PartyType 交易方类型
+type PartyType string
+
+const (
+	PERSON       PartyType = "PERSON"       // 自然人
+	LEGAL_ENTITY PartyType = "LEGAL_ENTITY" // 法人
+)
+
+// Party 交易方（房产所有者、买家、卖家）
+type Party struct {
+	ID             string    `json:"id"`             // 交易方ID
+	Type           PartyType `json:"type"`           // 交易方类型
+	IDDocumentHash string    `json:"idDocumentHash"` // 证件号码的 HMAC-SHA256（十六进制小写，密钥只保存在应用服务器上）
+	DisplayName    string    `json:"displayName"`    // 显示名称
+	ContactRef     string    `json:"contactRef"`     // 联系方式引用
+	PublicKey      string    `json:"publicKey"`      // 签署交易使用的 ECDSA 公钥（PEM 格式，为空表示尚未登记）
+	CreateTime     time.Time `json:"createTime"`     // 创建时间
+	UpdateTime     time.Time `json:"updateTime"`     // 更新时间
+}
+
+// RegisterParty 登记交易方（仅拥有登记权限的组织可以调用，idDocumentHash 为证件号码的 HMAC-SHA256，账本上不保存证件号码原文）
+//
+// 证件号码的取值空间很小，不加密钥的哈希可以被穷举还原，密钥由应用服务器保存，不上链
+func (s *SmartContract) RegisterParty(ctx contractapi.TransactionContextInterface, id string, partyType string, idDocumentHash string, displayName string, contactRef string) error {
+	err := s.checkCapability(ctx, "登记交易方", CAP_REGISTRY)
+	if err != nil {
+		return err
+	}
+
+	// 以账本交易时间作为创建时间
+	createTime, err := s.getTxTime(ctx)
+	if err != nil {
+		return err
+	}
+
+	// 参数验证
+	if len(id) == 0 || strings.TrimSpace(id) != id {
+		return fmt.Errorf("交易方ID不能为空，且不能包含首尾空白字符")
+	}
+	if PartyType(partyType) != PERSON && PartyType(partyType) != LEGAL_ENTITY {
+		return fmt.Errorf("交易方类型必须为 %s 或 %s", PERSON, LEGAL_ENTITY)
+	}
+	decoded, err := hex.DecodeString(idDocumentHash)
+	if err != nil || len(decoded) != 32 || strings.ToLower(idDocumentHash) != idDocumentHash {
+		return fmt.Errorf("证件号码哈希必须为64位小写十六进制的 HMAC-SHA256 值")
+	}
+	if len(strings.TrimSpace(displayName)) == 0 {
+		return fmt.Errorf("显示名称不能为空")
+	}
+
+	// 检查交易方是否已存在
+	key, err := s.getCompositeKey(ctx, PARTY, []string{id})
+	if err != nil {
+		return err
+	}
+
+	exists, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return fmt.Errorf("查询交易方信息失败：%v", err)
+	}
+	if exists != nil {
+		return fmt.Errorf("交易方ID %s 已存在", id)
+	}
+
+	// 检查证件是否已被其他交易方登记
+	registered, err := s.hasIndexEntries(ctx, PARTY_DOCUMENT_INDEX, []string{idDocumentHash})
+	if err != nil {
+		return err
+	}
+	if registered {
+		return fmt.Errorf("该证件已登记为其他交易方")
+	}
+
+	party := Party{
+		ID:             id,
+		Type:           PartyType(partyType),
+		IDDocumentHash: idDocumentHash,
+		DisplayName:    strings.TrimSpace(displayName),
+		ContactRef:     contactRef,
+		CreateTime:     createTime,
+		UpdateTime:     createTime,
+	}
+
+	err = s.putState(ctx, key, party)
+	if err != nil {
+		return err
+	}
+
+	return s.putIndex(ctx, PARTY_DOCUMENT_INDEX, []string{idDocumentHash, id})
+}
+
+// UpdateParty 更新交易方的显示名称和联系方式引用（仅拥有登记权限的组织可以调用）
+func (s *SmartContract) UpdateParty(ctx contractapi.TransactionContextInterface, id string, displayName string, contactRef string) error {
+	err := s.checkCapability(ctx, "更新交易方", CAP_REGISTRY)
+	if err != nil {
+		return err
+	}
+
+	// 以账本交易时间作为更新时间
+	updateTime, err := s.getTxTime(ctx)
+	if err != nil {
+		return err
+	}
+
+	if len(strings.TrimSpace(displayName)) == 0 {
+		return fmt.Errorf("显示名称不能为空")
+	}
+
+	party, err := s.getParty(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	party.DisplayName = strings.TrimSpace(displayName)
+	party.ContactRef = contactRef
+	party.UpdateTime = updateTime
+
+	key, err := s.getCompositeKey(ctx, PARTY, []string{id})
+	if err != nil {
+		return err
+	}
+	return s.putState(ctx, key, party)
+}
+
+// RegisterPartyPublicKey 登记或更换交易方签署交易使用的 ECDSA 公钥（仅拥有登记权限的组织可以调用，publicKey 为 PEM 格式的 PKIX 公钥）
+//
+// 更换公钥后，用旧私钥对未完成交易作出的签署不再有效，需要重新签署
+func (s *SmartContract) RegisterPartyPublicKey(ctx contractapi.TransactionContextInterface, id string, publicKey string) error {
+	err := s.checkCapability(ctx, "登记交易方公钥", CAP_REGISTRY)
+	if err != nil {
+		return err
+	}
+
+	// 以账本交易时间作为更新时间
+	updateTime, err := s.getTxTime(ctx)
+	if err != nil {
+		return err
+	}
+
+	_, err = parseECDSAPublicKey(publicKey)
+	if err != nil {
+		return err
+	}
+
+	party, err := s.getParty(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	party.PublicKey = publicKey
+	party.UpdateTime = updateTime
+
+	key, err := s.getCompositeKey(ctx, PARTY, []string{id})
+	if err != nil {
+		return err
+	}
+	return s.putState(ctx, key, party)
+}
+
+// DeleteParty 删除交易方（仅拥有登记权限的组织可以调用，已持有房产、参与过交易或有待答复报价的交易方不能删除）
+//
+// 未公开的买家索引保存在私有数据集合中，需要由集合成员（银行、交易平台组织）的节点背书
+func (s *SmartContract) DeleteParty(ctx contractapi.TransactionContextInterface, id string) error {
+	err := s.checkCapability(ctx, "删除交易方", CAP_REGISTRY)
+	if err != nil {
+		return err
+	}
+
+	party, err := s.getParty(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	for _, indexName := range []string{REAL_ESTATE_OWNER_INDEX, TRANSACTION_PARTY_INDEX} {
+		referenced, err := s.hasIndexEntries(ctx, indexName, []string{id})
+		if err != nil {
+			return err
+		}
+		if referenced {
+			return fmt.Errorf("交易方 %s 已持有房产或参与过交易，无法删除", id)
+		}
+	}
+
+	err = s.checkNoPrivateTrades(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	key, err := s.getCompositeKey(ctx, PARTY, []string{id})
+	if err != nil {
+		return err
+	}
+	err = ctx.GetStub().DelState(key)
+	if err != nil {
+		return fmt.Errorf("删除交易方失败：%v", err)
+	}
+
+	return s.deleteIndex(ctx, PARTY_DOCUMENT_INDEX, []string{party.IDDocumentHash, id})
+}
+
+// QueryParty 查询交易方信息
+func (s *SmartContract) QueryParty(ctx contractapi.TransactionContextInterface, id string) (*Party, error) {
+	return s.getParty(ctx, id)
+}
+
+// QueryPartyList 分页查询交易方列表
+func (s *SmartContract) QueryPartyList(ctx contractapi.TransactionContextInterface, pageSize int32, bookmark string) (*QueryResult, error) {
+	return s.queryPage(ctx, PARTY, "", nil, pageSize, bookmark, decodeParty)
+}
+
+// decodeParty 解析交易方信息
+func decodeParty(bytes []byte) (interface{}, error) {
+	var party Party
+	err := json.Unmarshal(bytes, &party)
+	if err != nil {
+		return nil, fmt.Errorf("解析交易方信息失败：%v", err)
+	}
+	return party, nil
+}
+
+// 通用方法：获取交易方信息
+func (s *SmartContract) getParty(ctx contractapi.TransactionContextInterface, id string) (*Party, error) {
+	key, err := s.getCompositeKey(ctx, PARTY, []string{id})
+	if err != nil {
+		return nil, err
+	}
+
+	bytes, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return nil, fmt.Errorf("查询交易方信息失败：%v", err)
+	}
+	if bytes == nil {
+		return nil, fmt.Errorf("交易方ID %s 不存在", id)
+	}
+
+	var party Party
+	err = json.Unmarshal(bytes, &party)
+	if err != nil {
+		return nil, fmt.Errorf("解析交易方信息失败：%v", err)
+	}
+	return &party, nil
+}
+
+// 通用方法：检查交易方没有作为尚未公开的买家参与过交易，也没有待答复的报价
+func (s *SmartContract) checkNoPrivateTrades(ctx contractapi.TransactionContextInterface, id string) error {
+	buyerIndex, err := ctx.GetStub().GetPrivateDataByPartialCompositeKey(TRANSACTION_PRIVATE_COLLECTION, TRANSACTION_BUYER_INDEX, []string{id})
+	if err != nil {
+		return fmt.Errorf("查询交易买家索引失败：%v", err)
+	}
+	defer buyerIndex.Close()
+	if buyerIndex.HasNext() {
+		return fmt.Errorf("交易方 %s 已参与过交易，无法删除", id)
+	}
+
+	iterator, err := ctx.GetStub().GetPrivateDataByPartialCompositeKey(TRANSACTION_PRIVATE_COLLECTION, OFFER_BUYER_INDEX, []string{id})
+	if err != nil {
+		return fmt.Errorf("查询报价买家索引失败：%v", err)
+	}
+	defer iterator.Close()
+
+	for iterator.HasNext() {
+		queryResponse, err := iterator.Next()
+		if err != nil {
+			return fmt.Errorf("获取下一条记录失败：%v", err)
+		}
+
+		_, attributes, err := ctx.GetStub().SplitCompositeKey(queryResponse.Key)
+		if err != nil {
+			return fmt.Errorf("解析复合键失败：%v", err)
+		}
+
+		offer, err := s.getOffer(ctx, attributes[len(attributes)-1])
+		if err != nil {
+			return err
+		}
+		if offer.Status == OFFER_PENDING {
+			return fmt.Errorf("交易方 %s 的报价 %s 待答复，报价答复前无法删除交易方", id, offer.ID)
+		}
+	}
+	return nil
+}
+
+// 通用方法：检查交易方均已登记
+func (s *SmartContract) checkPartiesRegistered(ctx contractapi.TransactionContextInterface, ids ...string) error {
+	for _, id := range ids {
+		key, err := s.getCompositeKey(ctx, PARTY, []string{id})
+		if err != nil {
+			return err
+		}
+
+		exists, err := ctx.GetStub().GetState(key)
+		if err != nil {
+			return fmt.Errorf("查询交易方信息失败：%v", err)
+		}
+		if exists == nil {
+			return fmt.Errorf("交易方 %s 未登记", id)
+		}
+	}
+	return nil
+}
+
+// 通用方法：检查索引下是否存在记录
+func (s *SmartContract) hasIndexEntries(ctx contractapi.TransactionContextInterface, indexName string, attributes []string) (bool, error) {
+	iterator, err := ctx.GetStub().GetStateByPartialCompositeKey(indexName, attributes)
+	if err != nil {
+		return false, fmt.Errorf("查询索引失败：%v", err)
+	}
+	defer iterator.Close()
+	return iterator.HasNext(), nil
+}
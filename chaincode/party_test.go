@@ -0,0 +1,82 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestDeletePartyWithOpenTrades(t *testing.T) {
+	// createTestTransaction 由集合成员背书生成交易草稿（买家只写入私有数据集合）
+	createTestTransaction := func(t *testing.T, s *SmartContract, stub *mockStub, txID string, buyer string) {
+		t.Helper()
+		stub.setCaller(t, TRADE_ORG_MSPID, "client", nil)
+		err := s.createTransaction(newTestContext(stub), txID, "R1", "S", 0, &TransactionPrivateDetails{
+			TxID:  txID,
+			Buyer: buyer,
+			Price: Money{Amount: 100000000, Currency: "CNY"},
+		}, stub.txTime)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	putBuyerOffer := func(t *testing.T, s *SmartContract, stub *mockStub, buyer string, status OfferStatus) {
+		t.Helper()
+		putTestOffer(t, s, stub, &Offer{
+			ID:           "O1",
+			RealEstateID: "R1",
+			Seller:       "S",
+			Share:        FULL_SHARE,
+			ProposedBy:   OFFER_BY_BUYER,
+			Status:       status,
+		}, &OfferPrivateDetails{OfferID: "O1", Buyer: buyer, Proposer: buyer})
+	}
+
+	tests := []struct {
+		name      string
+		setup     func(t *testing.T, s *SmartContract, stub *mockStub)
+		nonMember bool
+		wantErr   bool
+	}{
+		{"没有交易和报价", nil, false, false},
+		{"其他交易方的未完成交易和报价", func(t *testing.T, s *SmartContract, stub *mockStub) {
+			createTestTransaction(t, s, stub, "T1", "A")
+			putBuyerOffer(t, s, stub, "A", OFFER_PENDING)
+		}, false, false},
+		{"作为买家参与未完成的交易", func(t *testing.T, s *SmartContract, stub *mockStub) {
+			createTestTransaction(t, s, stub, "T1", "B")
+		}, false, true},
+		{"存在待答复的报价", func(t *testing.T, s *SmartContract, stub *mockStub) {
+			putBuyerOffer(t, s, stub, "B", OFFER_PENDING)
+		}, false, true},
+		{"只有已拒绝的报价", func(t *testing.T, s *SmartContract, stub *mockStub) {
+			putBuyerOffer(t, s, stub, "B", OFFER_REJECTED)
+		}, false, false},
+		{"非集合成员的节点无法检查买家索引", nil, true, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &SmartContract{}
+			stub := newMockStub()
+			ctx := newTestContext(stub)
+
+			for _, id := range []string{"S", "A", "B"} {
+				registerTestSigner(t, s, stub, id)
+			}
+			stub.setCaller(t, REALTY_ORG_MSPID, "client", nil)
+			err := s.CreateRealEstate(ctx, "R1", "北京市朝阳区", "89.50", string(RESIDENTIAL), []Owner{{ID: "S", Share: FULL_SHARE}})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.setup != nil {
+				tt.setup(t, s, stub)
+			}
+
+			stub.setCaller(t, REALTY_ORG_MSPID, "client", nil)
+			stub.nonMember = tt.nonMember
+			err = s.DeleteParty(ctx, "B")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("删除交易方错误 = %v，期望出错 = %v", err, tt.wantErr)
+			}
+		})
+	}
+}
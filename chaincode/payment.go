@@ -0,0 +1,214 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/v2/contractapi"
+)
+
+// PAYMENT 托管付款记录文档类型（保存在交易私有数据集合中，复合键：PAY_交易ID_付款记录ID）
+const PAYMENT = "PAY"
+
+// TransactionPayment 托管付款记录（付款金额可以推算成交价格，与交易私有数据保存在同一私有数据集合中）
+type TransactionPayment struct {
+	ID         string    `json:"id"`         // 付款记录ID（登记付款的 Fabric 交易ID）
+	TxID       string    `json:"txId"`       // 交易ID
+	Amount     Money     `json:"amount"`     // 付款金额（币种与成交价格一致）
+	Reference  string    `json:"reference"`  // 银行流水号
+	Operator   string    `json:"operator"`   // 登记付款的组织 MSP ID
+	CreateTime time.Time `json:"createTime"` // 登记时间
+}
+
+// TransactionPaymentSummary 交易托管付款汇总
+type TransactionPaymentSummary struct {
+	TxID     string                `json:"txId"`     // 交易ID
+	Price    Money                 `json:"price"`    // 成交价格
+	Paid     Money                 `json:"paid"`     // 已付金额
+	Balance  Money                 `json:"balance"`  // 未付余额
+	Payments []*TransactionPayment `json:"payments"` // 付款记录（按登记时间排序）
+}
+
+// RecordPayment 登记买家向托管账户的付款（仅拥有结算权限的组织可以调用，amount 为十进制字符串，币种与成交价格一致）
+//
+// 部分付款后交易转为部分付款状态，付清后转为已付清状态，再由结算机构提交 SettleTransaction 结算、CompleteTransaction 经登记机构背书完成过户；
+// 买卖双方尚未签署时付清的付款无法登记
+func (s *SmartContract) RecordPayment(ctx contractapi.TransactionContextInterface, txID string, amount string, reference string) error {
+	// 验证调用者所在组织是否拥有结算权限
+	err := s.checkCapability(ctx, "登记付款", CAP_SETTLEMENT)
+	if err != nil {
+		return err
+	}
+
+	clientMSPID, err := s.getClientIdentityMSPID(ctx)
+	if err != nil {
+		return fmt.Errorf("获取调用者身份失败：%v", err)
+	}
+
+	// 以账本交易时间作为登记时间
+	createTime, err := s.getTxTime(ctx)
+	if err != nil {
+		return err
+	}
+
+	// 参数验证
+	if len(reference) == 0 {
+		return fmt.Errorf("银行流水号不能为空")
+	}
+
+	// 查询交易信息
+	transaction, err := s.getOpenTransaction(ctx, txID)
+	if err != nil {
+		return err
+	}
+	if transaction.Status == DRAFT {
+		return fmt.Errorf("交易 %s 尚未经登记机构确认，无法登记付款", txID)
+	}
+
+	privateDetails, err := s.getTransactionPrivateDetails(ctx, txID)
+	if err != nil {
+		return err
+	}
+	if privateDetails == nil {
+		return fmt.Errorf("交易 %s 没有私有数据中的成交价格，无法登记付款", txID)
+	}
+
+	payment, err := parseMoney(amount, privateDetails.Price.Currency)
+	if err != nil {
+		return fmt.Errorf("付款金额无效：%v", err)
+	}
+
+	// 检查流水号是否重复登记、付款是否超过未付余额
+	payments, paid, err := s.getTransactionPayments(ctx, txID)
+	if err != nil {
+		return err
+	}
+	for _, p := range payments {
+		if p.Reference == reference {
+			return fmt.Errorf("银行流水号 %s 已登记", reference)
+		}
+	}
+	balance := privateDetails.Price.Amount - paid
+	if payment.Amount > balance {
+		return fmt.Errorf("付款金额 %s 超过未付余额 %s", payment.String(), Money{Amount: balance, Currency: payment.Currency}.String())
+	}
+
+	// 付清前买卖双方必须已签署交易条款
+	if payment.Amount == balance {
+		err = s.checkTransactionApprovals(ctx, transaction, privateDetails)
+		if err != nil {
+			return fmt.Errorf("无法登记付清的付款：%v", err)
+		}
+	}
+
+	// 保存付款记录
+	record := &TransactionPayment{
+		ID:         ctx.GetStub().GetTxID(),
+		TxID:       txID,
+		Amount:     payment,
+		Reference:  reference,
+		Operator:   clientMSPID,
+		CreateTime: createTime,
+	}
+	err = s.putTransactionPayment(ctx, record)
+	if err != nil {
+		return err
+	}
+
+	oldStatus := transaction.Status
+	transaction.Status = PARTIALLY_PAID
+	if payment.Amount == balance {
+		transaction.Status = PAID
+	}
+	transaction.UpdateTime = createTime
+	return s.putTransaction(ctx, transaction, oldStatus)
+}
+
+// QueryTransactionPayments 查询交易的托管付款记录和未付余额（仅拥有结算或交易权限的组织可以调用）
+func (s *SmartContract) QueryTransactionPayments(ctx contractapi.TransactionContextInterface, txID string) (*TransactionPaymentSummary, error) {
+	// 验证调用者所在组织是否拥有结算或交易权限
+	err := s.checkCapability(ctx, "查询付款记录", CAP_SETTLEMENT, CAP_TRADING)
+	if err != nil {
+		return nil, err
+	}
+
+	// 确认交易存在
+	_, err = s.getTransaction(ctx, txID)
+	if err != nil {
+		return nil, err
+	}
+
+	privateDetails, err := s.getTransactionPrivateDetails(ctx, txID)
+	if err != nil {
+		return nil, err
+	}
+	if privateDetails == nil {
+		return nil, fmt.Errorf("交易 %s 没有私有数据中的成交价格", txID)
+	}
+
+	payments, paid, err := s.getTransactionPayments(ctx, txID)
+	if err != nil {
+		return nil, err
+	}
+
+	currency := privateDetails.Price.Currency
+	return &TransactionPaymentSummary{
+		TxID:     txID,
+		Price:    privateDetails.Price,
+		Paid:     Money{Amount: paid, Currency: currency},
+		Balance:  Money{Amount: privateDetails.Price.Amount - paid, Currency: currency},
+		Payments: payments,
+	}, nil
+}
+
+// 通用方法：读取交易的托管付款记录（按登记时间排序），并返回已付金额（最小货币单位）
+func (s *SmartContract) getTransactionPayments(ctx contractapi.TransactionContextInterface, txID string) ([]*TransactionPayment, int64, error) {
+	iterator, err := ctx.GetStub().GetPrivateDataByPartialCompositeKey(TRANSACTION_PRIVATE_COLLECTION, PAYMENT, []string{txID})
+	if err != nil {
+		return nil, 0, fmt.Errorf("查询付款记录失败：%v", err)
+	}
+	defer iterator.Close()
+
+	payments := make([]*TransactionPayment, 0)
+	var paid int64
+	for iterator.HasNext() {
+		queryResponse, err := iterator.Next()
+		if err != nil {
+			return nil, 0, fmt.Errorf("获取下一条记录失败：%v", err)
+		}
+
+		var payment TransactionPayment
+		err = json.Unmarshal(queryResponse.Value, &payment)
+		if err != nil {
+			return nil, 0, fmt.Errorf("解析付款记录失败：%v", err)
+		}
+		payments = append(payments, &payment)
+		paid += payment.Amount.Amount
+	}
+
+	sort.Slice(payments, func(i, j int) bool {
+		return payments[i].CreateTime.Before(payments[j].CreateTime)
+	})
+	return payments, paid, nil
+}
+
+// 通用方法：保存托管付款记录到私有数据集合
+func (s *SmartContract) putTransactionPayment(ctx contractapi.TransactionContextInterface, payment *TransactionPayment) error {
+	key, err := s.getCompositeKey(ctx, PAYMENT, []string{payment.TxID, payment.ID})
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(payment)
+	if err != nil {
+		return fmt.Errorf("序列化付款记录失败：%v", err)
+	}
+
+	err = ctx.GetStub().PutPrivateData(TRANSACTION_PRIVATE_COLLECTION, key, data)
+	if err != nil {
+		return fmt.Errorf("保存付款记录失败：%v", err)
+	}
+	return nil
+}
@@ -0,0 +1,213 @@
+package main
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// registerTestSigner 登记交易方并为其登记签署公钥，返回对应的私钥
+func registerTestSigner(t *testing.T, s *SmartContract, stub *mockStub, id string) *ecdsa.PrivateKey {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := newTestContext(stub)
+	stub.setCaller(t, REALTY_ORG_MSPID, "client", nil)
+	idHash := sha256.Sum256([]byte(id))
+	err = s.RegisterParty(ctx, id, string(PERSON), hex.EncodeToString(idHash[:]), id, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = s.RegisterPartyPublicKey(ctx, id, string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+// putTestTransaction 写入待付款的交易及其私有数据
+func putTestTransaction(t *testing.T, s *SmartContract, stub *mockStub, transaction *Transaction, details *TransactionPrivateDetails) {
+	t.Helper()
+
+	ctx := newTestContext(stub)
+	hash, err := s.putTransactionPrivateDetails(ctx, details)
+	if err != nil {
+		t.Fatal(err)
+	}
+	transaction.PrivateDataHash = hash
+	err = s.putTransaction(ctx, transaction, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// approveTestTransaction 以交易方的私钥签署交易条款并提交签署
+func approveTestTransaction(t *testing.T, s *SmartContract, stub *mockStub, txID string, partyID string, key *ecdsa.PrivateKey) {
+	t.Helper()
+
+	ctx := newTestContext(stub)
+	stub.setCaller(t, TRADE_ORG_MSPID, "client", nil)
+	transaction, err := s.getTransaction(ctx, txID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	details, err := s.getTransactionPrivateDetails(ctx, txID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, termsHash, err := transactionTerms(transaction, details)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig, err := ecdsa.SignASN1(rand.Reader, key, termsHash)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = s.ApproveTransaction(ctx, txID, partyID, base64.StdEncoding.EncodeToString(sig))
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRecordPaymentBalance(t *testing.T) {
+	type payment struct {
+		amount    string
+		reference string
+		wantErr   bool
+	}
+	tests := []struct {
+		name       string
+		approved   bool
+		payments   []payment
+		wantStatus TransactionStatus
+		wantPaid   int64
+	}{
+		{
+			name:       "部分付款",
+			approved:   false,
+			payments:   []payment{{"400000.00", "R1", false}},
+			wantStatus: PARTIALLY_PAID, wantPaid: 40000000,
+		},
+		{
+			name:       "分期付清",
+			approved:   true,
+			payments:   []payment{{"400000.00", "R1", false}, {"600000.00", "R2", false}},
+			wantStatus: PAID, wantPaid: 100000000,
+		},
+		{
+			name:       "一次付清",
+			approved:   true,
+			payments:   []payment{{"1000000", "R1", false}},
+			wantStatus: PAID, wantPaid: 100000000,
+		},
+		{
+			name:       "超过未付余额",
+			approved:   true,
+			payments:   []payment{{"400000.00", "R1", false}, {"600000.01", "R2", true}},
+			wantStatus: PARTIALLY_PAID, wantPaid: 40000000,
+		},
+		{
+			name:       "首笔付款超过成交价格",
+			approved:   true,
+			payments:   []payment{{"1000000.01", "R1", true}},
+			wantStatus: PENDING, wantPaid: 0,
+		},
+		{
+			name:       "付清前买卖双方未签署",
+			approved:   false,
+			payments:   []payment{{"400000.00", "R1", false}, {"600000.00", "R2", true}},
+			wantStatus: PARTIALLY_PAID, wantPaid: 40000000,
+		},
+		{
+			name:       "银行流水号重复登记",
+			approved:   true,
+			payments:   []payment{{"400000.00", "R1", false}, {"100000.00", "R1", true}},
+			wantStatus: PARTIALLY_PAID, wantPaid: 40000000,
+		},
+		{
+			name:       "付款金额超出币种精度",
+			approved:   true,
+			payments:   []payment{{"0.001", "R1", true}},
+			wantStatus: PENDING, wantPaid: 0,
+		},
+		{
+			name:       "付款金额为0",
+			approved:   true,
+			payments:   []payment{{"0", "R1", true}},
+			wantStatus: PENDING, wantPaid: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &SmartContract{}
+			stub := newMockStub()
+			ctx := newTestContext(stub)
+
+			sellerKey := registerTestSigner(t, s, stub, "S")
+			buyerKey := registerTestSigner(t, s, stub, "B")
+			putTestTransaction(t, s, stub, &Transaction{
+				ID:           "T1",
+				RealEstateID: "R1",
+				Seller:       "S",
+				Share:        FULL_SHARE,
+				Status:       PENDING,
+			}, &TransactionPrivateDetails{
+				TxID:  "T1",
+				Buyer: "B",
+				Price: Money{Amount: 100000000, Currency: "CNY"},
+				Salt:  strings.Repeat("0", 32),
+			})
+			if tt.approved {
+				approveTestTransaction(t, s, stub, "T1", "S", sellerKey)
+				approveTestTransaction(t, s, stub, "T1", "B", buyerKey)
+			}
+
+			stub.setCaller(t, BANK_ORG_MSPID, "client", nil)
+			for i, p := range tt.payments {
+				// 付款记录ID为登记付款的 Fabric 交易ID
+				stub.txID = fmt.Sprintf("pay%d", i)
+				err := s.RecordPayment(ctx, "T1", p.amount, p.reference)
+				if (err != nil) != p.wantErr {
+					t.Fatalf("第%d笔付款 %s 错误 = %v，期望出错 = %v", i+1, p.amount, err, p.wantErr)
+				}
+			}
+
+			transaction, err := s.getTransaction(ctx, "T1")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if transaction.Status != tt.wantStatus {
+				t.Fatalf("交易状态 = %s，期望 %s", transaction.Status, tt.wantStatus)
+			}
+
+			summary, err := s.QueryTransactionPayments(ctx, "T1")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if summary.Paid.Amount != tt.wantPaid {
+				t.Fatalf("已付金额 = %s，期望 %d", summary.Paid.String(), tt.wantPaid)
+			}
+			if summary.Balance.Amount != 100000000-tt.wantPaid {
+				t.Fatalf("未付余额 = %s，期望 %d", summary.Balance.String(), 100000000-tt.wantPaid)
+			}
+		})
+	}
+}
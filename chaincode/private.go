@@ -0,0 +1,195 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+
+	"github.com/hyperledger/fabric-contract-api-go/v2/contractapi"
+)
+
+// TRANSACTION_PRIVATE_COLLECTION 交易私有数据集合（仅银行组织和交易平台组织的节点保存，见 collections_config.json）
+const TRANSACTION_PRIVATE_COLLECTION = "transactionPrivateCollection"
+
+// TRANSACTION_BUYER_INDEX 交易买家索引（保存在交易私有数据集合中，复合键：TX-BUYER_买家_交易ID，用于删除交易方前检查其是否参与过交易）
+const TRANSACTION_BUYER_INDEX = "TX-BUYER"
+
+// TRANSACTION_PRIVATE_TRANSIENT_KEY 生成交易时通过 transient map 传入私有数据使用的键
+const TRANSACTION_PRIVATE_TRANSIENT_KEY = "transaction"
+
+// TransactionPrivateInput 生成交易时通过 transient map 传入的私有数据
+type TransactionPrivateInput struct {
+	Buyer    string `json:"buyer"`    // 买家（交易方ID）
+	Price    string `json:"price"`    // 成交价格（十进制字符串）
+	Currency string `json:"currency"` // 币种（ISO 4217 代码）
+	Salt     string `json:"salt"`     // 随机盐值，防止通过穷举价格反推公开的哈希
+}
+
+// TransactionPrivateDetails 交易私有数据（保存在私有数据集合中，公开状态只保存其哈希）
+type TransactionPrivateDetails struct {
+	TxID  string `json:"txId"`  // 交易ID
+	Buyer string `json:"buyer"` // 买家（交易方ID）
+	Price Money  `json:"price"` // 成交价格
+	Salt  string `json:"salt"`  // 随机盐值
+}
+
+// QueryTransactionPrivateDetails 查询交易私有数据（仅拥有结算或交易权限的组织可以调用）
+func (s *SmartContract) QueryTransactionPrivateDetails(ctx contractapi.TransactionContextInterface, txID string) (*TransactionPrivateDetails, error) {
+	// 验证调用者所在组织是否拥有结算或交易权限
+	err := s.checkCapability(ctx, "查询交易私有数据", CAP_SETTLEMENT, CAP_TRADING)
+	if err != nil {
+		return nil, err
+	}
+
+	details, err := s.getTransactionPrivateDetails(ctx, txID)
+	if err != nil {
+		return nil, err
+	}
+	if details == nil {
+		return nil, fmt.Errorf("交易 %s 没有私有数据", txID)
+	}
+	return details, nil
+}
+
+// VerifyTransactionPrivateData 校验披露的买家、价格和盐值是否与私有数据哈希一致（仅拥有登记权限的组织可以调用）
+func (s *SmartContract) VerifyTransactionPrivateData(ctx contractapi.TransactionContextInterface, txID string, buyer string, price string, currency string, salt string) (bool, error) {
+	// 验证调用者所在组织是否拥有登记权限
+	err := s.checkCapability(ctx, "校验交易私有数据", CAP_REGISTRY)
+	if err != nil {
+		return false, err
+	}
+
+	priceMoney, err := parseMoney(price, currency)
+	if err != nil {
+		return false, fmt.Errorf("价格无效：%v", err)
+	}
+
+	disclosed, err := json.Marshal(TransactionPrivateDetails{
+		TxID:  txID,
+		Buyer: buyer,
+		Price: priceMoney,
+		Salt:  salt,
+	})
+	if err != nil {
+		return false, fmt.Errorf("序列化披露数据失败：%v", err)
+	}
+
+	key, err := s.getCompositeKey(ctx, TRANSACTION, []string{txID})
+	if err != nil {
+		return false, err
+	}
+
+	// 非集合成员的节点也保存私有数据的哈希
+	onChainHash, err := ctx.GetStub().GetPrivateDataHash(TRANSACTION_PRIVATE_COLLECTION, key)
+	if err != nil {
+		return false, fmt.Errorf("读取私有数据哈希失败：%v", err)
+	}
+	if onChainHash == nil {
+		return false, fmt.Errorf("交易 %s 没有私有数据", txID)
+	}
+
+	disclosedHash := sha256.Sum256(disclosed)
+	return bytes.Equal(onChainHash, disclosedHash[:]), nil
+}
+
+// 通用方法：读取并校验 transient map 中的交易私有数据
+func (s *SmartContract) getTransactionPrivateInput(ctx contractapi.TransactionContextInterface) (*TransactionPrivateInput, error) {
+	transient, err := ctx.GetStub().GetTransient()
+	if err != nil {
+		return nil, fmt.Errorf("读取 transient 数据失败：%v", err)
+	}
+
+	data, ok := transient[TRANSACTION_PRIVATE_TRANSIENT_KEY]
+	if !ok {
+		return nil, fmt.Errorf("买家和价格必须通过 transient map 的 %s 字段传入", TRANSACTION_PRIVATE_TRANSIENT_KEY)
+	}
+
+	var input TransactionPrivateInput
+	err = json.Unmarshal(data, &input)
+	if err != nil {
+		return nil, fmt.Errorf("解析交易私有数据失败：%v", err)
+	}
+	if len(input.Salt) < 32 {
+		return nil, fmt.Errorf("盐值长度不能少于32个字符")
+	}
+	return &input, nil
+}
+
+// 通用方法：保存交易私有数据，返回公开状态中保存的哈希（与 GetPrivateDataHash 的结果一致）
+func (s *SmartContract) putTransactionPrivateDetails(ctx contractapi.TransactionContextInterface, details *TransactionPrivateDetails) (string, error) {
+	key, err := s.getCompositeKey(ctx, TRANSACTION, []string{details.TxID})
+	if err != nil {
+		return "", err
+	}
+
+	data, err := json.Marshal(details)
+	if err != nil {
+		return "", fmt.Errorf("序列化交易私有数据失败：%v", err)
+	}
+
+	err = ctx.GetStub().PutPrivateData(TRANSACTION_PRIVATE_COLLECTION, key, data)
+	if err != nil {
+		return "", fmt.Errorf("保存交易私有数据失败：%v", err)
+	}
+
+	hash := sha256.Sum256(data)
+	return hex.EncodeToString(hash[:]), nil
+}
+
+// 通用方法：读取交易私有数据（不存在时返回 nil）
+func (s *SmartContract) getTransactionPrivateDetails(ctx contractapi.TransactionContextInterface, txID string) (*TransactionPrivateDetails, error) {
+	key, err := s.getCompositeKey(ctx, TRANSACTION, []string{txID})
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := ctx.GetStub().GetPrivateData(TRANSACTION_PRIVATE_COLLECTION, key)
+	if err != nil {
+		return nil, fmt.Errorf("读取交易私有数据失败：%v", err)
+	}
+	if data == nil {
+		return nil, nil
+	}
+
+	var details TransactionPrivateDetails
+	err = json.Unmarshal(data, &details)
+	if err != nil {
+		return nil, fmt.Errorf("解析交易私有数据失败：%v", err)
+	}
+	return &details, nil
+}
+
+// 通用方法：检查交易的私有数据已保存且与公开状态中保存的哈希一致（非集合成员的节点也保存私有数据的哈希）
+func (s *SmartContract) checkTransactionPrivateDataHash(ctx contractapi.TransactionContextInterface, transaction *Transaction) error {
+	key, err := s.getCompositeKey(ctx, TRANSACTION, []string{transaction.ID})
+	if err != nil {
+		return err
+	}
+
+	onChainHash, err := ctx.GetStub().GetPrivateDataHash(TRANSACTION_PRIVATE_COLLECTION, key)
+	if err != nil {
+		return fmt.Errorf("读取私有数据哈希失败：%v", err)
+	}
+	if onChainHash == nil {
+		return fmt.Errorf("交易 %s 没有私有数据", transaction.ID)
+	}
+	if hex.EncodeToString(onChainHash) != transaction.PrivateDataHash {
+		return fmt.Errorf("交易 %s 的私有数据与公开状态中保存的哈希不一致", transaction.ID)
+	}
+	return nil
+}
+
+// 通用方法：在交易私有数据集合中写入索引键
+func (s *SmartContract) putPrivateIndex(ctx contractapi.TransactionContextInterface, indexName string, attributes []string) error {
+	key, err := s.getCompositeKey(ctx, indexName, attributes)
+	if err != nil {
+		return err
+	}
+	err = ctx.GetStub().PutPrivateData(TRANSACTION_PRIVATE_COLLECTION, key, []byte{0x00})
+	if err != nil {
+		return fmt.Errorf("保存私有数据索引失败：%v", err)
+	}
+	return nil
+}
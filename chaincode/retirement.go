@@ -0,0 +1,116 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/v2/contractapi"
+)
+
+// RETIREMENT_DATE_LAYOUT 注销生效日期的格式
+const RETIREMENT_DATE_LAYOUT = "2006-01-02"
+
+// Retirement 房产注销信息
+type Retirement struct {
+	Reason        string    `json:"reason"`        // 注销原因（如拆除、征收）
+	EffectiveDate string    `json:"effectiveDate"` // 注销生效日期（YYYY-MM-DD）
+	Operator      string    `json:"operator"`      // 办理注销的组织 MSP ID
+	TxID          string    `json:"txId"`          // 办理注销的 Fabric 交易ID
+	RetireTime    time.Time `json:"retireTime"`    // 办理注销的时间
+}
+
+// RetireRealEstate 注销已拆除或被征收的房产（仅拥有登记权限的组织可以调用）
+//
+// 只有正常状态且没有未注销抵押的房产可以注销，注销后房产不能再交易、抵押、冻结或更正，但仍可查询房产信息和历史记录
+func (s *SmartContract) RetireRealEstate(ctx contractapi.TransactionContextInterface, realEstateID string, reason string, effectiveDate string) error {
+	// 验证调用者所在组织是否拥有登记权限
+	err := s.checkCapability(ctx, "注销房产", CAP_REGISTRY)
+	if err != nil {
+		return err
+	}
+
+	clientMSPID, err := s.getClientIdentityMSPID(ctx)
+	if err != nil {
+		return fmt.Errorf("获取调用者身份失败：%v", err)
+	}
+
+	// 以账本交易时间作为注销时间
+	retireTime, err := s.getTxTime(ctx)
+	if err != nil {
+		return err
+	}
+
+	// 参数验证
+	if len(strings.TrimSpace(reason)) == 0 {
+		return fmt.Errorf("注销原因不能为空")
+	}
+	effective, err := time.Parse(RETIREMENT_DATE_LAYOUT, effectiveDate)
+	if err != nil {
+		return fmt.Errorf("注销生效日期格式错误，应为 YYYY-MM-DD 格式：%v", err)
+	}
+	if effective.After(retireTime) {
+		return fmt.Errorf("注销生效日期不能晚于当前日期")
+	}
+
+	// 查询房产信息
+	realEstate, err := s.getRealEstate(ctx, realEstateID)
+	if err != nil {
+		return err
+	}
+
+	err = s.checkNotRetired(realEstate, "重复注销")
+	if err != nil {
+		return err
+	}
+	err = s.checkNotFrozen(ctx, realEstate, "注销")
+	if err != nil {
+		return err
+	}
+	if realEstate.Status != NORMAL {
+		return fmt.Errorf("房产 %s 当前状态为 %s，无法注销", realEstateID, realEstate.Status)
+	}
+
+	// 存在未注销抵押的房产不能注销
+	mortgages, err := s.QueryMortgageList(ctx, realEstateID)
+	if err != nil {
+		return err
+	}
+	for _, mortgage := range mortgages {
+		if mortgage.Status == MORTGAGE_ACTIVE {
+			return fmt.Errorf("房产存在未注销的抵押 %s，无法注销", mortgage.ID)
+		}
+	}
+
+	// 使房产待答复的报价失效
+	err = s.closePendingOffers(ctx, realEstateID, "", retireTime)
+	if err != nil {
+		return err
+	}
+
+	// 更新房产状态
+	realEstate.Status = RETIRED
+	realEstate.Retirement = &Retirement{
+		Reason:        strings.TrimSpace(reason),
+		EffectiveDate: effectiveDate,
+		Operator:      clientMSPID,
+		TxID:          ctx.GetStub().GetTxID(),
+		RetireTime:    retireTime,
+	}
+	realEstate.UpdateTime = retireTime
+
+	err = s.putRealEstate(ctx, realEstate, NORMAL)
+	if err != nil {
+		return err
+	}
+
+	return s.setEvent(ctx, EVENT_REAL_ESTATE_RETIRED, realEstateID, retireTime, realEstate)
+}
+
+// 通用方法：检查房产是否已注销
+func (s *SmartContract) checkNotRetired(realEstate *RealEstate, action string) error {
+	if realEstate.Status != RETIRED {
+		return nil
+	}
+	return fmt.Errorf("房产 %s 已于 %s 注销（%s），无法%s", realEstate.ID, realEstate.Retirement.EffectiveDate, realEstate.Retirement.Reason, action)
+}
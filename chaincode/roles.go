@@ -0,0 +1,248 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/hyperledger/fabric-chaincode-go/v2/pkg/cid"
+	"github.com/hyperledger/fabric-contract-api-go/v2/contractapi"
+)
+
+// ROLE_ATTRIBUTE 保存角色的证书属性名（由 Fabric CA 签发，如 role=clerk:ecert，多个角色用逗号分隔）
+const ROLE_ATTRIBUTE = "role"
+
+// 组织内角色常量
+const (
+	ROLE_REGISTRAR          = "registrar"          // 登记员（不动产登记机构：登记房产和交易方）
+	ROLE_CLERK              = "clerk"              // 经办人（交易平台：生成、取消交易，处理报价，提交交易方签署）
+	ROLE_SETTLEMENT_OFFICER = "settlement-officer" // 结算专员（银行：登记付款，完成、取消交易，办理抵押）
+	ROLE_AUDITOR            = "auditor"            // 审计员（查询私有数据、校验披露数据）
+	ROLE_JUDICIAL_OFFICER   = "judicial-officer"   // 司法专员（冻结、解除冻结房产）
+	ROLE_TAX_OFFICER        = "tax-officer"        // 税务专员（更新交易税费标准）
+)
+
+// CONFIG 链码配置文档类型（复合键：CONFIG_配置项）
+const CONFIG = "CONFIG"
+
+// ROLE_ENFORCEMENT_CONFIG 是否启用角色检查的配置项
+const ROLE_ENFORCEMENT_CONFIG = "ROLE_ENFORCEMENT"
+
+// functionRoles 各合约函数需要的角色（具备其中任一角色即可调用，nil 表示不需要角色）
+//
+// 组织限制仍由各函数自行检查；新增合约函数时必须在这里声明，未声明的函数一律拒绝调用
+var functionRoles = map[string][]string{
+	// 房产
+	"CreateRealEstate":       {ROLE_REGISTRAR},
+	"QueryRealEstate":        nil,
+	"QueryRealEstateHistory": nil,
+	"QueryRealEstateList":    nil,
+	"QueryRealEstateByOwner": nil,
+
+	// 房产信息更正
+	"UpdateRealEstate":          {ROLE_REGISTRAR},
+	"QueryRealEstateAmendments": nil,
+
+	// 房产注销
+	"RetireRealEstate": {ROLE_REGISTRAR},
+
+	// 房产键级背书策略（设置时函数内部检查组织管理员身份）
+	"SetRealEstateEndorsementPolicy":   nil,
+	"QueryRealEstateEndorsementPolicy": nil,
+
+	// 司法冻结
+	"FreezeRealEstate":   {ROLE_REGISTRAR, ROLE_JUDICIAL_OFFICER},
+	"UnfreezeRealEstate": {ROLE_REGISTRAR, ROLE_JUDICIAL_OFFICER},
+	"ExpireFreeze":       nil,
+	"QueryFreezeList":    nil,
+
+	// 交易
+	"CreateTransaction":              {ROLE_CLERK},
+	"OpenTransaction":                {ROLE_CLERK},
+	"SettleTransaction":              {ROLE_SETTLEMENT_OFFICER},
+	"CompleteTransaction":            {ROLE_SETTLEMENT_OFFICER},
+	"CancelTransaction":              {ROLE_CLERK, ROLE_SETTLEMENT_OFFICER},
+	"ExpireTransaction":              nil,
+	"QueryTransaction":               nil,
+	"QueryTransactionHistory":        nil,
+	"QueryTransactionList":           nil,
+	"QueryTransactionsByParty":       nil,
+	"QueryTransactionPrivateDetails": {ROLE_CLERK, ROLE_SETTLEMENT_OFFICER, ROLE_AUDITOR},
+	"VerifyTransactionPrivateData":   {ROLE_REGISTRAR, ROLE_AUDITOR},
+	"ApproveTransaction":             {ROLE_CLERK},
+	"QueryTransactionSigningPayload": {ROLE_CLERK, ROLE_SETTLEMENT_OFFICER, ROLE_AUDITOR},
+	"RecordPayment":                  {ROLE_SETTLEMENT_OFFICER},
+	"QueryTransactionPayments":       {ROLE_CLERK, ROLE_SETTLEMENT_OFFICER, ROLE_AUDITOR},
+	"QueryTransactionFees":           {ROLE_CLERK, ROLE_SETTLEMENT_OFFICER, ROLE_AUDITOR},
+
+	// 交易税费标准
+	"SetFeeSchedule":   {ROLE_REGISTRAR, ROLE_TAX_OFFICER},
+	"QueryFeeSchedule": nil,
+
+	// 文档存证（函数内部按关联对象类型检查组织权限）
+	"AttachDocument": {ROLE_REGISTRAR, ROLE_CLERK, ROLE_SETTLEMENT_OFFICER},
+	"VerifyDocument": nil,
+	"QueryDocuments": nil,
+
+	// 报价
+	"SubmitOffer":               {ROLE_CLERK},
+	"CounterOffer":              {ROLE_CLERK},
+	"AcceptOffer":               {ROLE_CLERK},
+	"RejectOffer":               {ROLE_CLERK},
+	"QueryOffer":                nil,
+	"QueryOffersByRealEstate":   nil,
+	"QueryOfferPrivateDetails":  {ROLE_CLERK, ROLE_SETTLEMENT_OFFICER, ROLE_AUDITOR},
+	"QueryOfferResponsePayload": {ROLE_CLERK},
+
+	// 抵押
+	"RegisterMortgage":        {ROLE_SETTLEMENT_OFFICER},
+	"ConsentMortgageTransfer": {ROLE_SETTLEMENT_OFFICER},
+	"ReleaseMortgage":         {ROLE_SETTLEMENT_OFFICER},
+	"QueryMortgageList":       nil,
+
+	// 交易方
+	"RegisterParty":          {ROLE_REGISTRAR},
+	"UpdateParty":            {ROLE_REGISTRAR},
+	"RegisterPartyPublicKey": {ROLE_REGISTRAR},
+	"DeleteParty":            {ROLE_REGISTRAR},
+	"QueryParty":             nil,
+	"QueryPartyList":         nil,
+
+	// 组织权限和角色检查治理（函数内部检查调用者所在组织是否参与治理）
+	"ProposeOrgCapabilities":      nil,
+	"ProposeRoleEnforcement":      nil,
+	"QueryRoleEnforcement":        nil,
+	"ApproveGovernanceProposal":   nil,
+	"QueryGovernanceProposal":     nil,
+	"QueryGovernanceProposalList": nil,
+	"QueryOrgCapabilities":        nil,
+	"QueryOrgCapabilitiesList":    nil,
+
+	// 管理（函数内部检查组织管理员身份）
+	"MigrateStorage": nil,
+	"MigrateAmounts": nil,
+	"Hello":          nil,
+	"InitLedger":     nil,
+}
+
+// ProposeRoleEnforcement 提议启用或关闭角色检查（仅参与治理的组织成员可以调用，提案组织视为已批准）
+//
+// 与组织权限变更一样由现有组织过半数批准后生效（见 ApproveGovernanceProposal），单个组织不能关闭角色检查
+func (s *SmartContract) ProposeRoleEnforcement(ctx contractapi.TransactionContextInterface, proposalID string, enabled bool) error {
+	clientMSPID, members, err := s.checkGovernanceMember(ctx, "提交治理提案")
+	if err != nil {
+		return err
+	}
+
+	// 以账本交易时间作为创建时间
+	createTime, err := s.getTxTime(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = s.checkProposalNotExists(ctx, proposalID)
+	if err != nil {
+		return err
+	}
+
+	proposal := &GovernanceProposal{
+		ID:              proposalID,
+		Type:            PROPOSAL_ROLE_ENFORCEMENT,
+		Capabilities:    []string{},
+		RoleEnforcement: &enabled,
+		Proposer:        clientMSPID,
+		Approvals:       []string{clientMSPID},
+		Status:          PROPOSAL_PENDING,
+		CreateTime:      createTime,
+		UpdateTime:      createTime,
+	}
+
+	return s.applyProposalIfApproved(ctx, proposal, members, createTime)
+}
+
+// QueryRoleEnforcement 查询是否启用了角色检查（默认启用）
+func (s *SmartContract) QueryRoleEnforcement(ctx contractapi.TransactionContextInterface) (bool, error) {
+	key, err := s.getCompositeKey(ctx, CONFIG, []string{ROLE_ENFORCEMENT_CONFIG})
+	if err != nil {
+		return false, err
+	}
+
+	bytes, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return false, fmt.Errorf("读取角色检查配置失败：%v", err)
+	}
+	if bytes == nil {
+		return true, nil
+	}
+
+	var enabled bool
+	err = json.Unmarshal(bytes, &enabled)
+	if err != nil {
+		return false, fmt.Errorf("解析角色检查配置失败：%v", err)
+	}
+	return enabled, nil
+}
+
+// 通用方法：保存是否启用角色检查（只在角色检查提案生效时调用）
+func (s *SmartContract) putRoleEnforcement(ctx contractapi.TransactionContextInterface, enabled bool) error {
+	key, err := s.getCompositeKey(ctx, CONFIG, []string{ROLE_ENFORCEMENT_CONFIG})
+	if err != nil {
+		return err
+	}
+	return s.putState(ctx, key, enabled)
+}
+
+// 通用方法：在每个合约函数执行前检查调用者是否具备该函数声明的角色
+func (s *SmartContract) checkFunctionRoles(ctx contractapi.TransactionContextInterface) error {
+	fn, _ := ctx.GetStub().GetFunctionAndParameters()
+	fn = unqualifiedFunctionName(fn)
+
+	roles, ok := functionRoles[fn]
+	if !ok {
+		return fmt.Errorf("合约函数 %s 未声明所需角色，拒绝调用", fn)
+	}
+	if len(roles) == 0 {
+		return nil
+	}
+
+	enabled, err := s.QueryRoleEnforcement(ctx)
+	if err != nil {
+		return err
+	}
+	if !enabled {
+		return nil
+	}
+
+	value, found, err := cid.GetAttributeValue(ctx.GetStub(), ROLE_ATTRIBUTE)
+	if err != nil {
+		return fmt.Errorf("读取调用者证书属性失败：%v", err)
+	}
+	if found {
+		for _, role := range strings.Split(value, ",") {
+			for _, required := range roles {
+				if strings.TrimSpace(role) == required {
+					return nil
+				}
+			}
+		}
+	}
+
+	if len(roles) == 1 {
+		return fmt.Errorf("调用 %s 需要角色 %s，当前身份的证书属性 %s 中没有该角色", fn, roles[0], ROLE_ATTRIBUTE)
+	}
+	return fmt.Errorf("调用 %s 需要以下角色之一：%s，当前身份的证书属性 %s 中没有这些角色", fn, strings.Join(roles, "、"), ROLE_ATTRIBUTE)
+}
+
+// unqualifiedFunctionName 去掉函数名中的合约名前缀（合约名:函数名），并与合约一样将首字母转为大写
+func unqualifiedFunctionName(fn string) string {
+	if i := strings.LastIndex(fn, ":"); i != -1 {
+		fn = fn[i+1:]
+	}
+	if fn == "" {
+		return fn
+	}
+	runes := []rune(fn)
+	runes[0] = unicode.ToUpper(runes[0])
+	return string(runes)
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestCheckFunctionRoles(t *testing.T) {
+	tests := []struct {
+		name     string
+		function string
+		attrs    map[string]string
+		wantErr  bool
+	}{
+		{"不需要角色的函数", "QueryRealEstate", nil, false},
+		{"未声明角色的函数", "UnknownFunction", map[string]string{ROLE_ATTRIBUTE: ROLE_REGISTRAR}, true},
+		{"证书没有角色属性", "CreateRealEstate", nil, true},
+		{"具备所需角色", "CreateRealEstate", map[string]string{ROLE_ATTRIBUTE: ROLE_REGISTRAR}, false},
+		{"多个角色之一", "CancelTransaction", map[string]string{ROLE_ATTRIBUTE: "auditor, settlement-officer"}, false},
+		{"角色不匹配", "CreateRealEstate", map[string]string{ROLE_ATTRIBUTE: ROLE_CLERK}, true},
+		{"带合约名前缀", "SmartContract:createRealEstate", map[string]string{ROLE_ATTRIBUTE: ROLE_CLERK}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &SmartContract{}
+			stub := newMockStub()
+			ctx := newTestContext(stub)
+			stub.setCaller(t, REALTY_ORG_MSPID, "client", tt.attrs)
+			stub.args = [][]byte{[]byte(tt.function)}
+
+			// 账本上没有配置时默认检查角色
+			err := s.checkFunctionRoles(ctx)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("角色检查错误 = %v，期望出错 = %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRoleEnforcementRequiresMajority(t *testing.T) {
+	s := &SmartContract{}
+	stub := newMockStub()
+	ctx := newTestContext(stub)
+
+	enabled, err := s.QueryRoleEnforcement(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !enabled {
+		t.Fatal("账本上没有配置时应默认启用角色检查")
+	}
+
+	// 单个组织的提案不能关闭角色检查
+	stub.setCaller(t, REALTY_ORG_MSPID, "admin", nil)
+	if err := s.ProposeRoleEnforcement(ctx, "P1", false); err != nil {
+		t.Fatal(err)
+	}
+	enabled, err = s.QueryRoleEnforcement(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !enabled {
+		t.Fatal("只有一个组织批准时角色检查已被关闭")
+	}
+
+	stub.args = [][]byte{[]byte("CreateRealEstate")}
+	if err := s.checkFunctionRoles(ctx); err == nil {
+		t.Fatal("提案生效前，没有角色属性的调用者不应通过角色检查")
+	}
+
+	// 不参与治理的组织不能批准
+	stub.setCaller(t, "Org9MSP", "admin", nil)
+	if err := s.ApproveGovernanceProposal(ctx, "P1"); err == nil {
+		t.Fatal("不参与治理的组织批准了提案")
+	}
+
+	// 过半数组织批准后生效
+	stub.setCaller(t, BANK_ORG_MSPID, "admin", nil)
+	if err := s.ApproveGovernanceProposal(ctx, "P1"); err != nil {
+		t.Fatal(err)
+	}
+	proposal, err := s.QueryGovernanceProposal(ctx, "P1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if proposal.Status != PROPOSAL_APPLIED || proposal.Type != PROPOSAL_ROLE_ENFORCEMENT {
+		t.Fatalf("提案 = %+v，期望角色检查提案已生效", proposal)
+	}
+	enabled, err = s.QueryRoleEnforcement(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if enabled {
+		t.Fatal("过半数组织批准后角色检查仍未关闭")
+	}
+	if err := s.checkFunctionRoles(ctx); err != nil {
+		t.Fatalf("关闭角色检查后仍然检查角色：%v", err)
+	}
+
+	// 角色检查提案不改变组织权限
+	records, err := s.QueryOrgCapabilitiesList(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != len(defaultOrgCapabilities) {
+		t.Fatalf("组织权限记录 = %d 条，期望 %d 条", len(records), len(defaultOrgCapabilities))
+	}
+	for _, record := range records {
+		if len(record.Capabilities) == 0 {
+			t.Fatalf("组织 %s 的权限被清空", record.MSPID)
+		}
+	}
+}
@@ -0,0 +1,244 @@
+package main
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/v2/shim"
+	"github.com/hyperledger/fabric-contract-api-go/v2/contractapi"
+	"github.com/hyperledger/fabric-protos-go-apiv2/ledger/queryresult"
+	"github.com/hyperledger/fabric-protos-go-apiv2/msp"
+	"github.com/hyperledger/fabric-protos-go-apiv2/peer"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// mockStub 测试使用的内存账本，只实现合约用到的方法（未实现的方法调用时会因接口为空而 panic）
+type mockStub struct {
+	shim.ChaincodeStubInterface
+	state      map[string][]byte
+	private    map[string]map[string][]byte
+	validation map[string][]byte
+	events     map[string][]byte
+	transient  map[string][]byte
+	args       [][]byte
+	creator    []byte
+	txID       string
+	txTime     time.Time
+
+	// nonMember 模拟不是私有数据集合成员的节点（如登记机构），读写私有数据时返回错误，只能读取哈希
+	nonMember bool
+}
+
+// newMockStub 创建空账本，交易时间固定为 2026-01-01
+func newMockStub() *mockStub {
+	return &mockStub{
+		state:      make(map[string][]byte),
+		private:    make(map[string]map[string][]byte),
+		validation: make(map[string][]byte),
+		events:     make(map[string][]byte),
+		txID:       "tx0",
+		txTime:     time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+}
+
+// newTestContext 创建使用内存账本的合约上下文
+func newTestContext(stub *mockStub) *contractapi.TransactionContext {
+	ctx := &contractapi.TransactionContext{}
+	ctx.SetStub(stub)
+	return ctx
+}
+
+// setCaller 以指定组织、OU 和证书属性生成调用者证书
+func (m *mockStub) setCaller(t *testing.T, mspID string, ou string, attrs map[string]string) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "user", OrganizationalUnit: []string{ou}},
+		NotBefore:    m.txTime.Add(-time.Hour),
+		NotAfter:     m.txTime.Add(time.Hour),
+	}
+	if attrs != nil {
+		// Fabric CA 证书属性扩展
+		value, err := json.Marshal(map[string]interface{}{"attrs": attrs})
+		if err != nil {
+			t.Fatal(err)
+		}
+		template.ExtraExtensions = []pkix.Extension{{Id: asn1.ObjectIdentifier{1, 2, 3, 4, 5, 6, 7, 8, 1}, Value: value}}
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	creator, err := proto.Marshal(&msp.SerializedIdentity{
+		Mspid:   mspID,
+		IdBytes: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.creator = creator
+}
+
+func (m *mockStub) GetFunctionAndParameters() (string, []string) {
+	if len(m.args) == 0 {
+		return "", []string{}
+	}
+	params := make([]string, 0, len(m.args)-1)
+	for _, arg := range m.args[1:] {
+		params = append(params, string(arg))
+	}
+	return string(m.args[0]), params
+}
+
+func (m *mockStub) GetArgs() [][]byte                        { return m.args }
+func (m *mockStub) GetTxID() string                          { return m.txID }
+func (m *mockStub) GetChannelID() string                     { return "mychannel" }
+func (m *mockStub) GetCreator() ([]byte, error)              { return m.creator, nil }
+func (m *mockStub) GetTransient() (map[string][]byte, error) { return m.transient, nil }
+func (m *mockStub) GetTxTimestamp() (*timestamppb.Timestamp, error) {
+	return timestamppb.New(m.txTime), nil
+}
+func (m *mockStub) SetEvent(name string, payload []byte) error { m.events[name] = payload; return nil }
+func (m *mockStub) GetState(key string) ([]byte, error)        { return m.state[key], nil }
+func (m *mockStub) PutState(key string, value []byte) error    { m.state[key] = value; return nil }
+func (m *mockStub) DelState(key string) error                  { delete(m.state, key); return nil }
+func (m *mockStub) GetStateValidationParameter(key string) ([]byte, error) {
+	return m.validation[key], nil
+}
+
+func (m *mockStub) SetStateValidationParameter(key string, ep []byte) error {
+	m.validation[key] = ep
+	return nil
+}
+
+func (m *mockStub) CreateCompositeKey(objectType string, attributes []string) (string, error) {
+	return shim.CreateCompositeKey(objectType, attributes)
+}
+
+func (m *mockStub) SplitCompositeKey(compositeKey string) (string, []string, error) {
+	parts := strings.Split(strings.Trim(compositeKey, "\x00"), "\x00")
+	return parts[0], parts[1:], nil
+}
+
+func (m *mockStub) GetStateByPartialCompositeKey(objectType string, keys []string) (shim.StateQueryIteratorInterface, error) {
+	return m.rangeQuery(m.state, objectType, keys)
+}
+
+func (m *mockStub) GetStateByPartialCompositeKeyWithPagination(objectType string, keys []string, pageSize int32, bookmark string) (shim.StateQueryIteratorInterface, *peer.QueryResponseMetadata, error) {
+	iterator, err := m.rangeQuery(m.state, objectType, keys)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// 书签为下一页第一条记录的键
+	start := 0
+	if len(bookmark) > 0 {
+		start = sort.Search(len(iterator.kvs), func(i int) bool { return iterator.kvs[i].Key >= bookmark })
+	}
+	end := start + int(pageSize)
+	next := ""
+	if end < len(iterator.kvs) {
+		next = iterator.kvs[end].Key
+	} else {
+		end = len(iterator.kvs)
+	}
+	iterator.kvs = iterator.kvs[start:end]
+
+	return iterator, &peer.QueryResponseMetadata{FetchedRecordsCount: int32(end - start), Bookmark: next}, nil
+}
+
+// errNotCollectionMember 非集合成员的节点读写私有数据时返回的错误
+var errNotCollectionMember = errors.New("节点不是私有数据集合成员")
+
+func (m *mockStub) GetPrivateData(collection string, key string) ([]byte, error) {
+	if m.nonMember {
+		return nil, errNotCollectionMember
+	}
+	return m.private[collection][key], nil
+}
+
+func (m *mockStub) GetPrivateDataHash(collection string, key string) ([]byte, error) {
+	value, ok := m.private[collection][key]
+	if !ok {
+		return nil, nil
+	}
+	hash := sha256.Sum256(value)
+	return hash[:], nil
+}
+
+func (m *mockStub) PutPrivateData(collection string, key string, value []byte) error {
+	if m.nonMember {
+		return errNotCollectionMember
+	}
+	if m.private[collection] == nil {
+		m.private[collection] = make(map[string][]byte)
+	}
+	m.private[collection][key] = value
+	return nil
+}
+
+func (m *mockStub) DelPrivateData(collection string, key string) error {
+	if m.nonMember {
+		return errNotCollectionMember
+	}
+	delete(m.private[collection], key)
+	return nil
+}
+
+func (m *mockStub) GetPrivateDataByPartialCompositeKey(collection string, objectType string, keys []string) (shim.StateQueryIteratorInterface, error) {
+	if m.nonMember {
+		return nil, errNotCollectionMember
+	}
+	return m.rangeQuery(m.private[collection], objectType, keys)
+}
+
+// rangeQuery 按键排序返回指定前缀的记录
+func (m *mockStub) rangeQuery(values map[string][]byte, objectType string, keys []string) (*mockIterator, error) {
+	prefix, err := shim.CreateCompositeKey(objectType, keys)
+	if err != nil {
+		return nil, err
+	}
+
+	iterator := &mockIterator{}
+	for key, value := range values {
+		if strings.HasPrefix(key, prefix) {
+			iterator.kvs = append(iterator.kvs, &queryresult.KV{Key: key, Value: value})
+		}
+	}
+	sort.Slice(iterator.kvs, func(i, j int) bool { return iterator.kvs[i].Key < iterator.kvs[j].Key })
+	return iterator, nil
+}
+
+// mockIterator 内存账本的范围查询结果
+type mockIterator struct {
+	kvs  []*queryresult.KV
+	next int
+}
+
+func (it *mockIterator) HasNext() bool { return it.next < len(it.kvs) }
+func (it *mockIterator) Close() error  { return nil }
+func (it *mockIterator) Next() (*queryresult.KV, error) {
+	kv := it.kvs[it.next]
+	it.next++
+	return kv, nil
+}
@@ -38,6 +38,7 @@ check_command() {
 log_info "检查必要的依赖..."
 check_command docker
 check_command docker-compose
+check_command openssl
 
 echo -e "\n${GREEN}================================${NC}"
 echo -e "${GREEN}   Fabric-Realty 一键安装脚本${NC}"
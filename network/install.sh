@@ -6,6 +6,7 @@
 # 描述: 自动部署三组织六节点的Fabric网络
 # 依赖:
 #   - docker & docker-compose
+#   - openssl（为演示用户签发角色属性）
 ###########################################
 
 set -e  # 遇到错误立即退出
@@ -125,7 +126,7 @@ handle_error() {
 
 # 健康检查函数
 check_prerequisites() {
-    local prerequisites=("docker" "docker-compose")
+    local prerequisites=("docker" "docker-compose" "openssl")
 
     for cmd in "${prerequisites[@]}"; do
         if ! command -v $cmd &> /dev/null; then
@@ -152,6 +153,37 @@ clean_environment() {
     ./uninstall.sh || handle_error "清理环境"
 }
 
+# 为组织的 User1 重新签发带角色属性的证书
+# cryptogen 生成的证书不带角色属性，这里沿用原私钥和主题，由组织 CA 重新签发，
+# 并按 Fabric CA 的格式写入属性扩展（OID 1.2.3.4.5.6.7.8.1，值为 {"attrs":{"role":"..."}}）
+issue_role_certificate() {
+    local org=$1    # 组织编号
+    local roles=$2  # 角色，多个角色用逗号分隔
+    local org_domain="org${org}.${DOMAIN}"
+    local org_path="crypto-config/peerOrganizations/${org_domain}"
+    local user_msp="${org_path}/users/User1@${org_domain}/msp"
+    local cert="${user_msp}/signcerts/User1@${org_domain}-cert.pem"
+    local work_dir=$(mktemp -d)
+
+    local attrs="{\"attrs\":{\"role\":\"${roles}\"}}"
+    cat > ${work_dir}/ext.cnf <<EOF
+basicConstraints=critical,CA:FALSE
+keyUsage=critical,digitalSignature
+subjectKeyIdentifier=hash
+authorityKeyIdentifier=keyid
+1.2.3.4.5.6.7.8.1=DER:$(printf '%s' "${attrs}" | od -An -tx1 | tr -d ' \n')
+EOF
+
+    openssl x509 -x509toreq -in ${cert} -signkey ${user_msp}/keystore/priv_sk -out ${work_dir}/user.csr &&
+    openssl x509 -req -in ${work_dir}/user.csr -days 3650 -sha256 \
+        -CA ${org_path}/ca/ca.${org_domain}-cert.pem -CAkey ${org_path}/ca/priv_sk \
+        -set_serial 0x$(openssl rand -hex 16) -extfile ${work_dir}/ext.cnf -out ${cert}
+    local result=$?
+
+    rm -rf ${work_dir}
+    return $result
+}
+
 ###########################################
 # 配置参数
 ###########################################
@@ -267,6 +299,10 @@ main() {
     # 生成证书和密钥
     show_progress 4 "生成证书和密钥（MSP 材料）" $start_time
     execute_with_timer "生成证书和密钥" "$CLI_CMD \"cryptogen generate --config=${HYPERLEDGER_PATH}/crypto-config.yaml --output=${CRYPTO_PATH}\""
+    # 应用服务器使用各组织的 User1 身份，按组织职责签发角色属性，部署后保持角色检查启用
+    execute_with_timer "签发Org1用户角色" "issue_role_certificate 1 registrar,judicial-officer,tax-officer"
+    execute_with_timer "签发Org2用户角色" "issue_role_certificate 2 settlement-officer"
+    execute_with_timer "签发Org3用户角色" "issue_role_certificate 3 clerk"
 
     # 创建排序通道创世区块
     show_progress 5 "创建排序通道创世区块" $start_time
@@ -322,13 +358,13 @@ main() {
     # 批准链码
     show_progress 14 "批准链码" $start_time
     PackageID=$($CLI_CMD "$Org1Peer0Cli peer lifecycle chaincode calculatepackageid ${CHAINCODE_PACKAGE}")
-    execute_with_timer "Org1批准链码" "$CLI_CMD \"$Org1Peer0Cli peer lifecycle chaincode approveformyorg -o $ORDERER1_ADDRESS --channelID $ChannelName --name $ChainCodeName --version $Version --package-id $PackageID --sequence $Sequence --tls --cafile $ORDERER_CA\""
-    execute_with_timer "Org2批准链码" "$CLI_CMD \"$Org2Peer0Cli peer lifecycle chaincode approveformyorg -o $ORDERER1_ADDRESS --channelID $ChannelName --name $ChainCodeName --version $Version --package-id $PackageID --sequence $Sequence --tls --cafile $ORDERER_CA\""
-    execute_with_timer "Org3批准链码" "$CLI_CMD \"$Org3Peer0Cli peer lifecycle chaincode approveformyorg -o $ORDERER1_ADDRESS --channelID $ChannelName --name $ChainCodeName --version $Version --package-id $PackageID --sequence $Sequence --tls --cafile $ORDERER_CA\""
+    execute_with_timer "Org1批准链码" "$CLI_CMD \"$Org1Peer0Cli peer lifecycle chaincode approveformyorg -o $ORDERER1_ADDRESS --channelID $ChannelName --name $ChainCodeName --version $Version --package-id $PackageID --sequence $Sequence --collections-config ${CHAINCODE_PATH}/collections_config.json --tls --cafile $ORDERER_CA\""
+    execute_with_timer "Org2批准链码" "$CLI_CMD \"$Org2Peer0Cli peer lifecycle chaincode approveformyorg -o $ORDERER1_ADDRESS --channelID $ChannelName --name $ChainCodeName --version $Version --package-id $PackageID --sequence $Sequence --collections-config ${CHAINCODE_PATH}/collections_config.json --tls --cafile $ORDERER_CA\""
+    execute_with_timer "Org3批准链码" "$CLI_CMD \"$Org3Peer0Cli peer lifecycle chaincode approveformyorg -o $ORDERER1_ADDRESS --channelID $ChannelName --name $ChainCodeName --version $Version --package-id $PackageID --sequence $Sequence --collections-config ${CHAINCODE_PATH}/collections_config.json --tls --cafile $ORDERER_CA\""
 
     # 提交链码
     show_progress 15 "提交链码" $start_time
-    execute_with_timer "提交链码定义" "$CLI_CMD \"$Org1Peer0Cli peer lifecycle chaincode commit -o $ORDERER1_ADDRESS --channelID $ChannelName --name $ChainCodeName --version $Version --sequence $Sequence --tls --cafile $ORDERER_CA --peerAddresses $ORG1_PEER0_ADDRESS --tlsRootCertFiles $ORG1_PEER0_TLS_ROOTCERT_FILE --peerAddresses $ORG2_PEER0_ADDRESS --tlsRootCertFiles $ORG2_PEER0_TLS_ROOTCERT_FILE --peerAddresses $ORG3_PEER0_ADDRESS --tlsRootCertFiles $ORG3_PEER0_TLS_ROOTCERT_FILE\""
+    execute_with_timer "提交链码定义" "$CLI_CMD \"$Org1Peer0Cli peer lifecycle chaincode commit -o $ORDERER1_ADDRESS --channelID $ChannelName --name $ChainCodeName --version $Version --sequence $Sequence --collections-config ${CHAINCODE_PATH}/collections_config.json --tls --cafile $ORDERER_CA --peerAddresses $ORG1_PEER0_ADDRESS --tlsRootCertFiles $ORG1_PEER0_TLS_ROOTCERT_FILE --peerAddresses $ORG2_PEER0_ADDRESS --tlsRootCertFiles $ORG2_PEER0_TLS_ROOTCERT_FILE --peerAddresses $ORG3_PEER0_ADDRESS --tlsRootCertFiles $ORG3_PEER0_TLS_ROOTCERT_FILE\""
 
     # 初始化并验证
     show_progress 16 "初始化并验证" $start_time